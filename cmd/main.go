@@ -2,11 +2,15 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
+	"go.mongodb.org/mongo-driver/mongo"
+
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/mongodb"
 )
@@ -44,11 +48,13 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to create user factory: %v", err)
 	}
+	defer userFactory.Close(context.Background())
 
 	productFactory, err := mongodb.NewFactory[*Product](config)
 	if err != nil {
 		log.Fatalf("Failed to create product factory: %v", err)
 	}
+	defer productFactory.Close(context.Background())
 
 	fmt.Println("Unit of Work factories created")
 
@@ -57,39 +63,34 @@ func main() {
 	fmt.Println("=============================")
 
 	mongoConnected := false
-	func() {
-		defer func() {
-			if r := recover(); r != nil {
-				fmt.Printf("MongoDB connection failed (expected if no MongoDB): %v\n", r)
-			}
-		}()
-
-		ctx := context.Background()
-		testUow := userFactory.CreateWithContext(ctx)
-
-		testUser := &User{
-			Email:  "connection-test@example.com",
-			Age:    25,
-			Active: true,
-		}
-		testUser.SetName("Connection Test")
-		testUser.SetSlug("connection-test")
+	ctx := context.Background()
+	testUow := userFactory.CreateWithContext(ctx)
 
-		_, err = testUow.Insert(ctx, testUser)
-		if err != nil {
-			fmt.Printf("MongoDB operation failed: %v\n", err)
-		} else {
-			fmt.Println("MongoDB connection successful!")
-			mongoConnected = true
-		}
-	}()
+	testUser := &User{
+		Email:  "connection-test@example.com",
+		Age:    25,
+		Active: true,
+	}
+	testUser.SetName("Connection Test")
+	testUser.SetSlug("connection-test")
+
+	_, err = testUow.Insert(ctx, testUser)
+	switch {
+	case errors.Is(err, uowerrors.ErrNotConnected):
+		fmt.Printf("MongoDB connection failed (expected if no MongoDB): %v\n", err)
+	case err != nil:
+		fmt.Printf("MongoDB operation failed: %v\n", err)
+	default:
+		fmt.Println("MongoDB connection successful!")
+		mongoConnected = true
+	}
 
 	if mongoConnected {
 		// Demonstrate operations with real MongoDB
 		demonstrateBasicOperations(userFactory)
 
 		// Demonstrate transactions
-		demonstrateTransactions(userFactory, productFactory)
+		demonstrateTransactions(userFactory, config)
 
 		// Demonstrate bulk operations
 		demonstrateBulkOperations(userFactory)
@@ -168,71 +169,44 @@ func demonstrateBasicOperations(factory *mongodb.Factory[*User]) {
 	fmt.Printf("Found %d users (total: %d)\n", len(users), total)
 }
 
-func demonstrateTransactions(userFactory *mongodb.Factory[*User], productFactory *mongodb.Factory[*Product]) {
+func demonstrateTransactions(userFactory *mongodb.Factory[*User], config *mongodb.Config) {
 	fmt.Println("\n💳 Transaction Demo:")
 	fmt.Println("===================")
 
 	ctx := context.Background()
 
-	// Create unit of work with transaction
-	userUow, err := userFactory.CreateWithTransaction(ctx)
-	if err != nil {
-		log.Printf("Transaction creation failed (expected if no MongoDB): %v", err)
-		return
-	}
-
-	productUow, err := productFactory.CreateWithTransaction(ctx)
-	if err != nil {
-		log.Printf("Transaction creation failed: %v", err)
-		return
-	}
-
-	defer func() {
-		if r := recover(); r != nil {
-			userUow.RollbackTransaction(ctx)
-			productUow.RollbackTransaction(ctx)
-			fmt.Println("Transaction rolled back due to panic")
+	// Both inserts share one session, so they commit or roll back together
+	err := userFactory.WithTransaction(ctx, func(sc mongo.SessionContext) error {
+		userUow := userFactory.Create()
+		productUow, err := mongodb.NewUnitOfWorkWithClient[*Product](config, sc.Client())
+		if err != nil {
+			return err
 		}
-	}()
-
-	// Create user and product in transaction
-	user := &User{
-		Email:  "transactional@example.com",
-		Age:    25,
-		Active: true,
-	}
-	user.SetName("Trans User")
-
-	product := &Product{
-		Price:    99.99,
-		Category: "Electronics",
-		InStock:  true,
-	}
-	product.SetName("Sample Product")
-
-	// Insert both entities
-	_, err = userUow.Insert(ctx, user)
-	if err != nil {
-		log.Printf("User insert failed: %v", err)
-		return
-	}
 
-	_, err = productUow.Insert(ctx, product)
-	if err != nil {
-		log.Printf("Product insert failed: %v", err)
-		return
-	}
+		user := &User{
+			Email:  "transactional@example.com",
+			Age:    25,
+			Active: true,
+		}
+		user.SetName("Trans User")
 
-	// Commit transactions
-	err = userUow.CommitTransaction(ctx)
-	if err != nil {
-		log.Printf("User transaction commit failed: %v", err)
-		return
-	}
+		product := &Product{
+			Price:    99.99,
+			Category: "Electronics",
+			InStock:  true,
+		}
+		product.SetName("Sample Product")
 
-	err = productUow.CommitTransaction(ctx)
+		if _, err := userUow.Insert(sc, user); err != nil {
+			return fmt.Errorf("user insert failed: %w", err)
+		}
+		if _, err := productUow.Insert(sc, product); err != nil {
+			return fmt.Errorf("product insert failed: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		log.Printf("Product transaction commit failed: %v", err)
+		log.Printf("Transaction failed (expected if no replica set): %v", err)
 		return
 	}
 
@@ -273,29 +247,29 @@ func demonstrateBulkOperations(factory *mongodb.Factory[*User]) {
 	}
 
 	// Bulk update
-	updatedUsers, err := uow.BulkUpdate(ctx, createdUsers)
+	updated, err := uow.BulkUpdate(ctx, createdUsers)
 	if err != nil {
 		log.Printf("Bulk update failed: %v", err)
 		return
 	}
 
-	fmt.Printf("Bulk updated %d users\n", len(updatedUsers))
+	fmt.Printf("Bulk updated %d users\n", updated.Modified)
 
 	// Create identifiers for bulk delete
 	var identifiers []identifier.IIdentifier
-	for _, user := range updatedUsers {
+	for _, user := range createdUsers {
 		id := identifier.New().Equal("_id", user.GetID())
 		identifiers = append(identifiers, id)
 	}
 
 	// Bulk soft delete
-	err = uow.BulkSoftDelete(ctx, identifiers)
+	deleteResult, err := uow.BulkSoftDelete(ctx, identifiers)
 	if err != nil {
 		log.Printf("Bulk soft delete failed: %v", err)
 		return
 	}
 
-	fmt.Printf("Bulk soft deleted %d users\n", len(identifiers))
+	fmt.Printf("Bulk soft deleted %d of %d users\n", deleteResult.Modified, deleteResult.Requested)
 }
 
 func demonstrateSoftDeleteRestore(factory *mongodb.Factory[*User]) {
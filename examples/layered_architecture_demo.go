@@ -29,11 +29,13 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to create user UoW factory: %v", err)
 	}
+	defer userUoWFactory.Close(context.Background())
 
 	productUoWFactory, err := mongodb.NewFactory[*persistence.Product](config)
 	if err != nil {
 		log.Fatalf("Failed to create product UoW factory: %v", err)
 	}
+	defer productUoWFactory.Close(context.Background())
 
 	fmt.Println("Unit of Work factories created")
 
@@ -62,15 +64,6 @@ func main() {
 	fmt.Println("Testing MongoDB Connection:")
 	fmt.Println("=============================")
 
-	defer func() {
-		if r := recover(); r != nil {
-			fmt.Printf("MongoDB operation failed (expected if no MongoDB): %v\n", r)
-			fmt.Println()
-			demonstrateOfflineArchitecture(userService, productService)
-			return
-		}
-	}()
-
 	// Test with a simple operation
 	testUser, err := userService.CreateUser(ctx, "test@example.com", 25)
 	if err != nil {
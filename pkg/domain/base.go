@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -19,6 +20,54 @@ type BaseModel interface {
 	IsDeleted() bool
 }
 
+// CollectionNamer is implemented by entities that choose their own collection
+// name instead of the lowercased, pluralized type name
+type CollectionNamer interface {
+	CollectionName() string
+}
+
+// SoftDeletable can be implemented by models that must never be soft deleted,
+// such as immutable logs. Returning false drops the deletedAt filter from every
+// read and turns soft deletes into hard deletes.
+type SoftDeletable interface {
+	SoftDeletable() bool
+}
+
+// ListProjected can be implemented by models with large fields, such as long
+// descriptions or embedded blobs, that list queries should leave out. Reads of
+// a single document still return every field.
+type ListProjected interface {
+	ListExcludedFields() []string
+}
+
+// ImmutableFields can be implemented by models whose listed fields, such as
+// createdBy, are written when an upsert inserts the document and left alone
+// when it updates one. createdAt is always treated this way.
+type ImmutableFields interface {
+	ImmutableFields() []string
+}
+
+// NormalizedFields can be implemented by models whose listed string fields,
+// such as emails or usernames, are stored trimmed and lowercased so matching
+// and unique indexes ignore case and stray whitespace. Queries on those fields
+// should pass values through Normalize.
+type NormalizedFields interface {
+	NormalizedFields() []string
+}
+
+// Normalize trims and lowercases value the way NormalizedFields are stored
+func Normalize(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// ContentHashed can be implemented by models that store a hash of their
+// significant fields under the "contentHash" key. The hash is refreshed on
+// every write and lets UpdateIfChanged skip updates that change nothing.
+type ContentHashed interface {
+	GetContentHash() string
+	SetContentHash(hash string)
+}
+
 type SortDirection string
 
 const (
@@ -26,22 +75,100 @@ const (
 	SortDesc SortDirection = "desc"
 )
 
+// SortMap sorts with the query's native sort. On array fields MongoDB compares
+// the lowest element when ascending and the highest when descending; use
+// ArraySort to choose the element explicitly.
 type SortMap map[string]SortDirection
 
+// ArraySortMode selects the array element a document is sorted by
+type ArraySortMode string
+
+const (
+	ArraySortMin ArraySortMode = "min"
+	ArraySortMax ArraySortMode = "max"
+)
+
+// ArraySort sorts by one element of an array field, e.g. products by their
+// lowest tag in descending order. Mode defaults to ArraySortMin.
+type ArraySort struct {
+	Field     string        `json:"field"`
+	Direction SortDirection `json:"direction"`
+	Mode      ArraySortMode `json:"mode,omitempty"`
+}
+
+// TrashMode selects live records, soft-deleted ones, or both. The zero value
+// behaves as LiveOnly.
+type TrashMode string
+
+const (
+	LiveOnly    TrashMode = "live"
+	TrashedOnly TrashMode = "trashed"
+	All         TrashMode = "all"
+)
+
+// FindOptions selects soft-deleted records for FindAllWithOptions. Leaving
+// both false reads live records only; setting both is invalid.
+type FindOptions struct {
+	IncludeTrashed bool `json:"includeTrashed,omitempty"`
+	OnlyTrashed    bool `json:"onlyTrashed,omitempty"`
+}
+
+// CountMode selects how paginated queries compute their total. The zero value
+// behaves as CountExact.
+type CountMode string
+
+const (
+	CountExact CountMode = "exact"
+	// CountEstimated reads the total from collection metadata when the query
+	// has no filter, including the implicit live-only one: soft delete is
+	// disabled, trashed documents live in a separate collection, or Trash is
+	// All. The estimate may be stale; other queries still count exactly.
+	CountEstimated CountMode = "estimated"
+)
+
 type QueryParams[E BaseModel] struct {
 	Filter  E        `json:"filter,omitempty"`
 	Sort    SortMap  `json:"sort,omitempty"`
 	Include []string `json:"include,omitempty"`
-	Limit   int      `json:"limit,omitempty"`
-	Offset  int      `json:"offset,omitempty"`
+	// ArraySort keys take precedence over Sort and run the query as an
+	// aggregation
+	ArraySort []ArraySort `json:"arraySort,omitempty"`
+	Limit     int         `json:"limit,omitempty"`
+	Offset    int         `json:"offset,omitempty"`
+	Trash     TrashMode   `json:"trash,omitempty"`
+	Count     CountMode   `json:"count,omitempty"`
+}
+
+// KeysetParams pages by a sort field instead of an offset. Cursor is a token
+// from a previous CursorPage; empty starts at the first page.
+type KeysetParams[E BaseModel] struct {
+	Filter E `json:"filter,omitempty"`
+	// SortField defaults to _id; ties are broken by _id
+	SortField string        `json:"sortField,omitempty"`
+	Direction SortDirection `json:"direction,omitempty"`
+	Limit     int           `json:"limit,omitempty"`
+	Cursor    string        `json:"cursor,omitempty"`
 }
 
+// DefaultMaxLimit is the page size Validate clamps Limit to
+const DefaultMaxLimit = 1000
+
 func (q *QueryParams[E]) Validate() error {
+	return q.ValidateWithMax(DefaultMaxLimit)
+}
+
+// ValidateWithMax normalizes the params like Validate but clamps Limit to max,
+// e.g. a small cap for UI lists or a larger one for exports. A max of zero or
+// less falls back to DefaultMaxLimit.
+func (q *QueryParams[E]) ValidateWithMax(max int) error {
+	if max <= 0 {
+		max = DefaultMaxLimit
+	}
 	if q.Limit < 0 {
 		q.Limit = 10
 	}
-	if q.Limit > 1000 {
-		q.Limit = 1000
+	if q.Limit > max {
+		q.Limit = max
 	}
 	if q.Offset < 0 {
 		q.Offset = 0
@@ -6,7 +6,8 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// BaseEntity provides a concrete implementation of BaseModel for MongoDB
+// BaseEntity provides a concrete implementation of BaseModel for MongoDB.
+// Timestamps are written and read back in UTC.
 type BaseEntity struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
 	Slug      string             `bson:"slug,omitempty" json:"slug,omitempty"`
@@ -12,12 +12,19 @@ var (
 	ErrTransactionAlreadyOpen    = errors.New("transaction is already open")
 	ErrTransactionCommitFailed   = errors.New("failed to commit transaction")
 	ErrTransactionRollbackFailed = errors.New("failed to rollback transaction")
+	ErrTransactionExpired        = errors.New("transaction exceeded its time limit")
+	ErrTransactionsUnsupported   = errors.New("transactions require a replica set member or mongos")
 
 	// Entity errors
 	ErrEntityNotFound   = errors.New("entity not found")
 	ErrEntityExists     = errors.New("entity already exists")
 	ErrInvalidEntity    = errors.New("invalid entity")
 	ErrEntityValidation = errors.New("entity validation failed")
+	ErrTypeMismatch     = errors.New("document does not match entity type")
+	ErrInvalidID        = errors.New("invalid entity ID")
+	ErrNilEntity        = errors.New("entity is nil")
+	ErrAppendOnly       = errors.New("time-series collection is append-only")
+	ErrVersionConflict  = errors.New("entity was modified concurrently")
 
 	// Repository errors
 	ErrRepositoryNotFound    = errors.New("repository not found")
@@ -26,14 +33,21 @@ var (
 
 	// Database errors
 	ErrDatabaseConnection = errors.New("database connection failed")
+	ErrNotConnected       = errors.New("not connected to MongoDB")
 	ErrDatabaseTimeout    = errors.New("database operation timeout")
 	ErrDatabaseConstraint = errors.New("database constraint violation")
+	ErrDuplicateKey       = errors.New("duplicate key")
 	ErrDatabaseDeadlock   = errors.New("database deadlock detected")
 
 	// Query errors
 	ErrInvalidQuery       = errors.New("invalid query")
 	ErrQueryExecution     = errors.New("query execution failed")
 	ErrInvalidQueryParams = errors.New("invalid query parameters")
+	ErrInvalidProjection  = errors.New("invalid projection: cannot mix inclusion and exclusion, except for excluding _id")
+	ErrSortRequired       = errors.New("paginated query requires a sort")
+
+	// Guardrail errors
+	ErrConfirmationRequired = errors.New("collection-wide operation requires explicit confirmation")
 )
 
 // UnitOfWorkError wraps errors with context information
@@ -140,7 +154,7 @@ func IsConstraint(err error) bool {
 	if errors.As(err, &uowErr) {
 		return uowErr.Code == CodeConstraint
 	}
-	return errors.Is(err, ErrDatabaseConstraint)
+	return errors.Is(err, ErrDatabaseConstraint) || errors.Is(err, ErrDuplicateKey)
 }
 
 // IsTransaction checks if the error is transaction-related
@@ -152,7 +166,9 @@ func IsTransaction(err error) bool {
 	return errors.Is(err, ErrTransactionNotStarted) ||
 		errors.Is(err, ErrTransactionAlreadyOpen) ||
 		errors.Is(err, ErrTransactionCommitFailed) ||
-		errors.Is(err, ErrTransactionRollbackFailed)
+		errors.Is(err, ErrTransactionRollbackFailed) ||
+		errors.Is(err, ErrTransactionExpired) ||
+		errors.Is(err, ErrTransactionsUnsupported)
 }
 
 // IsConnection checks if the error is connection-related
@@ -161,7 +177,7 @@ func IsConnection(err error) bool {
 	if errors.As(err, &uowErr) {
 		return uowErr.Code == CodeConnection
 	}
-	return errors.Is(err, ErrDatabaseConnection)
+	return errors.Is(err, ErrDatabaseConnection) || errors.Is(err, ErrNotConnected)
 }
 
 // IsTimeout checks if the error is timeout-related
@@ -3,6 +3,7 @@ package identifier
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -10,19 +11,27 @@ import (
 
 type IIdentifier interface {
 	Equal(field string, value interface{}) IIdentifier
+	NotEqual(field string, value interface{}) IIdentifier
 	In(field string, values []interface{}) IIdentifier
+	NotIn(field string, values []interface{}) IIdentifier
+	Exists(field string, exists bool) IIdentifier
 	Like(field string, pattern string) IIdentifier
 	GreaterThan(field string, value interface{}) IIdentifier
 	LessThan(field string, value interface{}) IIdentifier
 	Between(field string, start, end interface{}) IIdentifier
 	IsNull(field string) IIdentifier
 	IsNotNull(field string) IIdentifier
+	CreatedOnDay(day time.Time, loc *time.Location) IIdentifier
+	Or(groups ...IIdentifier) IIdentifier
+	And(groups ...IIdentifier) IIdentifier
+	Nor(groups ...IIdentifier) IIdentifier
 
 	Add(key string, value interface{}) IIdentifier
 	AddIf(condition bool, key string, value interface{}) IIdentifier
 
 	ToBSON() bson.M
 	ToObjectID(field string) (primitive.ObjectID, error)
+	ToIdentifier() IIdentifier
 
 	ToMap() map[string]interface{}
 	GetQuery() map[string]interface{}
@@ -31,104 +40,292 @@ type IIdentifier interface {
 	String() string
 }
 
+// operator is the comparison a condition applies to its field
+type operator int
+
+const (
+	opEqual operator = iota
+	opIn
+	opLike
+	opGreaterThan
+	opLessThan
+	opBetween
+	opRange
+	opIsNull
+	opIsNotNull
+	opOr
+	opAnd
+	opNotEqual
+	opNotIn
+	opExists
+	opNor
+)
+
+// suffix is the legacy key suffix of the operator, used when the query is
+// rendered as a map or string
+func (op operator) suffix() string {
+	switch op {
+	case opIn:
+		return " IN"
+	case opLike:
+		return " LIKE"
+	case opGreaterThan:
+		return " >"
+	case opLessThan:
+		return " <"
+	case opBetween:
+		return " BETWEEN"
+	case opRange:
+		return " RANGE"
+	case opIsNull:
+		return " IS NULL"
+	case opIsNotNull:
+		return " IS NOT NULL"
+	case opNotEqual:
+		return " !="
+	case opNotIn:
+		return " NOT IN"
+	case opExists:
+		return " EXISTS"
+	default:
+		return ""
+	}
+}
+
+// condition is a single field comparison. Field names are stored verbatim, so
+// they may contain spaces or operator-like text.
+type condition struct {
+	field string
+	op    operator
+	value interface{}
+}
+
 type Identifier struct {
-	query map[string]interface{}
+	conditions []condition
 }
 
 func New() *Identifier {
-	return &Identifier{
-		query: make(map[string]interface{}),
+	return &Identifier{}
+}
+
+// set adds a condition, replacing the value of an earlier one with the same
+// field and operator
+func (i *Identifier) set(field string, op operator, value interface{}) IIdentifier {
+	for n := range i.conditions {
+		if i.conditions[n].field == field && i.conditions[n].op == op {
+			i.conditions[n].value = value
+			return i
+		}
 	}
+	i.conditions = append(i.conditions, condition{field: field, op: op, value: value})
+	return i
 }
 
 func (i *Identifier) Equal(field string, value interface{}) IIdentifier {
-	i.query[field] = value
-	return i
+	return i.set(field, opEqual, value)
+}
+
+// NotEqual matches documents whose field differs from value, including those
+// without the field
+func (i *Identifier) NotEqual(field string, value interface{}) IIdentifier {
+	return i.set(field, opNotEqual, value)
 }
 
 func (i *Identifier) In(field string, values []interface{}) IIdentifier {
-	i.query[field+" IN"] = values
-	return i
+	return i.set(field, opIn, values)
+}
+
+// NotIn matches documents whose field is none of values, including those
+// without the field
+func (i *Identifier) NotIn(field string, values []interface{}) IIdentifier {
+	return i.set(field, opNotIn, values)
+}
+
+// Exists matches documents that have the field when exists is true, or lack
+// it when false. Unlike IsNull, a field explicitly set to null exists.
+func (i *Identifier) Exists(field string, exists bool) IIdentifier {
+	return i.set(field, opExists, exists)
 }
 
 func (i *Identifier) Like(field string, pattern string) IIdentifier {
-	i.query[field+" LIKE"] = pattern
-	return i
+	return i.set(field, opLike, pattern)
 }
 
 func (i *Identifier) GreaterThan(field string, value interface{}) IIdentifier {
-	i.query[field+" >"] = value
-	return i
+	return i.set(field, opGreaterThan, value)
 }
 
 func (i *Identifier) LessThan(field string, value interface{}) IIdentifier {
-	i.query[field+" <"] = value
-	return i
+	return i.set(field, opLessThan, value)
 }
 
 func (i *Identifier) Between(field string, start, end interface{}) IIdentifier {
-	i.query[field+" BETWEEN"] = []interface{}{start, end}
-	return i
+	return i.set(field, opBetween, []interface{}{start, end})
 }
 
 func (i *Identifier) IsNull(field string) IIdentifier {
-	i.query[field+" IS NULL"] = true
-	return i
+	return i.set(field, opIsNull, true)
 }
 
 func (i *Identifier) IsNotNull(field string) IIdentifier {
-	i.query[field+" IS NOT NULL"] = true
+	return i.set(field, opIsNotNull, true)
+}
+
+// CreatedOnDay matches documents created during the calendar day of the given
+// time in loc. The bounds are computed in loc and emitted in UTC as a half-open
+// [start, end) range, so days shortened or lengthened by DST are handled.
+func (i *Identifier) CreatedOnDay(day time.Time, loc *time.Location) IIdentifier {
+	if loc == nil {
+		loc = day.Location()
+	}
+	local := day.In(loc)
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	end := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc)
+	return i.set("createdAt", opRange, []interface{}{start.UTC(), end.UTC()})
+}
+
+// Or matches documents matching any of groups, alongside the other
+// conditions. Each call adds a separate group, so two Or calls must both hold.
+func (i *Identifier) Or(groups ...IIdentifier) IIdentifier {
+	return i.group("$or", opOr, groups)
+}
+
+// And matches documents matching all of groups, which is mainly useful to
+// nest inside Or
+func (i *Identifier) And(groups ...IIdentifier) IIdentifier {
+	return i.group("$and", opAnd, groups)
+}
+
+// Nor matches documents matching none of groups
+func (i *Identifier) Nor(groups ...IIdentifier) IIdentifier {
+	return i.group("$nor", opNor, groups)
+}
+
+// group appends a logical condition over the non-nil groups; an empty group
+// list is ignored because MongoDB rejects an empty $or or $and
+func (i *Identifier) group(field string, op operator, groups []IIdentifier) IIdentifier {
+	var kept []IIdentifier
+	for _, g := range groups {
+		if g != nil {
+			kept = append(kept, g)
+		}
+	}
+	if len(kept) > 0 {
+		i.conditions = append(i.conditions, condition{field: field, op: op, value: kept})
+	}
 	return i
 }
 
+// Add matches key exactly against value; key is a field name, never parsed
+// for operators
 func (i *Identifier) Add(key string, value interface{}) IIdentifier {
-	i.query[key] = value
-	return i
+	return i.set(key, opEqual, value)
 }
 
 func (i *Identifier) AddIf(condition bool, key string, value interface{}) IIdentifier {
 	if condition {
-		i.query[key] = value
+		i.set(key, opEqual, value)
 	}
 	return i
 }
 
+// ToBSON builds the filter. Operator conditions on the same field are merged,
+// so GreaterThan and LessThan on one field form a single range; an Equal on a
+// field replaces any operators before it.
 func (i *Identifier) ToBSON() bson.M {
 	filter := bson.M{}
-	for key, value := range i.query {
-
-		if strings.Contains(key, " >") {
-			field := strings.TrimSuffix(key, " >")
-			filter[field] = bson.M{"$gt": value}
-		} else if strings.Contains(key, " <") {
-			field := strings.TrimSuffix(key, " <")
-			filter[field] = bson.M{"$lt": value}
-		} else if strings.Contains(key, " IN") {
-			field := strings.TrimSuffix(key, " IN")
-			filter[field] = bson.M{"$in": value}
-		} else if strings.Contains(key, " LIKE") {
-			field := strings.TrimSuffix(key, " LIKE")
-			filter[field] = bson.M{"$regex": value, "$options": "i"}
-		} else if strings.Contains(key, " BETWEEN") {
-			field := strings.TrimSuffix(key, " BETWEEN")
-			if vals, ok := value.([]interface{}); ok && len(vals) == 2 {
-				filter[field] = bson.M{"$gte": vals[0], "$lte": vals[1]}
+	// built holds the operator documents created here, which are safe to
+	// merge into; an Equal value is never modified
+	built := make(map[string]bson.M)
+	var ors []bson.A
+	var ands, nors bson.A
+	for _, c := range i.conditions {
+		var expr bson.M
+		switch c.op {
+		case opOr:
+			ors = append(ors, groupFilters(c.value))
+			continue
+		case opAnd:
+			ands = append(ands, groupFilters(c.value)...)
+			continue
+		case opNor:
+			nors = append(nors, groupFilters(c.value)...)
+			continue
+		case opEqual:
+			filter[c.field] = c.value
+			delete(built, c.field)
+			continue
+		case opGreaterThan:
+			expr = bson.M{"$gt": c.value}
+		case opLessThan:
+			expr = bson.M{"$lt": c.value}
+		case opNotEqual:
+			expr = bson.M{"$ne": c.value}
+		case opIn:
+			expr = bson.M{"$in": c.value}
+		case opNotIn:
+			expr = bson.M{"$nin": c.value}
+		case opExists:
+			expr = bson.M{"$exists": c.value}
+		case opLike:
+			expr = bson.M{"$regex": c.value, "$options": "i"}
+		case opBetween:
+			if vals, ok := c.value.([]interface{}); ok && len(vals) == 2 {
+				expr = bson.M{"$gte": vals[0], "$lte": vals[1]}
+			}
+		case opRange:
+			if vals, ok := c.value.([]interface{}); ok && len(vals) == 2 {
+				expr = bson.M{"$gte": vals[0], "$lt": vals[1]}
+			}
+		case opIsNull:
+			expr = bson.M{"$exists": false}
+		case opIsNotNull:
+			expr = bson.M{"$exists": true}
+		}
+		if expr == nil {
+			continue
+		}
+
+		if existing, ok := built[c.field]; ok {
+			for k, v := range expr {
+				existing[k] = v
 			}
-		} else if strings.Contains(key, " IS NULL") {
-			field := strings.TrimSuffix(key, " IS NULL")
-			filter[field] = bson.M{"$exists": false}
-		} else if strings.Contains(key, " IS NOT NULL") {
-			field := strings.TrimSuffix(key, " IS NOT NULL")
-			filter[field] = bson.M{"$exists": true}
-		} else {
-			filter[key] = value
+			continue
 		}
+		built[c.field] = expr
+		filter[c.field] = expr
+	}
+
+	// A filter holds a single $or, so further groups are ANDed together
+	if len(ors) == 1 {
+		filter["$or"] = ors[0]
+	} else {
+		for _, or := range ors {
+			ands = append(ands, bson.M{"$or": or})
+		}
+	}
+	if len(ands) > 0 {
+		filter["$and"] = ands
+	}
+	// Matching none of several $nor lists is matching none of their union
+	if len(nors) > 0 {
+		filter["$nor"] = nors
 	}
 	return filter
 }
 
+// groupFilters converts the identifiers of an Or or And condition to filters
+func groupFilters(value interface{}) bson.A {
+	groups, _ := value.([]IIdentifier)
+	filters := make(bson.A, 0, len(groups))
+	for _, g := range groups {
+		filters = append(filters, g.ToBSON())
+	}
+	return filters
+}
+
 func (i *Identifier) ToObjectID(field string) (primitive.ObjectID, error) {
-	value, exists := i.query[field]
+	value, exists := i.lookup(field, opEqual)
 	if !exists {
 		return primitive.NilObjectID, fmt.Errorf("field %s not found", field)
 	}
@@ -143,47 +340,92 @@ func (i *Identifier) ToObjectID(field string) (primitive.ObjectID, error) {
 	}
 }
 
-func (i *Identifier) ToMap() map[string]interface{} {
-	return i.query
+// lookup returns the value of the condition on field with operator op
+func (i *Identifier) lookup(field string, op operator) (interface{}, bool) {
+	for _, c := range i.conditions {
+		if c.field == field && c.op == op {
+			return c.value, true
+		}
+	}
+	return nil, false
 }
 
-func (i *Identifier) GetQuery() map[string]interface{} {
-	result := make(map[string]interface{}, len(i.query))
-	for k, v := range i.query {
-		result[k] = v
+// ToMap returns the conditions keyed by field with the operator appended, for
+// example "age >"
+func (i *Identifier) ToMap() map[string]interface{} {
+	result := make(map[string]interface{}, len(i.conditions))
+	for _, c := range i.conditions {
+		if groups, ok := c.value.([]IIdentifier); ok {
+			maps := make([]map[string]interface{}, 0, len(groups))
+			for _, g := range groups {
+				maps = append(maps, g.ToMap())
+			}
+			result[c.field] = maps
+			continue
+		}
+		result[c.field+c.op.suffix()] = c.value
 	}
 	return result
 }
 
+func (i *Identifier) GetQuery() map[string]interface{} {
+	return i.ToMap()
+}
+
 func (i *Identifier) String() string {
-	if len(i.query) == 0 {
+	if len(i.conditions) == 0 {
 		return "{}"
 	}
 
 	var builder strings.Builder
 	builder.WriteString("{")
 
-	first := true
-	for key, value := range i.query {
-		if !first {
+	for n, c := range i.conditions {
+		if n > 0 {
 			builder.WriteString(", ")
 		}
-		builder.WriteString(fmt.Sprintf("%s: %v", key, value))
-		first = false
+		if groups, ok := c.value.([]IIdentifier); ok {
+			parts := make([]string, 0, len(groups))
+			for _, g := range groups {
+				parts = append(parts, g.String())
+			}
+			builder.WriteString(fmt.Sprintf("%s: [%s]", c.field, strings.Join(parts, ", ")))
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("%s%s: %v", c.field, c.op.suffix(), c.value))
 	}
 
 	builder.WriteString("}")
 	return builder.String()
 }
 
+// Has reports whether any condition applies to the field key, including
+// conditions nested in Or and And groups
 func (i *Identifier) Has(key string) bool {
-	_, exists := i.query[key]
-	return exists
+	for _, c := range i.conditions {
+		if groups, ok := c.value.([]IIdentifier); ok {
+			for _, g := range groups {
+				if g.Has(key) {
+					return true
+				}
+			}
+			continue
+		}
+		if c.field == key {
+			return true
+		}
+	}
+	return false
 }
 
+// Get returns the value of the first top-level condition on the field key
 func (i *Identifier) Get(key string) (interface{}, bool) {
-	value, exists := i.query[key]
-	return value, exists
+	for _, c := range i.conditions {
+		if c.field == key && c.op != opOr && c.op != opAnd && c.op != opNor {
+			return c.value, true
+		}
+	}
+	return nil, false
 }
 
 func ByID(id interface{}) IIdentifier {
@@ -206,10 +448,13 @@ func Inactive() IIdentifier {
 	return New().Equal("active", false)
 }
 
+// NotDeleted matches live documents with the condition the unit of work adds
+// to its reads, deletedAt: null, which also matches a missing field
 func NotDeleted() IIdentifier {
-	return New().IsNull("deletedAt")
+	return New().Equal("deletedAt", nil)
 }
 
+// Deleted matches soft-deleted documents, the complement of NotDeleted
 func Deleted() IIdentifier {
-	return New().IsNotNull("deletedAt")
+	return New().NotEqual("deletedAt", nil)
 }
@@ -0,0 +1,347 @@
+package identifier
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestIdentifier_CreatedOnDay(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		day   time.Time
+		loc   *time.Location
+		start time.Time
+		end   time.Time
+	}{
+		{
+			name:  "regular day",
+			day:   time.Date(2024, 6, 15, 23, 30, 0, 0, newYork),
+			loc:   newYork,
+			start: time.Date(2024, 6, 15, 4, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, 6, 16, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "spring forward is 23 hours",
+			day:   time.Date(2024, 3, 10, 12, 0, 0, 0, newYork),
+			loc:   newYork,
+			start: time.Date(2024, 3, 10, 5, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, 3, 11, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "fall back is 25 hours",
+			day:   time.Date(2024, 11, 3, 12, 0, 0, 0, newYork),
+			loc:   newYork,
+			start: time.Date(2024, 11, 3, 4, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, 11, 4, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "utc instant converted to local day",
+			day:   time.Date(2024, 3, 11, 2, 0, 0, 0, time.UTC),
+			loc:   newYork,
+			start: time.Date(2024, 3, 10, 5, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, 3, 11, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "nil location uses the day's zone",
+			day:   time.Date(2024, 3, 10, 12, 0, 0, 0, newYork),
+			loc:   nil,
+			start: time.Date(2024, 3, 10, 5, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, 3, 11, 4, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := New().CreatedOnDay(tt.day, tt.loc).ToBSON()
+
+			assert.Equal(t, bson.M{
+				"createdAt": bson.M{"$gte": tt.start, "$lt": tt.end},
+			}, filter)
+		})
+	}
+}
+
+func TestIdentifier_ToBSON(t *testing.T) {
+	tests := []struct {
+		name string
+		id   IIdentifier
+		want bson.M
+	}{
+		{
+			name: "equal on field containing IN",
+			id:   New().Equal("points IN stock", 5),
+			want: bson.M{"points IN stock": 5},
+		},
+		{
+			name: "equal on field containing >",
+			id:   New().Equal("score > limit", true),
+			want: bson.M{"score > limit": true},
+		},
+		{
+			name: "equal on field containing LIKE",
+			id:   New().Add("tags LIKE", "go"),
+			want: bson.M{"tags LIKE": "go"},
+		},
+		{
+			name: "equal on field containing IS NULL",
+			id:   New().Equal("value IS NULL", false),
+			want: bson.M{"value IS NULL": false},
+		},
+		{
+			name: "operators on fields containing operator text",
+			id: New().
+				GreaterThan("a < b", 1).
+				In("x LIKE y", []interface{}{"p", "q"}).
+				Like("c IN d", "^go"),
+			want: bson.M{
+				"a < b":    bson.M{"$gt": 1},
+				"x LIKE y": bson.M{"$in": []interface{}{"p", "q"}},
+				"c IN d":   bson.M{"$regex": "^go", "$options": "i"},
+			},
+		},
+		{
+			name: "null checks",
+			id:   New().IsNull("deletedAt").IsNotNull("email"),
+			want: bson.M{
+				"deletedAt": bson.M{"$exists": false},
+				"email":     bson.M{"$exists": true},
+			},
+		},
+		{
+			name: "between",
+			id:   New().Between("age", 18, 65),
+			want: bson.M{"age": bson.M{"$gte": 18, "$lte": 65}},
+		},
+		{
+			name: "operators on one field are merged",
+			id:   New().GreaterThan("age", 18).LessThan("age", 65),
+			want: bson.M{"age": bson.M{"$gt": 18, "$lt": 65}},
+		},
+		{
+			name: "repeated operator replaces its value",
+			id:   New().GreaterThan("age", 18).GreaterThan("age", 21),
+			want: bson.M{"age": bson.M{"$gt": 21}},
+		},
+		{
+			name: "equal after operator wins",
+			id:   New().GreaterThan("age", 18).Equal("age", 30),
+			want: bson.M{"age": 30},
+		},
+		{
+			name: "add if skips false conditions",
+			id:   New().AddIf(false, "role", "admin").AddIf(true, "active", true),
+			want: bson.M{"active": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.id.ToBSON())
+		})
+	}
+}
+
+func TestIdentifier_ToBSON_DoesNotModifyEqualValue(t *testing.T) {
+	size := bson.M{"$size": 2}
+	filter := New().Equal("tags", size).GreaterThan("tags", 1).ToBSON()
+
+	assert.Equal(t, bson.M{"tags": bson.M{"$gt": 1}}, filter)
+	assert.Equal(t, bson.M{"$size": 2}, size)
+}
+
+func TestIdentifier_Lookup(t *testing.T) {
+	id := New().Equal("_id", "507f1f77bcf86cd799439011").IsNotNull("deletedAt").GreaterThan("age >", 3)
+
+	assert.True(t, id.Has("deletedAt"))
+	assert.True(t, id.Has("age >"))
+	assert.False(t, id.Has("age"))
+
+	value, ok := id.Get("age >")
+	assert.True(t, ok)
+	assert.Equal(t, 3, value)
+
+	oid, err := id.ToObjectID("_id")
+	require.NoError(t, err)
+	assert.Equal(t, "507f1f77bcf86cd799439011", oid.Hex())
+
+	_, err = id.ToObjectID("deletedAt")
+	assert.Error(t, err, "only Equal conditions hold an ID")
+
+	assert.Equal(t, map[string]interface{}{
+		"_id":                   "507f1f77bcf86cd799439011",
+		"deletedAt IS NOT NULL": true,
+		"age > >":               3,
+	}, id.ToMap())
+	assert.Equal(t, "{_id: 507f1f77bcf86cd799439011, deletedAt IS NOT NULL: true, age > >: 3}", id.String())
+}
+
+func TestIdentifier_OrAnd(t *testing.T) {
+	tests := []struct {
+		name string
+		id   IIdentifier
+		want bson.M
+	}{
+		{
+			name: "or alongside a condition",
+			id: New().Equal("active", true).Or(
+				New().Equal("role", "admin"),
+				New().GreaterThan("age", 65),
+			),
+			want: bson.M{
+				"active": true,
+				"$or": bson.A{
+					bson.M{"role": "admin"},
+					bson.M{"age": bson.M{"$gt": 65}},
+				},
+			},
+		},
+		{
+			name: "two levels: or inside and",
+			id: New().And(
+				New().Or(New().Equal("role", "admin"), New().Equal("role", "owner")),
+				New().Or(New().IsNull("deletedAt"), New().Equal("archived", true)),
+			),
+			want: bson.M{"$and": bson.A{
+				bson.M{"$or": bson.A{bson.M{"role": "admin"}, bson.M{"role": "owner"}}},
+				bson.M{"$or": bson.A{bson.M{"deletedAt": bson.M{"$exists": false}}, bson.M{"archived": true}}},
+			}},
+		},
+		{
+			name: "three levels: and inside or inside and",
+			id: New().Equal("tenant", "acme").And(
+				New().Or(
+					New().Equal("role", "admin"),
+					New().And(
+						New().Equal("role", "member"),
+						New().Between("age", 18, 30),
+					),
+				),
+			),
+			want: bson.M{
+				"tenant": "acme",
+				"$and": bson.A{
+					bson.M{"$or": bson.A{
+						bson.M{"role": "admin"},
+						bson.M{"$and": bson.A{
+							bson.M{"role": "member"},
+							bson.M{"age": bson.M{"$gte": 18, "$lte": 30}},
+						}},
+					}},
+				},
+			},
+		},
+		{
+			name: "repeated or calls are all required",
+			id: New().
+				Or(New().Equal("a", 1), New().Equal("b", 2)).
+				Or(New().Equal("c", 3), New().Equal("d", 4)),
+			want: bson.M{"$and": bson.A{
+				bson.M{"$or": bson.A{bson.M{"a": 1}, bson.M{"b": 2}}},
+				bson.M{"$or": bson.A{bson.M{"c": 3}, bson.M{"d": 4}}},
+			}},
+		},
+		{
+			name: "nor groups are merged",
+			id: New().Equal("active", true).
+				Nor(New().Equal("role", "banned")).
+				Nor(New().IsNotNull("deletedAt")),
+			want: bson.M{"active": true, "$nor": bson.A{
+				bson.M{"role": "banned"},
+				bson.M{"deletedAt": bson.M{"$exists": true}},
+			}},
+		},
+		{
+			name: "empty and nil groups are ignored",
+			id:   New().Equal("active", true).Or().And(nil).Nor(),
+			want: bson.M{"active": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.id.ToBSON())
+		})
+	}
+}
+
+func TestIdentifier_OrAnd_Lookup(t *testing.T) {
+	id := New().Equal("active", true).Or(New().IsNotNull("deletedAt"), New().Equal("role", "admin"))
+
+	assert.True(t, id.Has("deletedAt"), "nested conditions count")
+	assert.False(t, id.Has("$or"))
+	_, ok := id.Get("$or")
+	assert.False(t, ok)
+
+	assert.Equal(t, map[string]interface{}{
+		"active": true,
+		"$or": []map[string]interface{}{
+			{"deletedAt IS NOT NULL": true},
+			{"role": "admin"},
+		},
+	}, id.ToMap())
+	assert.Equal(t, "{active: true, $or: [{deletedAt IS NOT NULL: true}, {role: admin}]}", id.String())
+}
+
+func TestIdentifier_NegationOperators(t *testing.T) {
+	tests := []struct {
+		name string
+		id   IIdentifier
+		want bson.M
+	}{
+		{
+			name: "not equal",
+			id:   New().NotEqual("status", "draft"),
+			want: bson.M{"status": bson.M{"$ne": "draft"}},
+		},
+		{
+			name: "not in",
+			id:   New().NotIn("role", []interface{}{"bot", "system"}),
+			want: bson.M{"role": bson.M{"$nin": []interface{}{"bot", "system"}}},
+		},
+		{
+			name: "exists",
+			id:   New().Exists("email", true),
+			want: bson.M{"email": bson.M{"$exists": true}},
+		},
+		{
+			name: "does not exist",
+			id:   New().Exists("legacyId", false),
+			want: bson.M{"legacyId": bson.M{"$exists": false}},
+		},
+		{
+			name: "combined on one field",
+			id:   New().Exists("score", true).NotEqual("score", -1),
+			want: bson.M{"score": bson.M{"$exists": true, "$ne": -1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.id.ToBSON())
+		})
+	}
+}
+
+func TestIdentifier_NegationOperators_ToMap(t *testing.T) {
+	id := New().NotEqual("a", 1).NotIn("b", []interface{}{2}).Exists("c", false)
+
+	assert.Equal(t, map[string]interface{}{
+		"a !=":     1,
+		"b NOT IN": []interface{}{2},
+		"c EXISTS": false,
+	}, id.ToMap())
+}
+
+func TestSoftDeleteIdentifiers_ToBSON(t *testing.T) {
+	// Null equality, not $exists, so documents without the field count as live
+	assert.Equal(t, bson.M{"deletedAt": nil}, NotDeleted().ToBSON())
+	assert.Equal(t, bson.M{"deletedAt": bson.M{"$ne": nil}}, Deleted().ToBSON())
+}
@@ -0,0 +1,54 @@
+package identifier
+
+// Specification is a named, reusable query such as "premium active users".
+// Specifications compose with And, Or and Not, and every IIdentifier is one,
+// so ad-hoc conditions mix with named ones.
+type Specification interface {
+	ToIdentifier() IIdentifier
+}
+
+// SpecFunc adapts a function building an identifier to Specification, e.g.
+// SpecFunc(Active)
+type SpecFunc func() IIdentifier
+
+func (f SpecFunc) ToIdentifier() IIdentifier {
+	return f()
+}
+
+// ToIdentifier returns the identifier itself
+func (i *Identifier) ToIdentifier() IIdentifier {
+	return i
+}
+
+// And is satisfied by documents satisfying every one of specs
+func And(specs ...Specification) Specification {
+	return SpecFunc(func() IIdentifier {
+		return New().And(identifiers(specs)...)
+	})
+}
+
+// Or is satisfied by documents satisfying at least one of specs
+func Or(specs ...Specification) Specification {
+	return SpecFunc(func() IIdentifier {
+		return New().Or(identifiers(specs)...)
+	})
+}
+
+// Not is satisfied by documents not satisfying spec
+func Not(spec Specification) Specification {
+	return SpecFunc(func() IIdentifier {
+		return New().Nor(identifiers([]Specification{spec})...)
+	})
+}
+
+// identifiers builds the identifiers of the non-nil specs. Each call builds
+// them afresh, so a composed specification can be reused.
+func identifiers(specs []Specification) []IIdentifier {
+	ids := make([]IIdentifier, 0, len(specs))
+	for _, spec := range specs {
+		if spec != nil {
+			ids = append(ids, spec.ToIdentifier())
+		}
+	}
+	return ids
+}
@@ -0,0 +1,68 @@
+package identifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var (
+	activeUsers  = SpecFunc(Active)
+	premiumUsers = SpecFunc(func() IIdentifier { return New().Equal("plan", "premium") })
+)
+
+func TestSpecification_Compose(t *testing.T) {
+	tests := []struct {
+		name string
+		spec Specification
+		want bson.M
+	}{
+		{
+			name: "and",
+			spec: And(premiumUsers, activeUsers),
+			want: bson.M{"$and": bson.A{bson.M{"plan": "premium"}, bson.M{"active": true}}},
+		},
+		{
+			name: "or",
+			spec: Or(premiumUsers, activeUsers),
+			want: bson.M{"$or": bson.A{bson.M{"plan": "premium"}, bson.M{"active": true}}},
+		},
+		{
+			name: "not",
+			spec: Not(premiumUsers),
+			want: bson.M{"$nor": bson.A{bson.M{"plan": "premium"}}},
+		},
+		{
+			name: "nested with an ad-hoc identifier",
+			spec: And(activeUsers, Not(Or(premiumUsers, New().GreaterThan("age", 65)))),
+			want: bson.M{"$and": bson.A{
+				bson.M{"active": true},
+				bson.M{"$nor": bson.A{
+					bson.M{"$or": bson.A{bson.M{"plan": "premium"}, bson.M{"age": bson.M{"$gt": 65}}}},
+				}},
+			}},
+		},
+		{
+			name: "nil specs are ignored",
+			spec: And(nil, activeUsers),
+			want: bson.M{"$and": bson.A{bson.M{"active": true}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.spec.ToIdentifier().ToBSON())
+		})
+	}
+}
+
+func TestSpecification_Reusable(t *testing.T) {
+	premiumActive := And(premiumUsers, activeUsers)
+
+	first := premiumActive.ToIdentifier()
+	first.Equal("tenant", "acme")
+
+	assert.Equal(t, bson.M{"$and": bson.A{bson.M{"plan": "premium"}, bson.M{"active": true}}},
+		premiumActive.ToIdentifier().ToBSON(), "each call builds a fresh identifier")
+}
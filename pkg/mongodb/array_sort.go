@@ -0,0 +1,66 @@
+package mongodb
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+)
+
+// arraySortKeyPrefix names the temporary fields holding array sort keys
+const arraySortKeyPrefix = "_arraySort"
+
+func validateArraySort(keys []domain.ArraySort) error {
+	for _, key := range keys {
+		if key.Field == "" {
+			return fmt.Errorf("%w: array sort requires a field", uowerrors.ErrInvalidQueryParams)
+		}
+		switch key.Mode {
+		case "", domain.ArraySortMin, domain.ArraySortMax:
+		default:
+			return fmt.Errorf("%w: unknown array sort mode %q for %s", uowerrors.ErrInvalidQueryParams, key.Mode, key.Field)
+		}
+	}
+	return nil
+}
+
+// sortStages builds the pipeline stages sorting by the array keys followed by
+// the native sort. Each array field is reduced to its min or max element in a
+// temporary field that is removed again after sorting.
+func sortStages(keys []domain.ArraySort, sort bson.D) bson.A {
+	if len(keys) == 0 {
+		if len(sort) == 0 {
+			return bson.A{}
+		}
+		return bson.A{bson.M{"$sort": sort}}
+	}
+
+	computed := bson.M{}
+	removed := bson.M{}
+	combined := bson.D{}
+	for i, key := range keys {
+		name := fmt.Sprintf("%s%d", arraySortKeyPrefix, i)
+
+		reducer := "$min"
+		if key.Mode == domain.ArraySortMax {
+			reducer = "$max"
+		}
+		computed[name] = bson.M{reducer: "$" + key.Field}
+		removed[name] = 0
+
+		direction := 1
+		if key.Direction == domain.SortDesc {
+			direction = -1
+		}
+		combined = append(combined, bson.E{Key: name, Value: direction})
+	}
+	combined = append(combined, sort...)
+
+	return bson.A{
+		bson.M{"$addFields": computed},
+		bson.M{"$sort": combined},
+		bson.M{"$project": removed},
+	}
+}
@@ -0,0 +1,116 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+)
+
+type TestTaggedProduct struct {
+	domain.BaseEntity `bson:",inline"`
+	Tags              []string `bson:"tags" json:"tags"`
+}
+
+func TestSortStages(t *testing.T) {
+	assert.Empty(t, sortStages(nil, bson.D{}))
+	assert.Equal(t, bson.A{bson.M{"$sort": bson.D{{Key: "name", Value: 1}}}}, sortStages(nil, bson.D{{Key: "name", Value: 1}}))
+
+	stages := sortStages([]domain.ArraySort{
+		{Field: "tags", Direction: domain.SortDesc},
+		{Field: "scores", Direction: domain.SortAsc, Mode: domain.ArraySortMax},
+	}, bson.D{{Key: "name", Value: 1}})
+
+	assert.Equal(t, bson.A{
+		bson.M{"$addFields": bson.M{
+			"_arraySort0": bson.M{"$min": "$tags"},
+			"_arraySort1": bson.M{"$max": "$scores"},
+		}},
+		bson.M{"$sort": bson.D{
+			{Key: "_arraySort0", Value: -1},
+			{Key: "_arraySort1", Value: 1},
+			{Key: "name", Value: 1},
+		}},
+		bson.M{"$project": bson.M{"_arraySort0": 0, "_arraySort1": 0}},
+	}, stages)
+}
+
+func TestUnitOfWork_InvalidArraySortRejected(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestTaggedProduct](t, &Config{EnableStats: true})
+	ctx := context.Background()
+
+	for _, key := range []domain.ArraySort{
+		{Direction: domain.SortAsc},
+		{Field: "tags", Mode: "median"},
+	} {
+		query := domain.QueryParams[*TestTaggedProduct]{ArraySort: []domain.ArraySort{key}}
+
+		_, _, err := uow.FindAllWithPagination(ctx, query)
+		assert.ErrorIs(t, err, uowerrors.ErrInvalidQueryParams)
+
+		_, _, err = uow.GetTrashedWithPagination(ctx, query)
+		assert.ErrorIs(t, err, uowerrors.ErrInvalidQueryParams)
+	}
+
+	assert.Zero(t, uow.Stats().Total())
+}
+
+func TestUnitOfWork_ArraySort_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestTaggedProduct](t)
+	ctx := context.Background()
+
+	for name, tags := range map[string][]string{
+		"laptop":  {"portable", "computer"},
+		"desk":    {"furniture", "wood"},
+		"monitor": {"display", "zoom"},
+	} {
+		product := &TestTaggedProduct{Tags: tags}
+		product.Name = name
+		_, err := uow.Insert(ctx, product)
+		require.NoError(t, err)
+	}
+
+	names := func(query domain.QueryParams[*TestTaggedProduct]) []string {
+		t.Helper()
+		products, total, err := uow.FindAllWithPagination(ctx, query)
+		require.NoError(t, err)
+		assert.Equal(t, uint(3), total)
+
+		result := make([]string, len(products))
+		for i, product := range products {
+			result[i] = product.Name
+		}
+		return result
+	}
+
+	// Lowest tags: computer, display, furniture
+	assert.Equal(t, []string{"laptop", "monitor", "desk"}, names(domain.QueryParams[*TestTaggedProduct]{
+		ArraySort: []domain.ArraySort{{Field: "tags", Direction: domain.SortAsc}},
+	}))
+
+	// Descending by lowest tag differs from the native sort, which uses the
+	// highest tag (zoom, wood, portable)
+	assert.Equal(t, []string{"desk", "monitor", "laptop"}, names(domain.QueryParams[*TestTaggedProduct]{
+		ArraySort: []domain.ArraySort{{Field: "tags", Direction: domain.SortDesc}},
+	}))
+	assert.Equal(t, []string{"monitor", "desk", "laptop"}, names(domain.QueryParams[*TestTaggedProduct]{
+		Sort: domain.SortMap{"tags": domain.SortDesc},
+	}))
+
+	// Highest tags: portable, wood, zoom
+	assert.Equal(t, []string{"laptop", "desk"}, names(domain.QueryParams[*TestTaggedProduct]{
+		ArraySort: []domain.ArraySort{{Field: "tags", Direction: domain.SortAsc, Mode: domain.ArraySortMax}},
+		Limit:     2,
+	}))
+
+	products, _, err := uow.FindAllWithPagination(ctx, domain.QueryParams[*TestTaggedProduct]{
+		ArraySort: []domain.ArraySort{{Field: "tags", Direction: domain.SortAsc}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"portable", "computer"}, products[0].Tags)
+}
@@ -0,0 +1,242 @@
+package mongodb
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+)
+
+// FieldChange is the old and new value of one field; a nil side means the
+// field was absent
+type FieldChange struct {
+	Old interface{} `bson:"old" json:"old"`
+	New interface{} `bson:"new" json:"new"`
+}
+
+// AuditRecord describes one audited write. Changes is keyed by dotted field
+// path and only holds fields whose value changed.
+type AuditRecord struct {
+	Collection string                 `bson:"collection" json:"collection"`
+	DocumentID primitive.ObjectID     `bson:"documentId" json:"documentId"`
+	Operation  string                 `bson:"operation" json:"operation"`
+	Changes    map[string]FieldChange `bson:"changes" json:"changes"`
+	At         time.Time              `bson:"at" json:"at"`
+}
+
+// AuditSink receives an AuditRecord after each audited write
+type AuditSink func(ctx context.Context, record AuditRecord)
+
+// WithAuditSink makes Update and RetryOnConflict report a field-level diff of
+// every change to sink; nil disables auditing
+func WithAuditSink(sink AuditSink) FactoryOption {
+	return func(s *factorySettings) {
+		s.auditSink = sink
+	}
+}
+
+// auditedUpdate performs Update and reports what changed to the audit sink
+func (uow *UnitOfWork[T]) auditedUpdate(ctx context.Context, identifier identifier.IIdentifier, entity T) (T, error) {
+	before, err := uow.update(ctx, identifier, entity, options.Before)
+	if err != nil {
+		return before, err
+	}
+	return uow.auditWritten(ctx, before, entity), nil
+}
+
+// auditWritten reports what {$set: entity} changed in before to the audit
+// sink and returns the updated entity
+func (uow *UnitOfWork[T]) auditWritten(ctx context.Context, before, entity T) T {
+	updated, changes := uow.writtenChanges(ctx, before, entity)
+	if changes != nil {
+		uow.audit(ctx, before, changes)
+	}
+	return updated
+}
+
+func (uow *UnitOfWork[T]) audit(ctx context.Context, before T, changes map[string]FieldChange) {
+	uow.auditSink(ctx, AuditRecord{
+		Collection: uow.collectionName,
+		DocumentID: before.GetID(),
+		Operation:  "update",
+		Changes:    changes,
+		At:         utcNow(),
+	})
+}
+
+// diffedUpdate performs Update while capturing the document before the write
+// and the field-level changes. The update is a $set of the entity, so the
+// after-state is the before-state overlaid with the entity's fields, both
+// encoded with the client registry. The write has committed by the time the
+// diff is computed, so a diff failure is logged rather than returned: updated
+// is then the entity as written and changes is nil.
+func (uow *UnitOfWork[T]) diffedUpdate(ctx context.Context, identifier identifier.IIdentifier, entity T) (before, updated T, changes map[string]FieldChange, err error) {
+	before, err = uow.update(ctx, identifier, entity, options.Before)
+	if err != nil {
+		return before, updated, nil, err
+	}
+
+	updated, changes = uow.writtenChanges(ctx, before, entity)
+	return before, updated, changes, nil
+}
+
+// writtenChanges diffs a committed {$set: entity} against before, logging a
+// diff failure and returning the entity as written with nil changes
+func (uow *UnitOfWork[T]) writtenChanges(ctx context.Context, before, entity T) (T, map[string]FieldChange) {
+	updated, changes, err := uow.diffUpdate(before, entity)
+	if err != nil {
+		if uow.logger != nil {
+			uow.logger.WarnContext(ctx, "failed to diff update",
+				slog.String("collection", uow.collectionName), slog.Any("error", err))
+		}
+		return entity, nil
+	}
+	return updated, changes
+}
+
+// diffUpdate returns the document {$set: entity} turns before into, and the
+// fields that changed
+func (uow *UnitOfWork[T]) diffUpdate(before, entity T) (updated T, changes map[string]FieldChange, err error) {
+	beforeDoc, err := uow.marshalPlain(before)
+	if err != nil {
+		return updated, nil, err
+	}
+	patch, err := uow.marshalPlain(entity)
+	if err != nil {
+		return updated, nil, err
+	}
+	afterDoc, err := overlayDocument(beforeDoc, patch)
+	if err != nil {
+		return updated, nil, err
+	}
+
+	if changes, err = DiffDocuments(beforeDoc, afterDoc); err != nil {
+		return updated, nil, err
+	}
+	if err := uow.unmarshal(afterDoc, &updated); err != nil {
+		return updated, nil, err
+	}
+	return updated, changes, nil
+}
+
+// overlayDocument returns base with every top-level field of patch set on it,
+// mirroring {$set: patch}
+func overlayDocument(base, patch bson.Raw) (bson.Raw, error) {
+	patchElements, err := patch.Elements()
+	if err != nil {
+		return nil, err
+	}
+	replacements := make(map[string]bson.RawValue, len(patchElements))
+	for _, element := range patchElements {
+		replacements[element.Key()] = element.Value()
+	}
+
+	baseElements, err := base.Elements()
+	if err != nil {
+		return nil, err
+	}
+
+	merged := bson.D{}
+	for _, element := range baseElements {
+		value := element.Value()
+		if replacement, ok := replacements[element.Key()]; ok {
+			value = replacement
+			delete(replacements, element.Key())
+		}
+		merged = append(merged, bson.E{Key: element.Key(), Value: value})
+	}
+	for _, element := range patchElements {
+		if value, ok := replacements[element.Key()]; ok {
+			merged = append(merged, bson.E{Key: element.Key(), Value: value})
+		}
+	}
+
+	return bson.Marshal(merged)
+}
+
+// DiffDocuments compares two BSON documents and returns the fields whose value
+// differs, keyed by dotted path. Embedded documents are compared field by
+// field; arrays and other values are compared as a whole.
+func DiffDocuments(before, after bson.Raw) (map[string]FieldChange, error) {
+	changes := make(map[string]FieldChange)
+	if err := diffInto(changes, "", before, after); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func diffInto(changes map[string]FieldChange, prefix string, before, after bson.Raw) error {
+	beforeElements, err := before.Elements()
+	if err != nil {
+		return err
+	}
+	afterElements, err := after.Elements()
+	if err != nil {
+		return err
+	}
+
+	afterValues := make(map[string]bson.RawValue, len(afterElements))
+	for _, element := range afterElements {
+		afterValues[element.Key()] = element.Value()
+	}
+
+	seen := make(map[string]bool, len(beforeElements))
+	for _, element := range beforeElements {
+		key := element.Key()
+		seen[key] = true
+		oldValue := element.Value()
+
+		newValue, ok := afterValues[key]
+		if !ok {
+			if err := recordChange(changes, prefix+key, &oldValue, nil); err != nil {
+				return err
+			}
+			continue
+		}
+		if oldValue.Equal(newValue) {
+			continue
+		}
+		if oldValue.Type == bsontype.EmbeddedDocument && newValue.Type == bsontype.EmbeddedDocument {
+			if err := diffInto(changes, prefix+key+".", oldValue.Document(), newValue.Document()); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := recordChange(changes, prefix+key, &oldValue, &newValue); err != nil {
+			return err
+		}
+	}
+
+	for _, element := range afterElements {
+		if key := element.Key(); !seen[key] {
+			newValue := element.Value()
+			if err := recordChange(changes, prefix+key, nil, &newValue); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func recordChange(changes map[string]FieldChange, path string, oldValue, newValue *bson.RawValue) error {
+	var change FieldChange
+	if oldValue != nil {
+		if err := oldValue.Unmarshal(&change.Old); err != nil {
+			return err
+		}
+	}
+	if newValue != nil {
+		if err := newValue.Unmarshal(&change.New); err != nil {
+			return err
+		}
+	}
+	changes[path] = change
+	return nil
+}
@@ -0,0 +1,147 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+)
+
+func mustMarshal(t *testing.T, doc interface{}) bson.Raw {
+	t.Helper()
+	data, err := bson.Marshal(doc)
+	require.NoError(t, err)
+	return data
+}
+
+func TestDiffDocuments(t *testing.T) {
+	before := mustMarshal(t, bson.D{
+		{Key: "name", Value: "Laptop"},
+		{Key: "price", Value: 999.99},
+		{Key: "tags", Value: bson.A{"a", "b"}},
+		{Key: "dimensions", Value: bson.D{{Key: "width", Value: 30}, {Key: "height", Value: 2}}},
+		{Key: "discontinued", Value: true},
+	})
+	after := mustMarshal(t, bson.D{
+		{Key: "name", Value: "Laptop"},
+		{Key: "price", Value: 899.99},
+		{Key: "tags", Value: bson.A{"a", "c"}},
+		{Key: "dimensions", Value: bson.D{{Key: "width", Value: 30}, {Key: "height", Value: 3}}},
+		{Key: "stock", Value: 5},
+	})
+
+	changes, err := DiffDocuments(before, after)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]FieldChange{
+		"price":             {Old: 999.99, New: 899.99},
+		"tags":              {Old: bson.A{"a", "b"}, New: bson.A{"a", "c"}},
+		"dimensions.height": {Old: int32(2), New: int32(3)},
+		"discontinued":      {Old: true, New: nil},
+		"stock":             {Old: nil, New: int32(5)},
+	}, changes)
+}
+
+func TestDiffDocuments_Identical(t *testing.T) {
+	doc := mustMarshal(t, bson.M{"name": "Laptop", "price": 999.99})
+
+	changes, err := DiffDocuments(doc, doc)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestOverlayDocument(t *testing.T) {
+	base := mustMarshal(t, bson.D{{Key: "name", Value: "old"}, {Key: "age", Value: 30}})
+	patch := mustMarshal(t, bson.D{{Key: "age", Value: 31}, {Key: "email", Value: "a@example.com"}})
+
+	merged, err := overlayDocument(base, patch)
+	require.NoError(t, err)
+
+	var result bson.D
+	require.NoError(t, bson.Unmarshal(merged, &result))
+	assert.Equal(t, bson.D{
+		{Key: "name", Value: "old"},
+		{Key: "age", Value: int32(31)},
+		{Key: "email", Value: "a@example.com"},
+	}, result)
+}
+
+func TestUnitOfWork_AuditedUpdate_Integration(t *testing.T) {
+	var records []AuditRecord
+	uow := newIntegrationUnitOfWork[*TestUser](t, WithAuditSink(func(_ context.Context, record AuditRecord) {
+		records = append(records, record)
+	}))
+	ctx := context.Background()
+
+	created, err := uow.Insert(ctx, &TestUser{Email: "old@example.com", Age: 30, Active: true})
+	require.NoError(t, err)
+
+	created.Email = "new@example.com"
+	updated, err := uow.Update(ctx, identifier.New().Equal("_id", created.GetID()), created)
+	require.NoError(t, err)
+	assert.Equal(t, "new@example.com", updated.Email)
+
+	require.Len(t, records, 1)
+	record := records[0]
+	assert.Equal(t, "testusers", record.Collection)
+	assert.Equal(t, created.GetID(), record.DocumentID)
+	assert.Equal(t, "update", record.Operation)
+
+	// updatedAt only shows up when the update lands in a later millisecond
+	delete(record.Changes, "updatedAt")
+	assert.Equal(t, []string{"email"}, keysOf(record.Changes))
+	assert.Equal(t, FieldChange{Old: "old@example.com", New: "new@example.com"}, record.Changes["email"])
+}
+
+func TestUnitOfWork_AuditWritten(t *testing.T) {
+	var records []AuditRecord
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+	uow.auditSink = func(_ context.Context, record AuditRecord) { records = append(records, record) }
+
+	before := &TestUser{Email: "a@example.com", Active: true}
+	before.SetID(primitive.NewObjectID())
+	entity := &TestUser{Email: "a@example.com", Active: false}
+	entity.SetID(before.GetID())
+
+	updated := uow.auditWritten(context.Background(), before, entity)
+	assert.False(t, updated.Active)
+	require.Len(t, records, 1)
+	assert.Equal(t, before.GetID(), records[0].DocumentID)
+	assert.Equal(t, FieldChange{Old: true, New: false}, records[0].Changes["active"])
+}
+
+func TestUnitOfWork_AuditedRetryOnConflict_Integration(t *testing.T) {
+	var records []AuditRecord
+	uow := newIntegrationUnitOfWork[*TestUser](t, WithAuditSink(func(_ context.Context, record AuditRecord) {
+		records = append(records, record)
+	}))
+	ctx := context.Background()
+
+	created, err := uow.Insert(ctx, &TestUser{Email: "a@example.com", Active: true})
+	require.NoError(t, err)
+
+	updated, err := uow.RetryOnConflict(ctx, created.GetID(), func(user *TestUser) error {
+		user.Active = false
+		return nil
+	}, 3)
+	require.NoError(t, err)
+	assert.False(t, updated.Active)
+
+	require.Len(t, records, 1)
+	assert.Equal(t, created.GetID(), records[0].DocumentID)
+	assert.Equal(t, FieldChange{Old: true, New: false}, records[0].Changes["active"])
+	assert.Contains(t, records[0].Changes, "updatedAt", "the version always moves")
+}
+
+func keysOf(changes map[string]FieldChange) []string {
+	keys := make([]string, 0, len(changes))
+	for key := range changes {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -6,7 +6,9 @@ import (
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // BaseRepository implements the base repository functionality using Unit of Work
@@ -33,6 +35,45 @@ func (r *BaseRepository[T]) Update(ctx context.Context, id identifier.IIdentifie
 	return uow.Update(ctx, id, entity)
 }
 
+// UpdateFields sets only the given fields on an entity
+func (r *BaseRepository[T]) UpdateFields(ctx context.Context, id identifier.IIdentifier, fields bson.M) (T, error) {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.UpdateFields(ctx, id, fields)
+}
+
+// UpdateIf sets fields on an entity only while condition also matches
+func (r *BaseRepository[T]) UpdateIf(ctx context.Context, id identifier.IIdentifier, condition identifier.IIdentifier, fields bson.M) (T, bool, error) {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.UpdateIf(ctx, id, condition, fields)
+}
+
+// UpdateWithArrayFilters applies an operator update whose $[<identifier>]
+// paths are bound by arrayFilters
+func (r *BaseRepository[T]) UpdateWithArrayFilters(ctx context.Context, id identifier.IIdentifier, update bson.M, arrayFilters []interface{}) (T, error) {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.UpdateWithArrayFilters(ctx, id, update, arrayFilters)
+}
+
+// RetryOnConflict applies mutate to the entity with id, re-reading and
+// retrying when a concurrent update wins the race
+func (r *BaseRepository[T]) RetryOnConflict(ctx context.Context, id primitive.ObjectID, mutate func(T) error, maxAttempts int) (T, error) {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.RetryOnConflict(ctx, id, mutate, maxAttempts)
+}
+
+// UpdateMany sets fields on every entity matched by id and returns the count
+func (r *BaseRepository[T]) UpdateMany(ctx context.Context, id identifier.IIdentifier, fields bson.M) (int64, error) {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.UpdateMany(ctx, id, fields)
+}
+
+// Upsert updates the entity matched by the identifier, or inserts it when none
+// matches
+func (r *BaseRepository[T]) Upsert(ctx context.Context, id identifier.IIdentifier, entity T) (T, error) {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.Upsert(ctx, id, entity)
+}
+
 // Delete removes an entity
 func (r *BaseRepository[T]) Delete(ctx context.Context, id identifier.IIdentifier) error {
 	uow := r.factory.CreateWithContext(ctx)
@@ -45,18 +86,93 @@ func (r *BaseRepository[T]) FindOneById(ctx context.Context, id primitive.Object
 	return uow.FindOneById(ctx, id)
 }
 
+// FindOneByHexId finds an entity by the hex string form of its ID
+func (r *BaseRepository[T]) FindOneByHexId(ctx context.Context, hexID string) (T, error) {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.FindOneByHexId(ctx, hexID)
+}
+
+// FindOneByIdWithTrashed finds an entity by its ID even when it is soft deleted
+func (r *BaseRepository[T]) FindOneByIdWithTrashed(ctx context.Context, id primitive.ObjectID) (T, error) {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.FindOneByIdWithTrashed(ctx, id)
+}
+
+// FindByIds finds the entities with the given IDs in a single query
+func (r *BaseRepository[T]) FindByIds(ctx context.Context, ids []primitive.ObjectID) ([]T, error) {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.FindByIds(ctx, ids)
+}
+
+// FindByIdsOrdered finds the entities with the given IDs in input order, with
+// nil for missing ones
+func (r *BaseRepository[T]) FindByIdsOrdered(ctx context.Context, ids []primitive.ObjectID) ([]T, error) {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.FindByIdsOrdered(ctx, ids)
+}
+
 // FindOne finds a single entity based on identifier
 func (r *BaseRepository[T]) FindOne(ctx context.Context, id identifier.IIdentifier) (T, error) {
 	uow := r.factory.CreateWithContext(ctx)
 	return uow.FindOneByIdentifier(ctx, id)
 }
 
+// TryFindOneById finds an entity by its ID, reporting absence through the found flag
+func (r *BaseRepository[T]) TryFindOneById(ctx context.Context, id primitive.ObjectID) (T, bool, error) {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.TryFindOneById(ctx, id)
+}
+
+// TryFindOne finds a single entity based on identifier, reporting absence through the found flag
+func (r *BaseRepository[T]) TryFindOne(ctx context.Context, id identifier.IIdentifier) (T, bool, error) {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.TryFindOne(ctx, id)
+}
+
 // FindAll finds all entities matching the identifier
 func (r *BaseRepository[T]) FindAll(ctx context.Context, id identifier.IIdentifier) ([]T, error) {
 	uow := r.factory.CreateWithContext(ctx)
-	// For now, we'll use FindAll and then filter - in a real implementation
-	// you might want to extend the unit of work interface
-	return uow.FindAll(ctx)
+	if id == nil {
+		return uow.FindAll(ctx)
+	}
+	return uow.FindAllRaw(ctx, id.ToBSON())
+}
+
+// FindAllMatchingAny finds the entities matching at least one of ids, in a
+// single $or query
+func (r *BaseRepository[T]) FindAllMatchingAny(ctx context.Context, ids ...identifier.IIdentifier) ([]T, error) {
+	return r.findAllMatching(ctx, identifier.New().Or(ids...))
+}
+
+// FindAllMatchingAll finds the entities matching every one of ids, in a
+// single $and query
+func (r *BaseRepository[T]) FindAllMatchingAll(ctx context.Context, ids ...identifier.IIdentifier) ([]T, error) {
+	return r.findAllMatching(ctx, identifier.New().And(ids...))
+}
+
+// FindAllSatisfying finds the entities satisfying spec
+func (r *BaseRepository[T]) FindAllSatisfying(ctx context.Context, spec identifier.Specification) ([]T, error) {
+	return r.findAllMatching(ctx, specIdentifier(spec))
+}
+
+// specIdentifier builds the identifier of spec; a nil spec gives nil, which
+// findAllMatching rejects
+func specIdentifier(spec identifier.Specification) identifier.IIdentifier {
+	if spec == nil {
+		return nil
+	}
+	return spec.ToIdentifier()
+}
+
+// findAllMatching runs a combined identifier, refusing one with no fragments
+// rather than returning every entity
+func (r *BaseRepository[T]) findAllMatching(ctx context.Context, combined identifier.IIdentifier) ([]T, error) {
+	filter, err := predicateFilter(combined)
+	if err != nil {
+		return nil, err
+	}
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.FindAllRaw(ctx, filter)
 }
 
 // FindAllWithPagination finds entities with pagination support
@@ -66,6 +182,45 @@ func (r *BaseRepository[T]) FindAllWithPagination(ctx context.Context, query dom
 	return entities, int64(count), err
 }
 
+// Count returns how many entities match the identifier
+func (r *BaseRepository[T]) Count(ctx context.Context, id identifier.IIdentifier) (int64, error) {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.Count(ctx, id)
+}
+
+// Exists reports whether an entity matches the identifier
+func (r *BaseRepository[T]) Exists(ctx context.Context, id identifier.IIdentifier) (bool, error) {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.Exists(ctx, id)
+}
+
+// Distinct returns the unique values of field among the live entities
+// matching filter
+func (r *BaseRepository[T]) Distinct(ctx context.Context, field string, filter identifier.IIdentifier) ([]interface{}, error) {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.Distinct(ctx, field, filter)
+}
+
+// DistinctWithCounts returns the values of field among the live entities
+// matching filter with how many entities hold each
+func (r *BaseRepository[T]) DistinctWithCounts(ctx context.Context, field string, filter identifier.IIdentifier) ([]persistence.ValueCount, error) {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.DistinctWithCounts(ctx, field, filter)
+}
+
+// Aggregate runs pipeline over the live entities and decodes the results
+func (r *BaseRepository[T]) Aggregate(ctx context.Context, pipeline mongo.Pipeline, result interface{}) error {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.Aggregate(ctx, pipeline, result)
+}
+
+// DeleteAll permanently removes every entity, trashed ones included, when
+// confirm is true
+func (r *BaseRepository[T]) DeleteAll(ctx context.Context, confirm bool) error {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.DeleteAll(ctx, confirm)
+}
+
 // BulkInsert creates multiple entities
 func (r *BaseRepository[T]) BulkInsert(ctx context.Context, entities []T) ([]T, error) {
 	uow := r.factory.CreateWithContext(ctx)
@@ -73,13 +228,19 @@ func (r *BaseRepository[T]) BulkInsert(ctx context.Context, entities []T) ([]T,
 }
 
 // BulkUpdate modifies multiple entities
-func (r *BaseRepository[T]) BulkUpdate(ctx context.Context, entities []T) ([]T, error) {
+func (r *BaseRepository[T]) BulkUpdate(ctx context.Context, entities []T) (persistence.BulkResult, error) {
 	uow := r.factory.CreateWithContext(ctx)
 	return uow.BulkUpdate(ctx, entities)
 }
 
+// BulkUpsert inserts or updates multiple entities by ID
+func (r *BaseRepository[T]) BulkUpsert(ctx context.Context, entities []T) ([]T, error) {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.BulkUpsert(ctx, entities)
+}
+
 // BulkDelete removes multiple entities
-func (r *BaseRepository[T]) BulkDelete(ctx context.Context, identifiers []identifier.IIdentifier) error {
+func (r *BaseRepository[T]) BulkDelete(ctx context.Context, identifiers []identifier.IIdentifier) (persistence.BulkResult, error) {
 	uow := r.factory.CreateWithContext(ctx)
 	return uow.BulkHardDelete(ctx, identifiers)
 }
@@ -91,17 +252,35 @@ func (r *BaseRepository[T]) SoftDelete(ctx context.Context, id identifier.IIdent
 }
 
 // BulkSoftDelete marks multiple entities as deleted
-func (r *BaseRepository[T]) BulkSoftDelete(ctx context.Context, identifiers []identifier.IIdentifier) error {
+func (r *BaseRepository[T]) BulkSoftDelete(ctx context.Context, identifiers []identifier.IIdentifier) (persistence.BulkResult, error) {
 	uow := r.factory.CreateWithContext(ctx)
 	return uow.BulkSoftDelete(ctx, identifiers)
 }
 
+// SoftDeleteMany marks every entity matched by id as deleted and returns the count
+func (r *BaseRepository[T]) SoftDeleteMany(ctx context.Context, id identifier.IIdentifier) (int64, error) {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.SoftDeleteMany(ctx, id)
+}
+
 // Restore recovers a soft-deleted entity
 func (r *BaseRepository[T]) Restore(ctx context.Context, id identifier.IIdentifier) (T, error) {
 	uow := r.factory.CreateWithContext(ctx)
 	return uow.Restore(ctx, id)
 }
 
+// RestoreMany recovers every soft-deleted entity matched by id and returns the count
+func (r *BaseRepository[T]) RestoreMany(ctx context.Context, id identifier.IIdentifier) (int64, error) {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.RestoreMany(ctx, id)
+}
+
+// BulkRestore recovers the soft-deleted entity matched by each identifier
+func (r *BaseRepository[T]) BulkRestore(ctx context.Context, identifiers []identifier.IIdentifier) error {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.BulkRestore(ctx, identifiers)
+}
+
 // GetTrashed retrieves all soft-deleted entities
 func (r *BaseRepository[T]) GetTrashed(ctx context.Context) ([]T, error) {
 	uow := r.factory.CreateWithContext(ctx)
@@ -126,3 +305,32 @@ func (r *BaseRepository[T]) RollbackTransaction(ctx context.Context) error {
 	uow.RollbackTransaction(ctx)
 	return nil
 }
+
+// RunInTransaction runs fn in a transaction, committing when it returns nil.
+// Repository calls made with the ctx passed to fn take part in it; fn may run
+// more than once.
+func (r *BaseRepository[T]) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.RunInTransaction(ctx, fn)
+}
+
+// Ping verifies that the database is reachable
+func (r *BaseRepository[T]) Ping(ctx context.Context) error {
+	return r.factory.Ping(ctx)
+}
+
+// CreateUniqueIndex makes the server reject duplicate values of field among
+// the live entities
+func (r *BaseRepository[T]) CreateUniqueIndex(ctx context.Context, field string) error {
+	uow := r.factory.CreateWithContext(ctx)
+	return uow.CreateUniqueIndex(ctx, field)
+}
+
+// CollectionName returns the collection the repository reads and writes
+func (r *BaseRepository[T]) CollectionName() string {
+	if factory, ok := r.factory.(*Factory[T]); ok {
+		return factory.collectionName()
+	}
+	var zero T
+	return getCollectionName(zero)
+}
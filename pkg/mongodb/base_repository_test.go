@@ -0,0 +1,459 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/services"
+)
+
+func unreachableConfig() *Config {
+	config := NewConfig()
+	config.Port = 1
+	config.Timeout = 200 * time.Millisecond
+	return config
+}
+
+func TestBaseRepository_Ping_Unreachable(t *testing.T) {
+	factory, err := NewFactory[*persistence.User](unreachableConfig())
+	require.NoError(t, err)
+
+	repo := NewBaseRepository[*persistence.User](factory)
+
+	assert.NotPanics(t, func() {
+		err = repo.Ping(context.Background())
+	})
+	assert.Error(t, err)
+	assert.True(t, uowerrors.IsConnection(err))
+}
+
+func TestService_Ping_Unreachable(t *testing.T) {
+	factory, err := NewFactory[*persistence.User](unreachableConfig())
+	require.NoError(t, err)
+
+	service := services.NewUserService(NewUserRepository(NewBaseRepository[*persistence.User](factory)))
+
+	err = service.Ping(context.Background())
+	assert.ErrorIs(t, err, uowerrors.ErrDatabaseConnection)
+}
+
+// softDeleteRepo fakes predicate soft deletes over in-memory users, matching
+// only on the active field
+type softDeleteRepo struct {
+	persistence.IUserRepository
+	users   []*persistence.User
+	deleted map[primitive.ObjectID]bool
+	calls   int
+}
+
+func (r *softDeleteRepo) setDeleted(id identifier.IIdentifier, deleted bool) int64 {
+	r.calls++
+	active := id.ToBSON()["active"].(bool)
+	var count int64
+	for _, user := range r.users {
+		if user.Active == active && r.deleted[user.GetID()] != deleted {
+			r.deleted[user.GetID()] = deleted
+			count++
+		}
+	}
+	return count
+}
+
+func (r *softDeleteRepo) SoftDeleteMany(_ context.Context, id identifier.IIdentifier) (int64, error) {
+	return r.setDeleted(id, true), nil
+}
+
+func (r *softDeleteRepo) RestoreMany(_ context.Context, id identifier.IIdentifier) (int64, error) {
+	return r.setDeleted(id, false), nil
+}
+
+func TestUserService_SoftDeleteUsersByPredicate(t *testing.T) {
+	repo := &softDeleteRepo{deleted: map[primitive.ObjectID]bool{}}
+	for _, active := range []bool{true, false, false, true, false} {
+		repo.users = append(repo.users, &persistence.User{BaseEntity: domain.BaseEntity{ID: primitive.NewObjectID()}, Active: active})
+	}
+	service := services.NewUserService(repo)
+	ctx := context.Background()
+
+	deleted, err := service.SoftDeleteUsersByPredicate(ctx, identifier.New().Equal("active", false))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+	assert.Equal(t, 1, repo.calls, "one write for every matched user")
+
+	restored, err := service.RestoreUsersByPredicate(ctx, identifier.New().Equal("active", false))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), restored)
+	for _, user := range repo.users {
+		assert.False(t, repo.deleted[user.GetID()])
+	}
+}
+
+func TestUserService_PredicateRequired(t *testing.T) {
+	repo := &softDeleteRepo{deleted: map[primitive.ObjectID]bool{}}
+	service := services.NewUserService(repo)
+	ctx := context.Background()
+
+	_, err := service.SoftDeleteUsersByPredicate(ctx, identifier.New())
+	assert.Error(t, err)
+	_, err = service.SoftDeleteUsersByPredicate(ctx, nil)
+	assert.Error(t, err)
+	_, err = service.RestoreUsersByPredicate(ctx, identifier.New())
+	assert.Error(t, err)
+	assert.Zero(t, repo.calls)
+}
+
+func TestPredicateFilter(t *testing.T) {
+	_, err := predicateFilter(identifier.New())
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQuery)
+	_, err = predicateFilter(nil)
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQuery)
+
+	filter, err := predicateFilter(identifier.New().Equal("active", false))
+	require.NoError(t, err)
+	assert.Equal(t, bson.M{"active": false}, filter)
+}
+
+func TestUserService_SoftDeleteUsersByPredicate_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*persistence.User](t)
+	ctx := context.Background()
+
+	config := NewConfig()
+	config.Database = uow.database.Name()
+	factory, err := NewFactory[*persistence.User](config)
+	require.NoError(t, err)
+	defer factory.Close(ctx)
+	service := services.NewUserService(NewUserRepository(NewBaseRepository[*persistence.User](factory)))
+
+	_, err = service.CreateUsers(ctx, []*persistence.User{
+		{Email: "a@example.com", Age: 30, Active: true},
+		{Email: "b@example.com", Age: 40, Active: false},
+		{Email: "c@example.com", Age: 50, Active: false},
+	})
+	require.NoError(t, err)
+
+	inactive := identifier.New().Equal("active", false)
+	deleted, err := service.SoftDeleteUsersByPredicate(ctx, inactive)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	live, err := uow.FindAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, live, 1)
+
+	restored, err := service.RestoreUsersByPredicate(ctx, inactive)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), restored)
+
+	live, err = uow.FindAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, live, 3)
+}
+
+func TestUserRepository_FindActiveUsers_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*persistence.User](t)
+	ctx := context.Background()
+
+	config := NewConfig()
+	config.Database = uow.database.Name()
+	factory, err := NewFactory[*persistence.User](config)
+	require.NoError(t, err)
+	defer factory.Close(ctx)
+	users := NewUserRepository(NewBaseRepository[*persistence.User](factory))
+
+	// Freshly inserted documents have no deletedAt field at all
+	for _, user := range []*persistence.User{
+		{Email: "a@example.com", Age: 30, Active: true},
+		{Email: "b@example.com", Age: 40, Active: true},
+		{Email: "c@example.com", Age: 50, Active: false},
+	} {
+		_, err := users.Insert(ctx, user)
+		require.NoError(t, err)
+	}
+	_, err = users.SoftDelete(ctx, identifier.New().Equal("email", "b@example.com"))
+	require.NoError(t, err)
+
+	active, err := users.FindActiveUsers(ctx)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, "a@example.com", active[0].Email)
+
+	inRange, err := users.FindUsersByAgeRange(ctx, 25, 45)
+	require.NoError(t, err)
+	require.Len(t, inRange, 1)
+	assert.Equal(t, "a@example.com", inRange[0].Email)
+}
+
+func TestStatsDecoding(t *testing.T) {
+	doc, err := bson.Marshal(bson.M{"_id": nil, "totalUsers": int32(3), "activeUsers": int32(2), "averageAge": 30.5})
+	require.NoError(t, err)
+	var users persistence.UserStats
+	require.NoError(t, bson.Unmarshal(doc, &users))
+	assert.Equal(t, persistence.UserStats{TotalUsers: 3, ActiveUsers: 2, AverageAge: 30.5}, users)
+
+	doc, err = bson.Marshal(bson.M{"_id": nil, "totalProducts": int32(1), "averagePrice": nil})
+	require.NoError(t, err)
+	var products persistence.ProductStats
+	require.NoError(t, bson.Unmarshal(doc, &products), "$avg yields null when no document has the field")
+	assert.Equal(t, persistence.ProductStats{TotalProducts: 1}, products)
+}
+
+func TestRepositoryStats_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*persistence.User](t)
+	ctx := context.Background()
+
+	config := NewConfig()
+	config.Database = uow.database.Name()
+	userFactory, err := NewFactory[*persistence.User](config)
+	require.NoError(t, err)
+	defer userFactory.Close(ctx)
+	productFactory, err := NewFactory[*persistence.Product](config)
+	require.NoError(t, err)
+	defer productFactory.Close(ctx)
+
+	users := NewUserRepository(NewBaseRepository[*persistence.User](userFactory))
+	inserted, err := users.BulkInsert(ctx, []*persistence.User{
+		{Email: "a@example.com", Age: 20, Active: true},
+		{Email: "b@example.com", Age: 30, Active: false},
+		{Email: "c@example.com", Age: 40, Active: true},
+	})
+	require.NoError(t, err)
+	_, err = users.SoftDelete(ctx, identifier.New().Equal("_id", inserted[2].GetID()))
+	require.NoError(t, err)
+
+	userStats, err := users.GetUserStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), userStats.TotalUsers, "soft-deleted users are not counted")
+	assert.Equal(t, int64(1), userStats.ActiveUsers)
+
+	live, err := uow.FindAll(ctx)
+	require.NoError(t, err)
+	var totalAge int
+	for _, user := range live {
+		totalAge += user.Age
+	}
+	assert.InDelta(t, float64(totalAge)/float64(len(live)), userStats.AverageAge, 1e-9, "the server average matches Go")
+
+	products := NewProductRepository(NewBaseRepository[*persistence.Product](productFactory))
+	productStats, err := products.GetProductStats(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, *productStats, "an empty collection yields zero stats")
+
+	_, err = products.BulkInsert(ctx, []*persistence.Product{
+		{BaseEntity: domain.BaseEntity{Name: "Laptop"}, Category: "electronics", Price: 1000, InStock: true},
+		{BaseEntity: domain.BaseEntity{Name: "Desk"}, Category: "furniture", Price: 200, InStock: false},
+		{BaseEntity: domain.BaseEntity{Name: "Phone"}, Category: "electronics", Price: 600, InStock: true},
+	})
+	require.NoError(t, err)
+
+	productStats, err = products.GetProductStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), productStats.TotalProducts)
+	assert.Equal(t, int64(2), productStats.InStockProducts)
+	assert.Equal(t, 600.0, productStats.AveragePrice)
+	assert.Equal(t, []string{"electronics", "furniture"}, productStats.Categories, "categories are listed once each")
+}
+
+// existsRepo fakes the existence check and insert CreateUser makes
+type existsRepo struct {
+	persistence.IUserRepository
+	emails    map[string]bool
+	err       error
+	insertErr error
+	inserted  int
+}
+
+func (r *existsRepo) Exists(_ context.Context, id identifier.IIdentifier) (bool, error) {
+	email, _ := id.Get("email")
+	return r.emails[email.(string)], r.err
+}
+
+func (r *existsRepo) Insert(_ context.Context, user *persistence.User) (*persistence.User, error) {
+	if r.insertErr != nil {
+		return nil, r.insertErr
+	}
+	r.inserted++
+	return user, nil
+}
+
+func TestUserService_CreateUser_Duplicate(t *testing.T) {
+	repo := &existsRepo{emails: map[string]bool{"taken@example.com": true}}
+	service := services.NewUserService(repo)
+	ctx := context.Background()
+
+	_, err := service.CreateUser(ctx, "taken@example.com", 30)
+	assert.ErrorContains(t, err, "already exists")
+
+	user, err := service.CreateUser(ctx, "free@example.com", 30)
+	require.NoError(t, err)
+	assert.Equal(t, "free@example.com", user.Email)
+	assert.Equal(t, 1, repo.inserted)
+
+	repo.err = uowerrors.ErrDatabaseConnection
+	_, err = service.CreateUser(ctx, "other@example.com", 30)
+	assert.ErrorIs(t, err, uowerrors.ErrDatabaseConnection, "a failed check must not be treated as free")
+	assert.Equal(t, 1, repo.inserted)
+}
+
+func TestUserService_CreateUser_DuplicateKey(t *testing.T) {
+	repo := &existsRepo{insertErr: fmt.Errorf("failed to insert: %w", duplicateKey(mongo.WriteException{
+		WriteErrors: mongo.WriteErrors{{Code: 11000, Message: "E11000 duplicate key error"}},
+	}))}
+	service := services.NewUserService(repo)
+
+	_, err := service.CreateUser(context.Background(), "raced@example.com", 30)
+	assert.ErrorIs(t, err, uowerrors.ErrEntityExists)
+	assert.ErrorContains(t, err, "raced@example.com already exists")
+}
+
+// racingRepo skips the existence check, as a concurrent registration would
+// slip past it
+type racingRepo struct {
+	persistence.IUserRepository
+}
+
+func (racingRepo) Exists(context.Context, identifier.IIdentifier) (bool, error) {
+	return false, nil
+}
+
+func TestUserService_UniqueEmailIndex_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*persistence.User](t)
+	ctx := context.Background()
+
+	config := NewConfig()
+	config.Database = uow.database.Name()
+	factory, err := NewFactory[*persistence.User](config)
+	require.NoError(t, err)
+	defer factory.Close(ctx)
+
+	service := services.NewUserService(racingRepo{NewUserRepository(NewBaseRepository[*persistence.User](factory))})
+	require.NoError(t, service.EnsureIndexes(ctx))
+
+	first, err := service.CreateUser(ctx, "unique@example.com", 30)
+	require.NoError(t, err)
+
+	_, err = service.CreateUser(ctx, "unique@example.com", 31)
+	assert.ErrorIs(t, err, uowerrors.ErrEntityExists)
+
+	_, err = service.SoftDeleteUsersByPredicate(ctx, identifier.New().Equal("_id", first.GetID()))
+	require.NoError(t, err)
+	_, err = service.CreateUser(ctx, "unique@example.com", 32)
+	assert.NoError(t, err, "soft-deleted users do not hold their email")
+}
+
+func TestBaseRepository_FindAllMatching_RequiresIdentifiers(t *testing.T) {
+	factory, err := NewFactory[*persistence.User](unreachableConfig())
+	require.NoError(t, err)
+	repo := NewBaseRepository[*persistence.User](factory)
+	ctx := context.Background()
+
+	_, err = repo.FindAllMatchingAny(ctx)
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQuery)
+	_, err = repo.FindAllMatchingAll(ctx, nil, nil)
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQuery)
+	assert.Nil(t, factory.client, "invalid queries never connect")
+}
+
+func TestBaseRepository_FindAllMatching_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*persistence.User](t)
+	ctx := context.Background()
+
+	config := NewConfig()
+	config.Database = uow.database.Name()
+	factory, err := NewFactory[*persistence.User](config)
+	require.NoError(t, err)
+	defer factory.Close(ctx)
+	repo := NewBaseRepository[*persistence.User](factory)
+
+	inserted, err := repo.BulkInsert(ctx, []*persistence.User{
+		{Email: "a@example.com", Age: 20, Active: true},
+		{Email: "b@example.com", Age: 35, Active: false},
+		{Email: "c@example.com", Age: 50, Active: true},
+		{Email: "d@example.com", Age: 65, Active: false},
+	})
+	require.NoError(t, err)
+	_, err = repo.SoftDelete(ctx, identifier.New().Equal("_id", inserted[3].GetID()))
+	require.NoError(t, err)
+
+	emails := func(users []*persistence.User) []string {
+		result := make([]string, len(users))
+		for i, user := range users {
+			result[i] = user.Email
+		}
+		return result
+	}
+
+	young := identifier.New().LessThan("age", 30)
+	old := identifier.New().GreaterThan("age", 40)
+	active := identifier.New().Equal("active", true)
+
+	either, err := repo.FindAllMatchingAny(ctx, young, old)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a@example.com", "c@example.com"}, emails(either), "soft-deleted users are excluded")
+
+	both, err := repo.FindAllMatchingAll(ctx, old, active)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c@example.com"}, emails(both))
+
+	both, err = repo.FindAllMatchingAll(ctx, young, old)
+	require.NoError(t, err)
+	assert.Empty(t, both)
+
+	either, err = repo.FindAllMatchingAny(ctx, identifier.New().Equal("email", "b@example.com"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b@example.com"}, emails(either), "nil fragments are ignored")
+}
+
+func TestBaseRepository_FindAllSatisfying_RequiresSpec(t *testing.T) {
+	factory, err := NewFactory[*persistence.User](unreachableConfig())
+	require.NoError(t, err)
+	repo := NewBaseRepository[*persistence.User](factory)
+
+	_, err = repo.FindAllSatisfying(context.Background(), nil)
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQuery)
+	_, err = repo.FindAllSatisfying(context.Background(), identifier.And())
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQuery)
+	assert.Nil(t, factory.client, "invalid queries never connect")
+}
+
+func TestBaseRepository_FindAllSatisfying_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*persistence.User](t)
+	ctx := context.Background()
+
+	config := NewConfig()
+	config.Database = uow.database.Name()
+	factory, err := NewFactory[*persistence.User](config)
+	require.NoError(t, err)
+	defer factory.Close(ctx)
+	repo := NewBaseRepository[*persistence.User](factory)
+
+	_, err = repo.BulkInsert(ctx, []*persistence.User{
+		{Email: "young-active@example.com", Age: 20, Active: true},
+		{Email: "senior-active@example.com", Age: 70, Active: true},
+		{Email: "senior-inactive@example.com", Age: 75, Active: false},
+	})
+	require.NoError(t, err)
+
+	seniors := identifier.SpecFunc(func() identifier.IIdentifier { return identifier.New().GreaterThan("age", 65) })
+	active := identifier.SpecFunc(identifier.Active)
+
+	users, err := repo.FindAllSatisfying(ctx, identifier.And(active, identifier.Not(seniors)))
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "young-active@example.com", users[0].Email)
+
+	users, err = repo.FindAllSatisfying(ctx, identifier.Or(seniors, identifier.Not(active)))
+	require.NoError(t, err)
+	assert.Len(t, users, 2)
+}
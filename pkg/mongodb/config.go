@@ -1,8 +1,19 @@
 package mongodb
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
 	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 )
 
 type Config struct {
@@ -18,8 +29,50 @@ type Config struct {
 	Timeout     time.Duration
 	SSL         bool
 	ReplicaSet  string
+	EnableStats bool
+	// ServerSelectionTimeout bounds how long an operation waits for a
+	// suitable server. NewConfig sets 5s; zero leaves the driver default
+	// of 30s.
+	ServerSelectionTimeout time.Duration
+	// ConnectTimeout bounds establishing a single connection. NewConfig sets
+	// 10s; zero leaves the driver default of 30s.
+	ConnectTimeout time.Duration
+	// MaxCommitTime bounds how long a commitTransaction may run; zero leaves
+	// the server default
+	MaxCommitTime time.Duration
+	// BulkBatchSize is the number of items bulk operations write per round
+	// trip when their options leave it unset; zero or less uses 1000
+	BulkBatchSize int
+	// SRV resolves Host through DNS seed list discovery (mongodb+srv://); Port
+	// must be left zero
+	SRV bool
+	// Hosts lists every seed as host:port for multi-host deployments and
+	// replaces Host and Port in the connection string when set
+	Hosts []string
+	// TLSCAFile is a PEM file of certificate authorities trusted to sign the
+	// server certificate; empty trusts the system roots. Like the other TLS
+	// fields it only applies when SSL is set.
+	TLSCAFile string
+	// TLSCertificateKeyFile is a PEM file holding the client certificate and
+	// its private key for x509 or mutual TLS
+	TLSCertificateKeyFile string
+	// TLSInsecure skips verification of the server certificate
+	TLSInsecure bool
+	// ReadPreference is a read preference mode such as "primary" or
+	// "secondaryPreferred"; empty leaves the driver default
+	ReadPreference string
+	// ReadConcern is a read concern level such as "local" or "majority"; empty
+	// leaves the server default
+	ReadConcern string
+	// WriteConcern is the w value: "majority", a node count such as "1", or a
+	// replica set tag; empty leaves the server default
+	WriteConcern string
 }
 
+// defaultBulkBatchSize keeps bulk batches well under the server's 100,000
+// operation and 48MB message limits for typical documents
+const defaultBulkBatchSize = 1000
+
 func NewConfig() *Config {
 	return &Config{
 		Host:        "localhost",
@@ -31,17 +84,33 @@ func NewConfig() *Config {
 		MaxIdleTime: 30 * time.Second,
 		Timeout:     10 * time.Second,
 		SSL:         false,
+
+		ServerSelectionTimeout: 5 * time.Second,
+		ConnectTimeout:         10 * time.Second,
+
+		BulkBatchSize: defaultBulkBatchSize,
 	}
 }
 
 func (c *Config) ConnectionString() string {
 	uri := "mongodb://"
+	if c.SRV {
+		uri = "mongodb+srv://"
+	}
 
 	if c.Username != "" && c.Password != "" {
-		uri += fmt.Sprintf("%s:%s@", c.Username, c.Password)
+		uri += url.UserPassword(c.Username, c.Password).String() + "@"
 	}
 
-	uri += fmt.Sprintf("%s:%d/%s", c.Host, c.Port, c.Database)
+	switch {
+	case len(c.Hosts) > 0:
+		uri += strings.Join(c.Hosts, ",")
+	case c.SRV:
+		uri += c.Host
+	default:
+		uri += fmt.Sprintf("%s:%d", c.Host, c.Port)
+	}
+	uri += "/" + c.Database
 
 	params := make([]string, 0)
 
@@ -65,6 +134,31 @@ func (c *Config) ConnectionString() string {
 		params = append(params, fmt.Sprintf("replicaSet=%s", c.ReplicaSet))
 	}
 
+	// The driver enables TLS for any of these, so they only follow SSL
+	if c.SSL && c.TLSCAFile != "" {
+		params = append(params, fmt.Sprintf("tlsCAFile=%s", url.QueryEscape(c.TLSCAFile)))
+	}
+
+	if c.SSL && c.TLSCertificateKeyFile != "" {
+		params = append(params, fmt.Sprintf("tlsCertificateKeyFile=%s", url.QueryEscape(c.TLSCertificateKeyFile)))
+	}
+
+	if c.SSL && c.TLSInsecure {
+		params = append(params, "tlsInsecure=true")
+	}
+
+	if c.ReadPreference != "" {
+		params = append(params, fmt.Sprintf("readPreference=%s", c.ReadPreference))
+	}
+
+	if c.ReadConcern != "" {
+		params = append(params, fmt.Sprintf("readConcernLevel=%s", c.ReadConcern))
+	}
+
+	if c.WriteConcern != "" {
+		params = append(params, fmt.Sprintf("w=%s", url.QueryEscape(c.WriteConcern)))
+	}
+
 	if len(params) > 0 {
 		uri += "?"
 		for i, param := range params {
@@ -78,12 +172,155 @@ func (c *Config) ConnectionString() string {
 	return uri
 }
 
+// ParseConfig builds a Config from a mongodb:// or mongodb+srv:// URI. Options
+// the URI leaves out keep their NewConfig defaults; query parameters other
+// than authSource, replicaSet, ssl, tls, tlsCAFile, tlsCertificateKeyFile,
+// tlsInsecure, maxPoolSize, minPoolSize, readPreference, readConcernLevel and
+// w are ignored. SRV records are not
+// resolved here.
+func ParseConfig(uri string) (*Config, error) {
+	config := NewConfig()
+
+	rest, ok := strings.CutPrefix(uri, "mongodb://")
+	if !ok {
+		if rest, ok = strings.CutPrefix(uri, "mongodb+srv://"); !ok {
+			return nil, fmt.Errorf("connection URI must start with mongodb:// or mongodb+srv://")
+		}
+		config.SRV = true
+		config.Port = 0
+	}
+
+	rest, query, _ := strings.Cut(rest, "?")
+	authority, database, _ := strings.Cut(rest, "/")
+
+	if at := strings.LastIndex(authority, "@"); at >= 0 {
+		username, password, _ := strings.Cut(authority[:at], ":")
+		var err error
+		if config.Username, err = url.PathUnescape(username); err != nil {
+			return nil, fmt.Errorf("invalid username: %w", err)
+		}
+		if config.Password, err = url.PathUnescape(password); err != nil {
+			return nil, fmt.Errorf("invalid password: %w", err)
+		}
+		authority = authority[at+1:]
+	}
+
+	if err := config.parseHosts(authority); err != nil {
+		return nil, err
+	}
+
+	if database != "" {
+		name, err := url.PathUnescape(database)
+		if err != nil {
+			return nil, fmt.Errorf("invalid database name: %w", err)
+		}
+		config.Database = name
+	}
+
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection options: %w", err)
+	}
+	if err := config.parseOptions(params); err != nil {
+		return nil, err
+	}
+
+	return config, config.Validate()
+}
+
+// parseHosts fills Host and Port from the first seed and keeps the full seed
+// list in Hosts when there is more than one
+func (c *Config) parseHosts(authority string) error {
+	if authority == "" {
+		return fmt.Errorf("connection URI must name at least one host")
+	}
+
+	seeds := strings.Split(authority, ",")
+
+	for i, seed := range seeds {
+		host, port := seed, c.Port
+		if h, p, err := net.SplitHostPort(seed); err == nil {
+			n, err := strconv.Atoi(p)
+			if err != nil {
+				return fmt.Errorf("invalid port in host %q", seed)
+			}
+			host, port = h, n
+		}
+		if host == "" {
+			return fmt.Errorf("host cannot be empty")
+		}
+		if i == 0 {
+			c.Host, c.Port = host, port
+		}
+		seeds[i] = net.JoinHostPort(host, strconv.Itoa(port))
+	}
+
+	if len(seeds) > 1 {
+		c.Hosts = seeds
+	}
+	return nil
+}
+
+// parseOptions applies the supported URI query parameters. As in the driver,
+// the TLS file and insecure options turn on SSL unless ssl or tls says
+// otherwise.
+func (c *Config) parseOptions(params url.Values) error {
+	explicitSSL, impliedSSL := false, false
+	for key, values := range params {
+		value := values[len(values)-1]
+
+		var err error
+		switch strings.ToLower(key) {
+		case "authsource":
+			c.AuthSource = value
+		case "replicaset":
+			c.ReplicaSet = value
+		case "ssl", "tls":
+			c.SSL, err = strconv.ParseBool(value)
+			explicitSSL = true
+		case "maxpoolsize":
+			c.MaxPoolSize, err = strconv.ParseUint(value, 10, 64)
+		case "minpoolsize":
+			c.MinPoolSize, err = strconv.ParseUint(value, 10, 64)
+		case "tlscafile":
+			c.TLSCAFile = value
+			impliedSSL = true
+		case "tlscertificatekeyfile":
+			c.TLSCertificateKeyFile = value
+			impliedSSL = true
+		case "tlsinsecure":
+			c.TLSInsecure, err = strconv.ParseBool(value)
+			impliedSSL = true
+		case "readpreference":
+			c.ReadPreference = value
+		case "readconcernlevel":
+			c.ReadConcern = value
+		case "w":
+			c.WriteConcern = value
+		}
+		if err != nil {
+			return fmt.Errorf("invalid %s option %q: %w", key, value, err)
+		}
+	}
+	if impliedSSL && !explicitSSL {
+		c.SSL = true
+	}
+	return nil
+}
+
 func (c *Config) Validate() error {
 	if c.Host == "" {
 		return fmt.Errorf("host cannot be empty")
 	}
 
-	if c.Port <= 0 || c.Port > 65535 {
+	if c.SRV {
+		if c.Port != 0 {
+			return fmt.Errorf("port must not be set for mongodb+srv connections")
+		}
+		if len(c.Hosts) > 0 {
+			return fmt.Errorf("mongodb+srv connections take a single host")
+		}
+	} else if c.Port <= 0 || c.Port > 65535 {
 		return fmt.Errorf("port must be between 1 and 65535")
 	}
 
@@ -91,5 +328,112 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("database name cannot be empty")
 	}
 
+	if c.SSL {
+		for _, file := range []string{c.TLSCAFile, c.TLSCertificateKeyFile} {
+			if file == "" {
+				continue
+			}
+			if _, err := os.Stat(file); err != nil {
+				return fmt.Errorf("TLS file: %w", err)
+			}
+		}
+	}
+
+	if _, err := c.readPreference(); err != nil {
+		return err
+	}
+
+	if _, err := c.readConcern(); err != nil {
+		return err
+	}
+
+	if _, err := c.writeConcern(); err != nil {
+		return err
+	}
+
 	return nil
 }
+
+// tlsConfig loads the configured CA and client certificate files, or returns
+// nil when SSL is disabled
+func (c *Config) tlsConfig() (*tls.Config, error) {
+	if !c.SSL {
+		return nil, nil
+	}
+
+	config := &tls.Config{InsecureSkipVerify: c.TLSInsecure}
+
+	if c.TLSCAFile != "" {
+		pem, err := os.ReadFile(c.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS CA file: %w", err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in TLS CA file %s", c.TLSCAFile)
+		}
+		config.RootCAs = roots
+	}
+
+	if c.TLSCertificateKeyFile != "" {
+		pem, err := os.ReadFile(c.TLSCertificateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS certificate key file: %w", err)
+		}
+		certificate, err := tls.X509KeyPair(pem, pem)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS certificate key file: %w", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	return config, nil
+}
+
+// readPreference returns the configured read preference, or nil when unset
+func (c *Config) readPreference() (*readpref.ReadPref, error) {
+	if c.ReadPreference == "" {
+		return nil, nil
+	}
+	mode, err := readpref.ModeFromString(c.ReadPreference)
+	if err != nil {
+		return nil, err
+	}
+	return readpref.New(mode)
+}
+
+// readConcern returns the configured read concern, or nil when unset
+func (c *Config) readConcern() (*readconcern.ReadConcern, error) {
+	switch strings.ToLower(c.ReadConcern) {
+	case "":
+		return nil, nil
+	case "local":
+		return readconcern.Local(), nil
+	case "available":
+		return readconcern.Available(), nil
+	case "majority":
+		return readconcern.Majority(), nil
+	case "linearizable":
+		return readconcern.Linearizable(), nil
+	case "snapshot":
+		return readconcern.Snapshot(), nil
+	}
+	return nil, fmt.Errorf("unknown read concern %s", c.ReadConcern)
+}
+
+// writeConcern returns the configured write concern, or nil when unset
+func (c *Config) writeConcern() (*writeconcern.WriteConcern, error) {
+	switch {
+	case c.WriteConcern == "":
+		return nil, nil
+	case c.WriteConcern == "majority":
+		return writeconcern.Majority(), nil
+	}
+	if w, err := strconv.Atoi(c.WriteConcern); err == nil {
+		if w < 0 {
+			return nil, fmt.Errorf("write concern must not be negative")
+		}
+		return &writeconcern.WriteConcern{W: w}, nil
+	}
+	return writeconcern.Custom(c.WriteConcern), nil
+}
@@ -0,0 +1,162 @@
+package mongodb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+)
+
+const contentHashField = "contentHash"
+
+// unhashedFields are bookkeeping fields that never count as content
+var unhashedFields = map[string]bool{
+	"_id":            true,
+	"slug":           true,
+	"createdAt":      true,
+	"updatedAt":      true,
+	"deletedAt":      true,
+	contentHashField: true,
+}
+
+// contentHash returns a hex SHA-256 of the entity's significant fields,
+// encoded with the client registry. Keys of embedded documents are sorted
+// first, so the hash does not depend on map iteration order.
+func (uow *UnitOfWork[T]) contentHash(entity T) (string, error) {
+	raw, err := uow.marshalPlain(entity)
+	if err != nil {
+		return "", err
+	}
+
+	canonical, err := canonicalDocument(raw, unhashedFields)
+	if err != nil {
+		return "", err
+	}
+	data, err := bson.Marshal(canonical)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func canonicalDocument(doc bson.Raw, skip map[string]bool) (bson.D, error) {
+	elements, err := doc.Elements()
+	if err != nil {
+		return nil, err
+	}
+
+	canonical := bson.D{}
+	for _, element := range elements {
+		if skip[element.Key()] {
+			continue
+		}
+		value, err := canonicalValue(element.Value())
+		if err != nil {
+			return nil, err
+		}
+		canonical = append(canonical, bson.E{Key: element.Key(), Value: value})
+	}
+
+	sort.Slice(canonical, func(i, j int) bool { return canonical[i].Key < canonical[j].Key })
+	return canonical, nil
+}
+
+func canonicalValue(value bson.RawValue) (interface{}, error) {
+	switch value.Type {
+	case bsontype.EmbeddedDocument:
+		return canonicalDocument(value.Document(), nil)
+	case bsontype.Array:
+		values, err := value.Array().Values()
+		if err != nil {
+			return nil, err
+		}
+		canonical := make(bson.A, len(values))
+		for i, item := range values {
+			if canonical[i], err = canonicalValue(item); err != nil {
+				return nil, err
+			}
+		}
+		return canonical, nil
+	default:
+		return value, nil
+	}
+}
+
+// stampContentHash refreshes the stored hash of entities implementing
+// domain.ContentHashed and returns it; other entities are left untouched
+func (uow *UnitOfWork[T]) stampContentHash(entity T) (string, error) {
+	hashed, ok := any(entity).(domain.ContentHashed)
+	if !ok {
+		return "", nil
+	}
+
+	hash, err := uow.contentHash(entity)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash content: %w", err)
+	}
+	hashed.SetContentHash(hash)
+	return hash, nil
+}
+
+// UpdateIfChanged writes entity only when its content hash differs from the
+// stored one, reporting whether it did. Unchanged entities are returned as
+// stored. The entity must implement domain.ContentHashed and be complete,
+// since the hash covers every significant field.
+func (uow *UnitOfWork[T]) UpdateIfChanged(ctx context.Context, identifier identifier.IIdentifier, entity T) (T, bool, error) {
+	var zero T
+	if _, ok := any(entity).(domain.ContentHashed); !ok {
+		return zero, false, fmt.Errorf("%w: %T does not implement domain.ContentHashed", uowerrors.ErrInvalidEntity, entity)
+	}
+	if err := uow.appendOnly("update"); err != nil {
+		return zero, false, err
+	}
+
+	normalizeFields(entity)
+	hash, err := uow.stampContentHash(entity)
+	if err != nil {
+		return zero, false, err
+	}
+
+	filter := uow.excludeDeleted(identifier.ToBSON())
+	filter[contentHashField] = bson.M{"$ne": hash}
+
+	uow.setEntityTimestamp(entity, "updatedAt", utcNow())
+
+	uow.track(opUpdate)
+	result := uow.getCollection().FindOneAndUpdate(
+		uow.getContext(ctx),
+		filter,
+		bson.M{"$set": entity},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+
+	var updated T
+	err = uow.decode(result, &updated)
+	if err == nil {
+		return updated, true, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return zero, false, fmt.Errorf("failed to update: %w", err)
+	}
+
+	current, found, err := uow.tryFindOne(ctx, uow.excludeDeleted(identifier.ToBSON()))
+	if err != nil {
+		return zero, false, err
+	}
+	if !found {
+		return zero, false, fmt.Errorf("entity not found")
+	}
+	return current, false, nil
+}
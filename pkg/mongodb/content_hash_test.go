@@ -0,0 +1,106 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+)
+
+type Article struct {
+	domain.BaseEntity `bson:",inline"`
+	Title             string `bson:"title" json:"title"`
+	Body              string `bson:"body" json:"body"`
+	Meta              bson.M `bson:"meta,omitempty" json:"meta,omitempty"`
+	ContentHash       string `bson:"contentHash,omitempty" json:"contentHash,omitempty"`
+}
+
+func (a *Article) GetContentHash() string     { return a.ContentHash }
+func (a *Article) SetContentHash(hash string) { a.ContentHash = hash }
+
+func TestContentHash(t *testing.T) {
+	uow := newOfflineUnitOfWork[*Article](t, nil)
+	article := &Article{Title: "Go", Body: "generics", Meta: bson.M{"a": 1, "b": 2, "c": 3}}
+	hash, err := uow.contentHash(article)
+	require.NoError(t, err)
+	assert.Len(t, hash, 64)
+
+	bookkeeping := *article
+	bookkeeping.CreatedAt = time.Now()
+	bookkeeping.UpdatedAt = time.Now()
+	bookkeeping.Slug = "go"
+	bookkeeping.ContentHash = hash
+	same, err := uow.contentHash(&bookkeeping)
+	require.NoError(t, err)
+	assert.Equal(t, hash, same, "timestamps, slug and the hash itself are excluded")
+
+	for i := 0; i < 10; i++ {
+		reordered, err := uow.contentHash(&Article{Title: "Go", Body: "generics", Meta: bson.M{"c": 3, "b": 2, "a": 1}})
+		require.NoError(t, err)
+		assert.Equal(t, hash, reordered)
+	}
+
+	changed, err := uow.contentHash(&Article{Title: "Go", Body: "generics!", Meta: article.Meta})
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, changed)
+}
+
+func TestContentHash_UsesRegistry(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestInvoice](t, nil)
+	uow.registry = newMoneyRegistry()
+
+	small, err := uow.contentHash(&TestInvoice{Total: Money{cents: 1234}})
+	require.NoError(t, err)
+	large, err := uow.contentHash(&TestInvoice{Total: Money{cents: 9999}})
+	require.NoError(t, err)
+	assert.NotEqual(t, small, large, "codec-backed fields count as content")
+}
+
+func TestStampContentHash(t *testing.T) {
+	article := &Article{Title: "Go"}
+	hash, err := newOfflineUnitOfWork[*Article](t, nil).stampContentHash(article)
+	require.NoError(t, err)
+	assert.Equal(t, hash, article.ContentHash)
+
+	hash, err = newOfflineUnitOfWork[*TestUser](t, nil).stampContentHash(&TestUser{Email: "a@example.com"})
+	require.NoError(t, err)
+	assert.Empty(t, hash, "entities without domain.ContentHashed are not hashed")
+}
+
+func TestUnitOfWork_UpdateIfChanged_RequiresContentHashed(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+
+	_, _, err := uow.UpdateIfChanged(context.Background(), identifier.New().Equal("email", "a@example.com"), &TestUser{})
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidEntity)
+}
+
+func TestUnitOfWork_UpdateIfChanged_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*Article](t)
+	ctx := context.Background()
+
+	created, err := uow.Insert(ctx, &Article{Title: "Go", Body: "generics"})
+	require.NoError(t, err)
+	require.NotEmpty(t, created.ContentHash)
+	byID := identifier.New().Equal("_id", created.GetID())
+
+	stored, changed, err := uow.UpdateIfChanged(ctx, byID, &Article{Title: "Go", Body: "generics"})
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, created.UpdatedAt.Truncate(time.Millisecond), stored.UpdatedAt.Truncate(time.Millisecond))
+
+	updated, changed, err := uow.UpdateIfChanged(ctx, byID, &Article{Title: "Go", Body: "iterators"})
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "iterators", updated.Body)
+	assert.NotEqual(t, created.ContentHash, updated.ContentHash)
+
+	_, _, err = uow.UpdateIfChanged(ctx, identifier.New().Equal("title", "missing"), &Article{Title: "missing"})
+	assert.Error(t, err)
+}
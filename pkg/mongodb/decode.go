@@ -0,0 +1,158 @@
+package mongodb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+)
+
+// WithStrictDecoding makes unit of work instances reject documents carrying
+// fields that the entity type does not declare, instead of silently decoding
+// them into zero values. This catches reading one type's collection as another.
+func WithStrictDecoding() FactoryOption {
+	return func(s *factorySettings) {
+		s.strictDecoding = true
+	}
+}
+
+func (uow *UnitOfWork[T]) decode(result *mongo.SingleResult, out *T) error {
+	if !uow.strictDecoding {
+		return result.Decode(out)
+	}
+
+	raw, err := result.Raw()
+	if err != nil {
+		return err
+	}
+	if err := checkDocument(raw, documentFieldsFor[T]()); err != nil {
+		return err
+	}
+	return result.Decode(out)
+}
+
+func (uow *UnitOfWork[T]) decodeAll(ctx context.Context, cursor *mongo.Cursor, out *[]T) error {
+	if !uow.strictDecoding {
+		return cursor.All(ctx, out)
+	}
+
+	return uow.decodeEach(ctx, cursor, func(item T) {
+		*out = append(*out, item)
+	})
+}
+
+// unmarshal decodes a raw document read from the database into out with the
+// client registry, so custom codecs and field decryption apply as they do to
+// cursor results
+func (uow *UnitOfWork[T]) unmarshal(doc bson.Raw, out *T) error {
+	if uow.registry == nil {
+		return bson.Unmarshal(doc, out)
+	}
+
+	decoder, err := bson.NewDecoder(bsonrw.NewBSONDocumentReader(doc))
+	if err != nil {
+		return err
+	}
+	if err := decoder.SetRegistry(uow.registry); err != nil {
+		return err
+	}
+	return decoder.Decode(out)
+}
+
+// marshalPlain encodes entity with the client registry, so custom codecs apply
+// as they do on writes, leaving encrypted fields in plaintext
+func (uow *UnitOfWork[T]) marshalPlain(entity T) (bson.Raw, error) {
+	if uow.registry == nil {
+		return bson.Marshal(entity)
+	}
+
+	var buf bytes.Buffer
+	writer, err := bsonrw.NewBSONValueWriter(&buf)
+	if err != nil {
+		return nil, err
+	}
+	encoder, err := bson.NewEncoder(writer)
+	if err != nil {
+		return nil, err
+	}
+	if err := encoder.SetRegistry(uow.registry); err != nil {
+		return nil, err
+	}
+	if err := encoder.Encode(entity); err != nil {
+		return nil, err
+	}
+
+	if uow.encryption != nil {
+		return uow.encryption.decryptDocument(buf.Bytes())
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeEach decodes the cursor one document at a time, handing each entity to
+// fn without collecting them
+func (uow *UnitOfWork[T]) decodeEach(ctx context.Context, cursor *mongo.Cursor, fn func(T)) error {
+	var fields documentFields
+	if uow.strictDecoding {
+		fields = documentFieldsFor[T]()
+	}
+
+	for cursor.Next(ctx) {
+		if uow.strictDecoding {
+			if err := checkDocument(cursor.Current, fields); err != nil {
+				return err
+			}
+		}
+
+		var item T
+		if err := cursor.Decode(&item); err != nil {
+			return err
+		}
+		fn(item)
+	}
+	return cursor.Err()
+}
+
+// documentFields lists the top-level keys an entity type can decode
+type documentFields struct {
+	typeName string
+	known    map[string]bool
+}
+
+func documentFieldsFor[T any]() documentFields {
+	var zero T
+	t := reflect.TypeOf(zero)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	known := make(map[string]bool)
+	for _, field := range filterFieldsFor(t) {
+		known[field.name] = true
+		known[strings.ToLower(field.name)] = true
+	}
+
+	return documentFields{typeName: t.Name(), known: known}
+}
+
+// checkDocument returns ErrTypeMismatch when doc has a top-level field that
+// the entity type does not declare
+func checkDocument(doc bson.Raw, fields documentFields) error {
+	elements, err := doc.Elements()
+	if err != nil {
+		return err
+	}
+
+	for _, element := range elements {
+		if key := element.Key(); !fields.known[key] {
+			return fmt.Errorf("%w: field %q is not defined on %s", uowerrors.ErrTypeMismatch, key, fields.typeName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+)
+
+func TestCheckDocument(t *testing.T) {
+	fields := documentFieldsFor[*TestUser]()
+
+	user, err := bson.Marshal(bson.M{"_id": primitive.NewObjectID(), "name": "alice", "email": "a@example.com", "age": 30})
+	require.NoError(t, err)
+	assert.NoError(t, checkDocument(user, fields))
+
+	product, err := bson.Marshal(bson.M{"_id": primitive.NewObjectID(), "name": "laptop", "price": 999.99, "category": "electronics"})
+	require.NoError(t, err)
+
+	err = checkDocument(product, fields)
+	assert.ErrorIs(t, err, uowerrors.ErrTypeMismatch)
+	assert.Contains(t, err.Error(), "TestUser")
+}
+
+func TestUnitOfWork_StrictDecoding_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	productID := primitive.NewObjectID()
+	_, err := uow.getCollection().InsertOne(ctx, bson.M{"_id": productID, "name": "laptop", "price": 999.99, "category": "electronics"})
+	require.NoError(t, err)
+
+	// Without the guard the product decodes into a user with zero-valued fields
+	user, err := uow.FindOneById(ctx, productID)
+	require.NoError(t, err)
+	assert.Empty(t, user.Email)
+
+	uow.strictDecoding = true
+
+	_, err = uow.FindOneById(ctx, productID)
+	assert.ErrorIs(t, err, uowerrors.ErrTypeMismatch)
+
+	_, err = uow.FindAll(ctx)
+	assert.ErrorIs(t, err, uowerrors.ErrTypeMismatch)
+}
+
+func TestFactory_WithStrictDecoding(t *testing.T) {
+	factory, err := NewFactory[*TestUser](NewConfig(), WithStrictDecoding())
+	require.NoError(t, err)
+
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+	factory.apply(uow)
+
+	assert.True(t, uow.strictDecoding)
+}
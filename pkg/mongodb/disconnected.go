@@ -0,0 +1,364 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+)
+
+// disconnectedUnitOfWork is what Factory.Create returns when MongoDB cannot be
+// reached, so callers get an error from their first operation instead of a
+// panic. Every operation returns the connection error, which matches
+// ErrNotConnected; the next Create tries to connect again.
+type disconnectedUnitOfWork[T persistence.ModelConstraint] struct {
+	err            error
+	collectionName string
+}
+
+var _ persistence.IUnitOfWork[*persistence.User] = (*disconnectedUnitOfWork[*persistence.User])(nil)
+
+func newDisconnectedUnitOfWork[T persistence.ModelConstraint](err error) *disconnectedUnitOfWork[T] {
+	var zero T
+	return &disconnectedUnitOfWork[T]{
+		err:            notConnected(err),
+		collectionName: getCollectionName(zero),
+	}
+}
+
+// notConnected wraps err in ErrNotConnected unless it already matches it
+func notConnected(err error) error {
+	if errors.Is(err, uowerrors.ErrNotConnected) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", uowerrors.ErrNotConnected, err)
+}
+
+func (u *disconnectedUnitOfWork[T]) BeginTransaction(ctx context.Context) error {
+	return u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) CommitTransaction(ctx context.Context) error {
+	return u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) RollbackTransaction(ctx context.Context) {}
+
+func (u *disconnectedUnitOfWork[T]) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) FindAll(ctx context.Context) ([]T, error) {
+	return nil, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) FindAllWithTrashed(ctx context.Context) ([]T, error) {
+	return nil, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) FindAllWithPagination(ctx context.Context, query domain.QueryParams[T]) ([]T, uint, error) {
+	return nil, 0, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) FindAllWithOptions(ctx context.Context, query domain.QueryParams[T], opts domain.FindOptions) ([]T, uint, error) {
+	return nil, 0, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) Count(ctx context.Context, identifier identifier.IIdentifier) (int64, error) {
+	return 0, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) Exists(ctx context.Context, identifier identifier.IIdentifier) (bool, error) {
+	return false, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) Distinct(ctx context.Context, field string, filter identifier.IIdentifier) ([]interface{}, error) {
+	return nil, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) DistinctWithCounts(ctx context.Context, field string, filter identifier.IIdentifier) ([]persistence.ValueCount, error) {
+	return nil, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) Aggregate(ctx context.Context, pipeline mongo.Pipeline, result interface{}) error {
+	return u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) FindOne(ctx context.Context, filter T) (T, error) {
+	var zero T
+	return zero, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) FindOneById(ctx context.Context, id primitive.ObjectID) (T, error) {
+	var zero T
+	return zero, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) FindOneByIdWithTrashed(ctx context.Context, id primitive.ObjectID) (T, error) {
+	var zero T
+	return zero, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) FindByIds(ctx context.Context, ids []primitive.ObjectID) ([]T, error) {
+	return nil, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) FindByIdsOrdered(ctx context.Context, ids []primitive.ObjectID) ([]T, error) {
+	return nil, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) FindOneByHexId(ctx context.Context, hexID string) (T, error) {
+	var zero T
+	return zero, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) FindOneByIdentifier(ctx context.Context, identifier identifier.IIdentifier) (T, error) {
+	var zero T
+	return zero, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) TryFindOneById(ctx context.Context, id primitive.ObjectID) (T, bool, error) {
+	var zero T
+	return zero, false, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) TryFindOne(ctx context.Context, identifier identifier.IIdentifier) (T, bool, error) {
+	var zero T
+	return zero, false, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) FindAllWithCursor(ctx context.Context, query domain.KeysetParams[T]) (persistence.CursorPage[T], error) {
+	return persistence.CursorPage[T]{}, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) Export(ctx context.Context, query domain.KeysetParams[T]) (persistence.ExportBatch[T], error) {
+	return persistence.ExportBatch[T]{}, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) FindAllRaw(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]T, error) {
+	return nil, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) FindOneRaw(ctx context.Context, filter bson.M) (T, error) {
+	var zero T
+	return zero, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) ResolveIDByUniqueField(ctx context.Context, model domain.BaseModel, field string, value interface{}) (primitive.ObjectID, error) {
+	return primitive.NilObjectID, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) Insert(ctx context.Context, entity T) (T, error) {
+	var zero T
+	return zero, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) Update(ctx context.Context, identifier identifier.IIdentifier, entity T) (T, error) {
+	var zero T
+	return zero, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) UpdateReturningBefore(ctx context.Context, identifier identifier.IIdentifier, entity T) (T, error) {
+	var zero T
+	return zero, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) UpdateModified(ctx context.Context, identifier identifier.IIdentifier, entity T) (T, bool, error) {
+	var zero T
+	return zero, false, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) UpdateFields(ctx context.Context, identifier identifier.IIdentifier, fields bson.M) (T, error) {
+	var zero T
+	return zero, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) UpdateIf(ctx context.Context, identifier identifier.IIdentifier, condition identifier.IIdentifier, fields bson.M) (T, bool, error) {
+	var zero T
+	return zero, false, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) UpdateWithArrayFilters(ctx context.Context, identifier identifier.IIdentifier, update bson.M, arrayFilters []interface{}) (T, error) {
+	var zero T
+	return zero, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) RetryOnConflict(ctx context.Context, id primitive.ObjectID, mutate func(T) error, maxAttempts int) (T, error) {
+	var zero T
+	return zero, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) UpdateIfChanged(ctx context.Context, identifier identifier.IIdentifier, entity T) (T, bool, error) {
+	var zero T
+	return zero, false, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) UpdateMany(ctx context.Context, identifier identifier.IIdentifier, fields bson.M) (int64, error) {
+	return 0, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) Upsert(ctx context.Context, identifier identifier.IIdentifier, entity T) (T, error) {
+	var zero T
+	return zero, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) Delete(ctx context.Context, identifier identifier.IIdentifier) error {
+	return u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) DeleteAll(ctx context.Context, confirm bool) error {
+	return u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) SoftDelete(ctx context.Context, identifier identifier.IIdentifier) (T, error) {
+	var zero T
+	return zero, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) SoftDeleteReturningBefore(ctx context.Context, identifier identifier.IIdentifier) (T, error) {
+	var zero T
+	return zero, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) HardDelete(ctx context.Context, identifier identifier.IIdentifier) (T, error) {
+	var zero T
+	return zero, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) BulkInsert(ctx context.Context, entities []T) ([]T, error) {
+	return nil, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) BulkInsertWithOptions(ctx context.Context, entities []T, opts persistence.BulkOptions) ([]T, error) {
+	return nil, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) BulkUpdate(ctx context.Context, entities []T) (persistence.BulkResult, error) {
+	return persistence.BulkResult{}, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) BulkUpsert(ctx context.Context, entities []T) ([]T, error) {
+	return nil, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) BulkSoftDelete(ctx context.Context, identifiers []identifier.IIdentifier) (persistence.BulkResult, error) {
+	return persistence.BulkResult{}, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) BulkSoftDeleteWithOptions(ctx context.Context, identifiers []identifier.IIdentifier, opts persistence.BulkOptions) (persistence.BulkResult, error) {
+	return persistence.BulkResult{}, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) SoftDeleteMany(ctx context.Context, identifier identifier.IIdentifier) (int64, error) {
+	return 0, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) BulkHardDelete(ctx context.Context, identifiers []identifier.IIdentifier) (persistence.BulkResult, error) {
+	return persistence.BulkResult{}, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) GetTrashed(ctx context.Context) ([]T, error) {
+	return nil, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) GetTrashedWithPagination(ctx context.Context, query domain.QueryParams[T]) ([]T, uint, error) {
+	return nil, 0, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) GetTrashedInRange(ctx context.Context, start, end time.Time) ([]T, error) {
+	return nil, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) GetTrashedInRangeWithPagination(ctx context.Context, start, end time.Time, query domain.QueryParams[T]) ([]T, uint, error) {
+	return nil, 0, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) PurgeTrashedOlderThan(ctx context.Context, cutoff time.Time, dryRun bool) (int64, error) {
+	return 0, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) Restore(ctx context.Context, identifier identifier.IIdentifier) (T, error) {
+	var zero T
+	return zero, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) RestoreAll(ctx context.Context, confirm bool) error {
+	return u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) RestoreMany(ctx context.Context, identifier identifier.IIdentifier) (int64, error) {
+	return 0, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) BulkRestore(ctx context.Context, identifiers []identifier.IIdentifier) error {
+	return u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) BackfillTimestamps(ctx context.Context) (int64, error) {
+	return 0, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) RenameField(ctx context.Context, from, to string) (int64, error) {
+	return 0, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) MigrateDocuments(ctx context.Context, filter identifier.IIdentifier, transform func(bson.M) (bson.M, error), batchSize int) (int64, error) {
+	return 0, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) EnsureSlugIndex(ctx context.Context) error {
+	return u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) EnsureIndexes(ctx context.Context, specs ...persistence.IndexSpec) ([]string, error) {
+	return nil, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) EnsureIndexesAsync(ctx context.Context, specs ...persistence.IndexSpec) <-chan persistence.IndexBuildResult {
+	results := make(chan persistence.IndexBuildResult, 1)
+	results <- persistence.IndexBuildResult{Err: u.err}
+	close(results)
+	return results
+}
+
+func (u *disconnectedUnitOfWork[T]) CreateUniqueIndex(ctx context.Context, field string) error {
+	return u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) ForDatabase(name string) persistence.IUnitOfWork[T] {
+	return u
+}
+
+func (u *disconnectedUnitOfWork[T]) WithReadConcern(rc *readconcern.ReadConcern) persistence.IUnitOfWork[T] {
+	return u
+}
+
+func (u *disconnectedUnitOfWork[T]) WithReadPreference(rp *readpref.ReadPref) persistence.IUnitOfWork[T] {
+	return u
+}
+
+func (u *disconnectedUnitOfWork[T]) SnapshotContext(ctx context.Context) (context.Context, func(), error) {
+	return ctx, func() {}, u.err
+}
+
+func (u *disconnectedUnitOfWork[T]) CollectionName() string {
+	return u.collectionName
+}
+
+func (u *disconnectedUnitOfWork[T]) Stats() persistence.OperationStats {
+	return persistence.OperationStats{}
+}
+
+func (u *disconnectedUnitOfWork[T]) ResetStats() {}
@@ -0,0 +1,80 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+)
+
+func TestFactory_Create_Unreachable(t *testing.T) {
+	factory, err := NewFactory[*TestUser](unreachableConfig())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var uow persistence.IUnitOfWork[*TestUser]
+	require.NotPanics(t, func() {
+		uow = factory.CreateWithContext(ctx)
+	})
+	assert.Equal(t, "testusers", uow.CollectionName())
+
+	_, err = uow.Insert(ctx, &TestUser{Email: "a@example.com"})
+	assert.ErrorIs(t, err, uowerrors.ErrNotConnected)
+	assert.True(t, uowerrors.IsConnection(err))
+
+	_, err = uow.FindAll(ctx)
+	assert.ErrorIs(t, err, uowerrors.ErrNotConnected)
+	_, err = uow.FindOneById(ctx, primitive.NewObjectID())
+	assert.ErrorIs(t, err, uowerrors.ErrNotConnected)
+	_, _, err = uow.TryFindOneById(ctx, primitive.NewObjectID())
+	assert.ErrorIs(t, err, uowerrors.ErrNotConnected)
+
+	byEmail := identifier.New().Equal("email", "a@example.com")
+	assert.ErrorIs(t, uow.Delete(ctx, byEmail), uowerrors.ErrNotConnected)
+	_, err = uow.SoftDelete(ctx, byEmail)
+	assert.ErrorIs(t, err, uowerrors.ErrNotConnected)
+	_, err = uow.BulkSoftDelete(ctx, []identifier.IIdentifier{byEmail})
+	assert.ErrorIs(t, err, uowerrors.ErrNotConnected)
+
+	assert.ErrorIs(t, uow.BeginTransaction(ctx), uowerrors.ErrNotConnected)
+	assert.Same(t, uow, uow.ForDatabase("other"))
+	result := <-uow.EnsureIndexesAsync(ctx, ActiveIndex("email"))
+	assert.ErrorIs(t, result.Err, uowerrors.ErrNotConnected)
+
+	_, err = factory.CreateWithTransaction(ctx)
+	assert.ErrorIs(t, err, uowerrors.ErrNotConnected)
+}
+
+func TestBaseRepository_Unreachable(t *testing.T) {
+	factory, err := NewFactory[*persistence.User](unreachableConfig())
+	require.NoError(t, err)
+	repo := NewBaseRepository[*persistence.User](factory)
+	ctx := context.Background()
+
+	assert.NotPanics(t, func() {
+		_, err = repo.Insert(ctx, &persistence.User{Email: "a@example.com"})
+	})
+	assert.ErrorIs(t, err, uowerrors.ErrNotConnected)
+
+	_, err = repo.FindAll(ctx, nil)
+	assert.ErrorIs(t, err, uowerrors.ErrNotConnected)
+	assert.ErrorIs(t, repo.Delete(ctx, identifier.New().Equal("email", "a@example.com")), uowerrors.ErrNotConnected)
+}
+
+func TestNewUnitOfWork_Unreachable(t *testing.T) {
+	_, err := NewUnitOfWork[*TestUser](unreachableConfig())
+	assert.ErrorIs(t, err, uowerrors.ErrNotConnected)
+}
+
+func TestNotConnected(t *testing.T) {
+	err := notConnected(assert.AnError)
+	assert.ErrorIs(t, err, uowerrors.ErrNotConnected)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Same(t, err, notConnected(err), "errors already matching are not wrapped twice")
+}
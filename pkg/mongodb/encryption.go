@@ -0,0 +1,332 @@
+package mongodb
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Encryption modes accepted by the encrypt struct tag
+const (
+	// EncryptDeterministic always produces the same ciphertext for the same
+	// value, so equality queries on the field still work
+	EncryptDeterministic = "deterministic"
+	// EncryptRandom uses a fresh nonce for every write; the field can be read
+	// back but never queried
+	EncryptRandom = "random"
+)
+
+// encryptedSubtype is the user-defined binary subtype holding ciphertext.
+// MongoDB's own subtype 6 is avoided so drivers configured for automatic
+// encryption never try to decrypt these values.
+const encryptedSubtype byte = 0x80
+
+// encryptedVersion prefixes every ciphertext so the format can evolve
+const encryptedVersion byte = 1
+
+// KeyProvider supplies the master key that field encryption keys are derived
+// from
+type KeyProvider interface {
+	MasterKey() ([]byte, error)
+}
+
+// LocalKeyProvider serves a master key held by the application, for example
+// loaded from a secret store at startup
+type LocalKeyProvider struct {
+	Key []byte
+}
+
+// MasterKey returns the local key, which must be at least 32 bytes
+func (p LocalKeyProvider) MasterKey() ([]byte, error) {
+	if len(p.Key) < 32 {
+		return nil, fmt.Errorf("local master key must be at least 32 bytes, got %d", len(p.Key))
+	}
+	return p.Key, nil
+}
+
+// WithFieldEncryption encrypts entity fields tagged `encrypt:"deterministic"`
+// or `encrypt:"random"` before they are written and decrypts them when read,
+// using keys derived from provider. Encryption is done by a codec registered
+// for the entity type, on top of any registry set with WithRegistry.
+//
+// Only deterministic fields can be queried, and only by equality: filters
+// built from an entity are encrypted automatically, identifier filters need
+// EncryptedValue. Range, regex and sort on an encrypted field do not work.
+// Content hashes and audit records are computed on plaintext.
+func WithFieldEncryption(provider KeyProvider) FactoryOption {
+	return func(s *factorySettings) {
+		s.keyProvider = provider
+	}
+}
+
+// fieldEncryption encrypts and decrypts the tagged fields of one entity type
+type fieldEncryption struct {
+	aead   cipher.AEAD
+	macKey []byte
+	// modes maps bson field names to their encryption mode
+	modes map[string]string
+}
+
+// newFieldEncryption derives the field keys from provider and reads the
+// encrypt tags of t
+func newFieldEncryption(provider KeyProvider, t reflect.Type) (*fieldEncryption, error) {
+	master, err := provider.MasterKey()
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(deriveKey(master, "field encryption"))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	modes := make(map[string]string)
+	for _, field := range filterFieldsFor(t) {
+		switch field.encrypt {
+		case "":
+		case EncryptDeterministic, EncryptRandom:
+			modes[field.name] = field.encrypt
+		default:
+			return nil, fmt.Errorf("field %q has unknown encryption mode %q", field.name, field.encrypt)
+		}
+	}
+
+	return &fieldEncryption{aead: aead, macKey: deriveKey(master, "field nonce"), modes: modes}, nil
+}
+
+func deriveKey(master []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, master)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// registerFieldEncryption registers the encrypting codec for the entity type
+// on the configured registry, creating a default registry when none was set
+func registerFieldEncryption[T any](s *factorySettings) error {
+	var zero T
+	t := reflect.TypeOf(zero)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	encryption, err := newFieldEncryption(s.keyProvider, t)
+	if err != nil {
+		return err
+	}
+
+	registry := s.registry
+	if registry == nil {
+		registry = bson.NewRegistry()
+	}
+	encoder, err := registry.LookupEncoder(t)
+	if err != nil {
+		return err
+	}
+	decoder, err := registry.LookupDecoder(t)
+	if err != nil {
+		return err
+	}
+
+	codec := encryptingCodec{encryption: encryption, encoder: encoder, decoder: decoder}
+	registry.RegisterTypeEncoder(t, codec)
+	registry.RegisterTypeDecoder(t, codec)
+
+	s.registry = registry
+	s.encryption = encryption
+	return nil
+}
+
+// encrypt seals a BSON value of field. Deterministic nonces are an HMAC of the
+// field and value; the field is also authenticated, so ciphertext cannot be
+// moved to another field.
+func (e *fieldEncryption) encrypt(field string, value bson.RawValue) (primitive.Binary, error) {
+	plaintext := append([]byte{byte(value.Type)}, value.Value...)
+
+	nonce := make([]byte, e.aead.NonceSize())
+	if e.modes[field] == EncryptDeterministic {
+		mac := hmac.New(sha256.New, e.macKey)
+		mac.Write([]byte(field))
+		mac.Write([]byte{0})
+		mac.Write(plaintext)
+		copy(nonce, mac.Sum(nil))
+	} else if _, err := rand.Read(nonce); err != nil {
+		return primitive.Binary{}, err
+	}
+
+	data := append([]byte{encryptedVersion}, nonce...)
+	data = e.aead.Seal(data, nonce, plaintext, []byte(field))
+	return primitive.Binary{Subtype: encryptedSubtype, Data: data}, nil
+}
+
+// decrypt opens a value written by encrypt
+func (e *fieldEncryption) decrypt(field string, data []byte) (bson.RawValue, error) {
+	nonceSize := e.aead.NonceSize()
+	if len(data) < 1+nonceSize || data[0] != encryptedVersion {
+		return bson.RawValue{}, fmt.Errorf("field %q holds malformed ciphertext", field)
+	}
+
+	plaintext, err := e.aead.Open(nil, data[1:1+nonceSize], data[1+nonceSize:], []byte(field))
+	if err != nil {
+		return bson.RawValue{}, fmt.Errorf("failed to decrypt field %q: %w", field, err)
+	}
+	if len(plaintext) == 0 {
+		return bson.RawValue{}, fmt.Errorf("field %q holds malformed ciphertext", field)
+	}
+	return bson.RawValue{Type: bsontype.Type(plaintext[0]), Value: plaintext[1:]}, nil
+}
+
+// encryptDocument encrypts the tagged top-level fields of doc. Null values
+// are kept so IsNull filters keep working.
+func (e *fieldEncryption) encryptDocument(doc bson.Raw) (bson.Raw, error) {
+	return e.transformDocument(doc, func(field string, value bson.RawValue) (interface{}, error) {
+		if value.Type == bsontype.Null {
+			return value, nil
+		}
+		return e.encrypt(field, value)
+	})
+}
+
+// decryptDocument decrypts the tagged top-level fields of doc. Values that are
+// not ciphertext, such as documents written before encryption was enabled,
+// are returned unchanged.
+func (e *fieldEncryption) decryptDocument(doc bson.Raw) (bson.Raw, error) {
+	return e.transformDocument(doc, func(field string, value bson.RawValue) (interface{}, error) {
+		subtype, data, ok := value.BinaryOK()
+		if !ok || subtype != encryptedSubtype {
+			return value, nil
+		}
+		return e.decrypt(field, data)
+	})
+}
+
+func (e *fieldEncryption) transformDocument(doc bson.Raw, fn func(string, bson.RawValue) (interface{}, error)) (bson.Raw, error) {
+	elements, err := doc.Elements()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(bson.D, 0, len(elements))
+	for _, element := range elements {
+		var value interface{} = element.Value()
+		if _, ok := e.modes[element.Key()]; ok {
+			if value, err = fn(element.Key(), element.Value()); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, bson.E{Key: element.Key(), Value: value})
+	}
+	return bson.Marshal(out)
+}
+
+// encryptFilter replaces the values of deterministic fields in an equality
+// filter with their ciphertext. Values that fail to encode are left as is and
+// simply match nothing.
+func (e *fieldEncryption) encryptFilter(filter bson.M) {
+	for field, value := range filter {
+		if e.modes[field] != EncryptDeterministic {
+			continue
+		}
+		if encrypted, err := e.encryptValue(field, value); err == nil {
+			filter[field] = encrypted
+		}
+	}
+}
+
+// encryptFields encrypts the values of every encrypted field in a $set
+// document; nil values are kept so fields can still be cleared
+func (e *fieldEncryption) encryptFields(set bson.M) error {
+	for field, value := range set {
+		if _, ok := e.modes[field]; !ok || value == nil {
+			continue
+		}
+		encrypted, err := e.encryptValue(field, value)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt %s: %w", field, err)
+		}
+		set[field] = encrypted
+	}
+	return nil
+}
+
+func (e *fieldEncryption) encryptValue(field string, value interface{}) (primitive.Binary, error) {
+	t, data, err := bson.MarshalValue(value)
+	if err != nil {
+		return primitive.Binary{}, err
+	}
+	return e.encrypt(field, bson.RawValue{Type: t, Value: data})
+}
+
+// EncryptedValue returns value as stored in field, for equality filters built
+// by hand such as identifier.New().Equal("email", v). Fields that are not
+// encrypted return value unchanged; randomly encrypted fields cannot be
+// queried and return an error.
+func (uow *UnitOfWork[T]) EncryptedValue(field string, value interface{}) (interface{}, error) {
+	if uow.encryption == nil {
+		return value, nil
+	}
+
+	switch uow.encryption.modes[field] {
+	case "":
+		return value, nil
+	case EncryptRandom:
+		return nil, fmt.Errorf("field %q uses random encryption and cannot be queried", field)
+	default:
+		return uow.encryption.encryptValue(field, value)
+	}
+}
+
+// encryptingCodec wraps the registry's struct codec for an entity type,
+// encrypting tagged fields after encoding and decrypting them before decoding
+type encryptingCodec struct {
+	encryption *fieldEncryption
+	encoder    bsoncodec.ValueEncoder
+	decoder    bsoncodec.ValueDecoder
+}
+
+func (c encryptingCodec) EncodeValue(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	var buf bytes.Buffer
+	plainWriter, err := bsonrw.NewBSONValueWriter(&buf)
+	if err != nil {
+		return err
+	}
+	if err := c.encoder.EncodeValue(ec, plainWriter, val); err != nil {
+		return err
+	}
+
+	doc, err := c.encryption.encryptDocument(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	return bsonrw.Copier{}.CopyDocumentFromBytes(vw, doc)
+}
+
+func (c encryptingCodec) DecodeValue(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if t := vr.Type(); t == bsontype.Null || t == bsontype.Undefined {
+		return c.decoder.DecodeValue(dc, vr, val)
+	}
+
+	raw, err := bsonrw.Copier{}.CopyDocumentToBytes(vr)
+	if err != nil {
+		return err
+	}
+	doc, err := c.encryption.decryptDocument(raw)
+	if err != nil {
+		return err
+	}
+	return c.decoder.DecodeValue(dc, bsonrw.NewBSONDocumentReader(doc), val)
+}
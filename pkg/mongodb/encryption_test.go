@@ -0,0 +1,240 @@
+package mongodb
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+)
+
+type Patient struct {
+	domain.BaseEntity `bson:",inline"`
+	Email             string `bson:"email" json:"email" encrypt:"deterministic"`
+	Notes             string `bson:"notes,omitempty" json:"notes,omitempty" encrypt:"random"`
+	Ward              string `bson:"ward" json:"ward"`
+}
+
+var testMasterKey = LocalKeyProvider{Key: bytes.Repeat([]byte{7}, 32)}
+
+func marshalWith(t *testing.T, registry *bsoncodec.Registry, v interface{}) bson.Raw {
+	t.Helper()
+	var buf bytes.Buffer
+	vw, err := bsonrw.NewBSONValueWriter(&buf)
+	require.NoError(t, err)
+	encoder, err := bson.NewEncoder(vw)
+	require.NoError(t, err)
+	require.NoError(t, encoder.SetRegistry(registry))
+	require.NoError(t, encoder.Encode(v))
+	return buf.Bytes()
+}
+
+func unmarshalWith(t *testing.T, registry *bsoncodec.Registry, data []byte, v interface{}) error {
+	t.Helper()
+	decoder, err := bson.NewDecoder(bsonrw.NewBSONDocumentReader(data))
+	require.NoError(t, err)
+	require.NoError(t, decoder.SetRegistry(registry))
+	return decoder.Decode(v)
+}
+
+func newEncryptingFactory(t *testing.T, opts ...FactoryOption) *Factory[*Patient] {
+	t.Helper()
+	factory, err := NewFactory[*Patient](NewConfig(), append(opts, WithFieldEncryption(testMasterKey))...)
+	require.NoError(t, err)
+	return factory
+}
+
+func TestLocalKeyProvider(t *testing.T) {
+	_, err := LocalKeyProvider{Key: make([]byte, 16)}.MasterKey()
+	assert.Error(t, err)
+
+	_, err = NewFactory[*Patient](NewConfig(), WithFieldEncryption(LocalKeyProvider{}))
+	assert.Error(t, err)
+}
+
+func TestWithFieldEncryption_UnknownMode(t *testing.T) {
+	type Broken struct {
+		domain.BaseEntity `bson:",inline"`
+		SSN               string `bson:"ssn" encrypt:"sometimes"`
+	}
+
+	_, err := NewFactory[*Broken](NewConfig(), WithFieldEncryption(testMasterKey))
+	assert.ErrorContains(t, err, `unknown encryption mode "sometimes"`)
+}
+
+func TestFieldEncryption_RoundTrip(t *testing.T) {
+	factory := newEncryptingFactory(t)
+	registry := factory.settings.registry
+
+	patient := &Patient{Email: "jane@example.com", Notes: "allergic to penicillin", Ward: "B"}
+	patient.SetID(primitive.NewObjectID())
+
+	raw := marshalWith(t, registry, patient)
+
+	email := raw.Lookup("email")
+	subtype, data, ok := email.BinaryOK()
+	require.True(t, ok, "email must be stored as ciphertext")
+	assert.Equal(t, encryptedSubtype, subtype)
+	assert.NotContains(t, string(data), "jane@example.com")
+	assert.NotContains(t, string(raw), "penicillin")
+	assert.Equal(t, "B", raw.Lookup("ward").StringValue(), "untagged fields stay plaintext")
+	assert.Equal(t, patient.GetID(), raw.Lookup("_id").ObjectID())
+
+	var decoded Patient
+	require.NoError(t, unmarshalWith(t, registry, raw, &decoded))
+	assert.Equal(t, "jane@example.com", decoded.Email)
+	assert.Equal(t, "allergic to penicillin", decoded.Notes)
+	assert.Equal(t, "B", decoded.Ward)
+}
+
+func TestFieldEncryption_Modes(t *testing.T) {
+	registry := newEncryptingFactory(t).settings.registry
+	patient := &Patient{Email: "jane@example.com", Notes: "note"}
+
+	first := marshalWith(t, registry, patient)
+	second := marshalWith(t, registry, patient)
+
+	assert.Equal(t, first.Lookup("email"), second.Lookup("email"), "deterministic fields encrypt identically")
+	assert.NotEqual(t, first.Lookup("notes"), second.Lookup("notes"), "random fields use a fresh nonce")
+}
+
+func TestFieldEncryption_DecodesPlaintext(t *testing.T) {
+	registry := newEncryptingFactory(t).settings.registry
+	legacy, err := bson.Marshal(bson.M{"email": "old@example.com", "ward": "A"})
+	require.NoError(t, err)
+
+	var decoded Patient
+	require.NoError(t, unmarshalWith(t, registry, legacy, &decoded))
+	assert.Equal(t, "old@example.com", decoded.Email)
+}
+
+func TestFieldEncryption_RejectsMovedCiphertext(t *testing.T) {
+	encryption := newEncryptingFactory(t).settings.encryption
+	encryption.modes["ward"] = EncryptDeterministic
+
+	value, err := encryption.encryptValue("email", "jane@example.com")
+	require.NoError(t, err)
+
+	_, err = encryption.decrypt("ward", value.Data)
+	assert.Error(t, err)
+}
+
+func TestFieldEncryption_WrapsExistingRegistry(t *testing.T) {
+	registry := bson.NewRegistry()
+	factory := newEncryptingFactory(t, WithRegistry(registry))
+	assert.Same(t, registry, factory.settings.registry)
+}
+
+func TestFieldEncryption_Filters(t *testing.T) {
+	factory := newEncryptingFactory(t)
+	uow := newOfflineUnitOfWork[*Patient](t, nil)
+	factory.apply(uow)
+
+	stored := marshalWith(t, factory.settings.registry, &Patient{Email: "jane@example.com"}).Lookup("email")
+
+	filter := uow.buildFilterFromModel(&Patient{Email: "jane@example.com", Ward: "B"})
+	encoded, err := bson.Marshal(filter)
+	require.NoError(t, err)
+	assert.Equal(t, stored, bson.Raw(encoded).Lookup("email"))
+	assert.Equal(t, "B", filter["ward"])
+
+	value, err := uow.EncryptedValue("email", "jane@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, filter["email"], value)
+
+	value, err = uow.EncryptedValue("ward", "B")
+	require.NoError(t, err)
+	assert.Equal(t, "B", value)
+
+	_, err = uow.EncryptedValue("notes", "x")
+	assert.Error(t, err)
+}
+
+func TestFieldEncryption_Upsert(t *testing.T) {
+	uow := newOfflineUnitOfWork[*Patient](t, nil)
+	newEncryptingFactory(t).apply(uow)
+
+	update, err := uow.upsertUpdate(&Patient{Email: "jane@example.com", Ward: "B"})
+	require.NoError(t, err)
+
+	for _, e := range update["$set"].(bson.D) {
+		if e.Key == "email" {
+			subtype, _, ok := e.Value.(bson.RawValue).BinaryOK()
+			assert.True(t, ok, "upserted email must be ciphertext")
+			assert.Equal(t, encryptedSubtype, subtype)
+		}
+	}
+}
+
+func TestFieldEncryption_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*Patient](t, WithFieldEncryption(testMasterKey))
+	ctx := context.Background()
+
+	inserted, err := uow.Insert(ctx, &Patient{Email: "jane@example.com", Notes: "private", Ward: "B"})
+	require.NoError(t, err)
+
+	var raw bson.M
+	require.NoError(t, uow.getCollection().FindOne(ctx, bson.M{"_id": inserted.GetID()}).Decode(&raw))
+	assert.IsType(t, primitive.Binary{}, raw["email"])
+	assert.IsType(t, primitive.Binary{}, raw["notes"])
+	assert.Equal(t, "B", raw["ward"])
+
+	found, err := uow.FindOne(ctx, &Patient{Email: "jane@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "private", found.Notes)
+
+	email, err := uow.EncryptedValue("email", "jane@example.com")
+	require.NoError(t, err)
+	found, err = uow.FindOneByIdentifier(ctx, identifier.New().Equal("email", email))
+	require.NoError(t, err)
+	assert.Equal(t, inserted.GetID(), found.GetID())
+}
+
+func TestUnitOfWork_Unmarshal_Decrypts(t *testing.T) {
+	factory := newEncryptingFactory(t)
+	uow := newOfflineUnitOfWork[*Patient](t, nil)
+	factory.apply(uow)
+
+	raw := marshalWith(t, factory.settings.registry, &Patient{Email: "jane@example.com", Ward: "B"})
+
+	var patient *Patient
+	require.NoError(t, uow.unmarshal(raw, &patient))
+	assert.Equal(t, "jane@example.com", patient.Email)
+}
+
+func TestFieldEncryption_SetFields(t *testing.T) {
+	factory := newEncryptingFactory(t)
+	uow := newOfflineUnitOfWork[*Patient](t, nil)
+	factory.apply(uow)
+
+	set, err := uow.setFields(bson.M{"email": "jane@example.com", "notes": nil, "ward": "C"})
+	require.NoError(t, err)
+
+	email, err := uow.EncryptedValue("email", "jane@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, email, set["email"])
+	assert.Nil(t, set["notes"], "clearing an encrypted field stays possible")
+	assert.Equal(t, "C", set["ward"])
+}
+
+func TestUnitOfWork_DiffUpdate_Plaintext(t *testing.T) {
+	uow := newOfflineUnitOfWork[*Patient](t, nil)
+	newEncryptingFactory(t).apply(uow)
+
+	before := &Patient{Email: "jane@example.com", Notes: "allergic to penicillin", Ward: "B"}
+	entity := &Patient{Email: "jane@example.com", Notes: "allergic to penicillin", Ward: "C"}
+
+	updated, changes, err := uow.diffUpdate(before, entity)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]FieldChange{"ward": {Old: "B", New: "C"}}, changes, "randomly encrypted fields are compared in plaintext")
+	assert.Equal(t, "allergic to penicillin", updated.Notes)
+	assert.Equal(t, "C", updated.Ward)
+}
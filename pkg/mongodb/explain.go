@@ -0,0 +1,63 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+)
+
+// Explain returns the queryPlanner output for the read FindAllWithPagination
+// would issue for query, e.g. to check in tests that it uses an index
+// (an IXSCAN stage in queryPlanner.winningPlan) rather than a COLLSCAN.
+// Queries that need an aggregation are explained as one.
+func (uow *UnitOfWork[T]) Explain(ctx context.Context, query domain.QueryParams[T]) (bson.M, error) {
+	if err := uow.validatePage(query); err != nil {
+		return nil, err
+	}
+	collection, filter, err := uow.pageScope(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var command bson.D
+	if needsAggregate(query) {
+		command = bson.D{
+			{Key: "aggregate", Value: collection.Name()},
+			{Key: "pipeline", Value: uow.aggregatePipeline(filter, query)},
+			{Key: "cursor", Value: bson.D{}},
+		}
+	} else {
+		opts := pageOptions(query)
+		command = bson.D{
+			{Key: "find", Value: collection.Name()},
+			{Key: "filter", Value: filter},
+		}
+		if opts.Sort != nil {
+			command = append(command, bson.E{Key: "sort", Value: opts.Sort})
+		}
+		if opts.Projection != nil {
+			command = append(command, bson.E{Key: "projection", Value: opts.Projection})
+		}
+		if opts.Skip != nil {
+			command = append(command, bson.E{Key: "skip", Value: *opts.Skip})
+		}
+		if opts.Limit != nil {
+			command = append(command, bson.E{Key: "limit", Value: *opts.Limit})
+		}
+	}
+
+	uow.track(opFind)
+	var plan bson.M
+	err = uow.database.RunCommand(uow.getContext(ctx), bson.D{
+		{Key: "explain", Value: command},
+		{Key: "verbosity", Value: "queryPlanner"},
+	}).Decode(&plan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain: %w", err)
+	}
+
+	return plan, nil
+}
@@ -0,0 +1,77 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+)
+
+// planStages collects every "stage" name in an explain document
+func planStages(doc interface{}) []string {
+	var stages []string
+	switch v := doc.(type) {
+	case bson.M:
+		for key, value := range v {
+			if name, ok := value.(string); ok && key == "stage" {
+				stages = append(stages, name)
+				continue
+			}
+			stages = append(stages, planStages(value)...)
+		}
+	case bson.D:
+		for _, e := range v {
+			if name, ok := e.Value.(string); ok && e.Key == "stage" {
+				stages = append(stages, name)
+				continue
+			}
+			stages = append(stages, planStages(e.Value)...)
+		}
+	case bson.A:
+		for _, item := range v {
+			stages = append(stages, planStages(item)...)
+		}
+	}
+	return stages
+}
+
+func TestPlanStages(t *testing.T) {
+	plan := bson.M{"queryPlanner": bson.M{"winningPlan": bson.M{
+		"stage":      "FETCH",
+		"inputStage": bson.M{"stage": "IXSCAN", "indexName": "email_1"},
+	}}}
+	assert.ElementsMatch(t, []string{"FETCH", "IXSCAN"}, planStages(plan))
+}
+
+func TestUnitOfWork_Explain_ValidatesQuery(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+	uow.strictSort = true
+
+	_, err := uow.Explain(context.Background(), domain.QueryParams[*TestUser]{})
+	assert.ErrorIs(t, err, uowerrors.ErrSortRequired)
+}
+
+func TestUnitOfWork_Explain_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	_, err := uow.EnsureIndexes(ctx, IndexSpec{Keys: bson.D{{Key: "email", Value: 1}}})
+	require.NoError(t, err)
+	_, err = uow.Insert(ctx, &TestUser{Email: "indexed@example.com"})
+	require.NoError(t, err)
+
+	plan, err := uow.Explain(ctx, domain.QueryParams[*TestUser]{Filter: &TestUser{Email: "indexed@example.com"}})
+	require.NoError(t, err)
+	winning := plan["queryPlanner"].(bson.M)["winningPlan"]
+	assert.Contains(t, planStages(winning), "IXSCAN")
+
+	plan, err = uow.Explain(ctx, domain.QueryParams[*TestUser]{Filter: &TestUser{Age: 30}})
+	require.NoError(t, err)
+	winning = plan["queryPlanner"].(bson.M)["winningPlan"]
+	assert.Contains(t, planStages(winning), "COLLSCAN")
+}
@@ -0,0 +1,94 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+)
+
+const (
+	defaultExportBatch = 1000
+	maxExportBatch     = 10000
+)
+
+// Export returns one batch of a resumable export ordered like
+// FindAllWithCursor. query.Cursor takes the ResumeToken of the previous
+// batch, or a NextCursor from FindAllWithCursor, and empty starts from the
+// beginning. Batches hold full documents and only one batch is in memory at
+// a time. Tokens do not expire, so an export can be resumed by another
+// process much later; documents inserted behind the resume point are skipped.
+func (uow *UnitOfWork[T]) Export(ctx context.Context, query domain.KeysetParams[T]) (persistence.ExportBatch[T], error) {
+	var batch persistence.ExportBatch[T]
+
+	field := query.SortField
+	if field == "" {
+		field = "_id"
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultExportBatch
+	}
+	if limit > maxExportBatch {
+		limit = maxExportBatch
+	}
+
+	descending := query.Direction == domain.SortDesc
+
+	filter := uow.excludeDeleted(bson.M{})
+	if !isZeroValue(query.Filter) {
+		for k, v := range uow.buildFilterFromModel(query.Filter) {
+			filter[k] = v
+		}
+	}
+	if query.Cursor != "" {
+		c, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return batch, err
+		}
+		if c.Field != field {
+			return batch, fmt.Errorf("%w: cursor is for field %q, not %q", uowerrors.ErrInvalidQueryParams, c.Field, field)
+		}
+		if c.Direction != pageNext {
+			return batch, fmt.Errorf("%w: exports only resume forward", uowerrors.ErrInvalidQueryParams)
+		}
+		filter = bson.M{"$and": bson.A{filter, keysetFilter(field, c.Value, c.ID, descending)}}
+	}
+
+	opts := options.Find().
+		SetSort(keysetSort(field, descending)).
+		SetLimit(int64(limit + 1))
+
+	uow.track(opFind)
+	queryCtx := uow.getContext(ctx)
+	cursor, err := uow.getCollection().Find(queryCtx, filter, opts)
+	if err != nil {
+		return batch, fmt.Errorf("failed to export: %w", err)
+	}
+	defer cursor.Close(queryCtx)
+
+	var items []T
+	if err := uow.decodeAll(queryCtx, cursor, &items); err != nil {
+		return batch, fmt.Errorf("failed to decode results: %w", err)
+	}
+
+	more := len(items) > limit
+	if more {
+		items = items[:limit]
+	}
+
+	batch.Items = items
+	batch.Done = !more
+	if more {
+		if batch.ResumeToken, err = uow.cursorFor(items[len(items)-1], field, pageNext); err != nil {
+			return batch, err
+		}
+	}
+	return batch, nil
+}
@@ -0,0 +1,96 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+)
+
+func TestUnitOfWork_Export_InvalidToken(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, &Config{EnableStats: true})
+	ctx := context.Background()
+
+	_, err := uow.Export(ctx, domain.KeysetParams[*TestUser]{Cursor: "garbage"})
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQueryParams)
+
+	backward, err := encodeCursor(keysetCursor{Field: "_id", Direction: pagePrev})
+	require.NoError(t, err)
+	_, err = uow.Export(ctx, domain.KeysetParams[*TestUser]{Cursor: backward})
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQueryParams, "exports only move forward")
+
+	otherField, err := encodeCursor(keysetCursor{Field: "email", Direction: pageNext})
+	require.NoError(t, err)
+	_, err = uow.Export(ctx, domain.KeysetParams[*TestUser]{SortField: "age", Cursor: otherField})
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQueryParams)
+
+	assert.Zero(t, uow.Stats().Total())
+}
+
+func TestUnitOfWork_Export_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	// Ages repeat so batches have to break ties on _id
+	users := make([]*TestUser, 23)
+	for i := range users {
+		users[i] = &TestUser{Email: fmt.Sprintf("user%02d@example.com", i), Age: 20 + i%4}
+	}
+	inserted, err := uow.BulkInsert(ctx, users)
+	require.NoError(t, err)
+
+	query := domain.KeysetParams[*TestUser]{SortField: "age", Limit: 5}
+	seen := make(map[primitive.ObjectID]bool)
+	lastAge, batches := 0, 0
+	for {
+		batch, err := uow.Export(ctx, query)
+		require.NoError(t, err)
+		batches++
+
+		for _, user := range batch.Items {
+			assert.False(t, seen[user.GetID()], "duplicate %s", user.Email)
+			assert.GreaterOrEqual(t, user.Age, lastAge)
+			seen[user.GetID()] = true
+			lastAge = user.Age
+		}
+		if batch.Done {
+			assert.Empty(t, batch.ResumeToken)
+			break
+		}
+		require.Len(t, batch.Items, 5)
+		require.NotEmpty(t, batch.ResumeToken)
+
+		// Resume from another unit of work, as a later run would
+		query.Cursor = batch.ResumeToken
+		uow = uow.ForDatabase(uow.database.Name()).(*UnitOfWork[*TestUser])
+	}
+
+	assert.Equal(t, 5, batches)
+	assert.Len(t, seen, len(inserted), "every document is exported exactly once")
+}
+
+func TestUnitOfWork_Export_ResumesFromCursorPage_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ {
+		_, err := uow.Insert(ctx, &TestUser{Email: fmt.Sprintf("user%d@example.com", i), Age: 30})
+		require.NoError(t, err)
+	}
+
+	page, err := uow.FindAllWithCursor(ctx, domain.KeysetParams[*TestUser]{Limit: 1})
+	require.NoError(t, err)
+	require.NotEmpty(t, page.NextCursor)
+
+	batch, err := uow.Export(ctx, domain.KeysetParams[*TestUser]{Cursor: page.NextCursor})
+	require.NoError(t, err)
+	assert.True(t, batch.Done)
+	assert.Len(t, batch.Items, 3)
+	assert.NotEqual(t, page.Items[0].GetID(), batch.Items[0].GetID())
+}
@@ -3,37 +3,271 @@ package mongodb
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
 )
 
-// Factory implements IUnitOfWorkFactory for MongoDB
+// Factory implements IUnitOfWorkFactory for MongoDB. Every unit of work it
+// creates shares one client, connected on first use and released by Close.
 type Factory[T persistence.ModelConstraint] struct {
-	config *Config
+	config   *Config
+	settings factorySettings
+
+	mu     sync.Mutex
+	client *mongo.Client
+	// connections counts the connections currently open in the client pool
+	connections atomic.Int64
+}
+
+// factorySettings holds the values configured through FactoryOption
+type factorySettings struct {
+	slugStrategy   SlugStrategy
+	slugAttempts   int
+	slugIndex      *slugIndexes
+	references     map[string]Reference
+	strictDecoding bool
+	registry       *bsoncodec.Registry
+	logger         *slog.Logger
+	auditSink      AuditSink
+	trashMode      bool
+	strictSort     bool
+	txFallback     bool
+	keyProvider    KeyProvider
+	encryption     *fieldEncryption
+	poolMonitor    *event.PoolMonitor
+	timeSeries     *timeSeriesCollection
+	collectionName string
+}
+
+// FactoryOption customizes the unit of work instances created by a Factory
+type FactoryOption func(*factorySettings)
+
+// WithSlugStrategy enables slug generation on insert for entities that have a
+// name but no slug, using strategy; nil, the default, disables it
+func WithSlugStrategy(strategy SlugStrategy) FactoryOption {
+	return func(s *factorySettings) {
+		s.slugStrategy = strategy
+	}
+}
+
+// WithRegistry sets the BSON registry used by the client, so custom codecs for
+// entity field types are honored on both writes and reads
+func WithRegistry(registry *bsoncodec.Registry) FactoryOption {
+	return func(s *factorySettings) {
+		s.registry = registry
+	}
+}
+
+// WithLogger sets the logger that records collection-wide operations such as
+// RestoreAll and DeleteAll, warnings, and every command at debug level; nil
+// disables logging. Records carry the request ID set with WithRequestID.
+func WithLogger(logger *slog.Logger) FactoryOption {
+	return func(s *factorySettings) {
+		s.logger = nil
+		if logger != nil {
+			s.logger = slog.New(requestIDHandler{logger.Handler()})
+		}
+	}
+}
+
+// WithStrictSort makes paginated queries fail with ErrSortRequired when they
+// specify neither Sort nor ArraySort, instead of returning natural order
+func WithStrictSort() FactoryOption {
+	return func(s *factorySettings) {
+		s.strictSort = true
+	}
+}
+
+// WithCollectionName stores the entities in the named collection, overriding
+// both CollectionName on the entity and the pluralized type name
+func WithCollectionName(name string) FactoryOption {
+	return func(s *factorySettings) {
+		s.collectionName = name
+	}
 }
 
 // NewFactory creates a new MongoDB unit of work factory
-func NewFactory[T persistence.ModelConstraint](config *Config) (*Factory[T], error) {
+func NewFactory[T persistence.ModelConstraint](config *Config, opts ...FactoryOption) (*Factory[T], error) {
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid config: %w", err)
 	}
 
+	settings := factorySettings{
+		slugAttempts: defaultSlugAttempts,
+		slugIndex:    newSlugIndexes(),
+	}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	if settings.timeSeries != nil {
+		if err := settings.timeSeries.options.validate(); err != nil {
+			return nil, fmt.Errorf("invalid time-series options: %w", err)
+		}
+	}
+	if settings.keyProvider != nil {
+		if err := registerFieldEncryption[T](&settings); err != nil {
+			return nil, fmt.Errorf("invalid field encryption: %w", err)
+		}
+	}
+
 	return &Factory[T]{
-		config: config,
+		config:   config,
+		settings: settings,
 	}, nil
 }
 
-// Create creates a new unit of work instance
+// Ping reports whether MongoDB is reachable, returning an error matching
+// errors.IsConnection
+func (f *Factory[T]) Ping(ctx context.Context) error {
+	client, err := f.sharedClient()
+	if err != nil {
+		return fmt.Errorf("%w: %w", uowerrors.ErrDatabaseConnection, err)
+	}
+
+	if err := client.Ping(ctx, nil); err != nil {
+		return fmt.Errorf("%w: %w", uowerrors.ErrDatabaseConnection, err)
+	}
+
+	return nil
+}
+
+// CollectionInfo describes a collection in the configured database
+type CollectionInfo struct {
+	Name string `json:"name"`
+	// EstimatedCount comes from collection metadata and may be stale after an
+	// unclean shutdown or while orphaned documents exist on sharded clusters
+	EstimatedCount int64 `json:"estimatedCount"`
+}
+
+// ListCollections returns the collections of the configured database, sorted
+// by name, with their estimated document counts. Views and system collections
+// are skipped.
+func (f *Factory[T]) ListCollections(ctx context.Context) ([]CollectionInfo, error) {
+	client, err := f.sharedClient()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", uowerrors.ErrDatabaseConnection, err)
+	}
+	database := client.Database(f.config.Database)
+
+	filter := bson.M{
+		"type": "collection",
+		"name": bson.M{"$not": primitive.Regex{Pattern: `^system\.`}},
+	}
+	names, err := database.ListCollectionNames(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	sort.Strings(names)
+
+	infos := make([]CollectionInfo, 0, len(names))
+	for _, name := range names {
+		count, err := database.Collection(name).EstimatedDocumentCount(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", name, err)
+		}
+		infos = append(infos, CollectionInfo{Name: name, EstimatedCount: count})
+	}
+
+	return infos, nil
+}
+
+// Create creates a new unit of work instance. When MongoDB cannot be reached
+// it returns one whose operations all fail with ErrNotConnected.
 func (f *Factory[T]) Create() persistence.IUnitOfWork[T] {
-	uow, err := NewUnitOfWork[T](f.config)
+	uow, err := f.newUnitOfWork()
 	if err != nil {
-		// In a real implementation, you might want to handle this differently
-		// For now, we'll panic as this indicates a serious configuration error
-		panic(fmt.Sprintf("failed to create unit of work: %v", err))
+		disconnected := newDisconnectedUnitOfWork[T](err)
+		disconnected.collectionName = f.collectionName()
+		return disconnected
 	}
 	return uow
 }
 
+// newUnitOfWork creates a unit of work on the shared client and applies the
+// factory settings
+func (f *Factory[T]) newUnitOfWork() (*UnitOfWork[T], error) {
+	client, err := f.sharedClient()
+	if err != nil {
+		return nil, err
+	}
+	uow := newUnitOfWork[T](f.config, client)
+	f.apply(uow)
+	return uow, nil
+}
+
+// sharedClient returns the factory client, connecting it on first use. A
+// failed connection is not cached, so a later call retries.
+func (f *Factory[T]) sharedClient() (*mongo.Client, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.client != nil {
+		return f.client, nil
+	}
+	client, err := connectClient(f.config, f.settings.registry, f.poolMonitor(), commandMonitor(f.settings.logger))
+	if err != nil {
+		return nil, err
+	}
+	f.client = client
+	return client, nil
+}
+
+// Close disconnects the shared client. Units of work created earlier stop
+// working; a later Create connects a new client.
+func (f *Factory[T]) Close(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.client == nil {
+		return nil
+	}
+	client := f.client
+	f.client = nil
+	return client.Disconnect(ctx)
+}
+
+// apply copies the factory settings onto a freshly created unit of work
+func (f *Factory[T]) apply(uow *UnitOfWork[T]) {
+	uow.slugStrategy = f.settings.slugStrategy
+	uow.slugAttempts = f.settings.slugAttempts
+	uow.slugIndex = f.settings.slugIndex
+	uow.references = f.settings.references
+	uow.strictDecoding = f.settings.strictDecoding
+	uow.logger = f.settings.logger
+	uow.auditSink = f.settings.auditSink
+	uow.trashMode = f.settings.trashMode && uow.softDelete
+	uow.strictSort = f.settings.strictSort
+	uow.txFallback = f.settings.txFallback
+	uow.encryption = f.settings.encryption
+	uow.registry = f.settings.registry
+	uow.collectionName = f.collectionName()
+	if f.settings.timeSeries != nil {
+		uow.timeSeries = f.settings.timeSeries
+		uow.softDelete = false
+		uow.trashMode = false
+	}
+}
+
+// collectionName returns the collection the factory's units of work use
+func (f *Factory[T]) collectionName() string {
+	if f.settings.collectionName != "" {
+		return f.settings.collectionName
+	}
+	var zero T
+	return getCollectionName(zero)
+}
+
 // CreateWithContext creates a new unit of work instance with context
 func (f *Factory[T]) CreateWithContext(ctx context.Context) persistence.IUnitOfWork[T] {
 	return f.Create()
@@ -0,0 +1,166 @@
+package mongodb
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+)
+
+func TestFactory_ListCollections_Unreachable(t *testing.T) {
+	factory, err := NewFactory[*TestUser](unreachableConfig())
+	require.NoError(t, err)
+
+	_, err = factory.ListCollections(context.Background())
+	assert.True(t, uowerrors.IsConnection(err))
+}
+
+func TestFactory_ListCollections_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	_, err := uow.Insert(ctx, &TestUser{Email: "a@example.com"})
+	require.NoError(t, err)
+	_, err = uow.Insert(ctx, &TestUser{Email: "b@example.com"})
+	require.NoError(t, err)
+	require.NoError(t, uow.database.CreateCollection(ctx, "empty"))
+	require.NoError(t, uow.database.CreateView(ctx, "testusers_view", "testusers", bson.A{}))
+
+	config := NewConfig()
+	config.Database = uow.database.Name()
+	factory, err := NewFactory[*TestUser](config)
+	require.NoError(t, err)
+	defer factory.Close(ctx)
+
+	infos, err := factory.ListCollections(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []CollectionInfo{
+		{Name: "empty", EstimatedCount: 0},
+		{Name: "testusers", EstimatedCount: 2},
+	}, infos)
+}
+
+func TestFactory_SharedClient_RetriesAfterFailure(t *testing.T) {
+	factory, err := NewFactory[*TestUser](unreachableConfig())
+	require.NoError(t, err)
+
+	_, err = factory.sharedClient()
+	require.Error(t, err)
+	assert.Nil(t, factory.client, "a failed connection must not be cached")
+	assert.NoError(t, factory.Close(context.Background()))
+}
+
+func TestNewUnitOfWorkWithClient(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().
+		ApplyURI("mongodb://127.0.0.1:1").
+		SetServerSelectionTimeout(50*time.Millisecond))
+	require.NoError(t, err)
+
+	uow, err := NewUnitOfWorkWithClient[*TestUser](NewConfig(), client)
+	require.NoError(t, err)
+	assert.Same(t, client, uow.client)
+	assert.Equal(t, "testusers", uow.CollectionName())
+
+	require.NoError(t, uow.Close(ctx))
+	assert.NoError(t, client.Disconnect(ctx), "Close must leave a borrowed client connected")
+
+	_, err = NewUnitOfWorkWithClient[*TestUser](NewConfig(), nil)
+	assert.Error(t, err)
+}
+
+func TestFactory_SharesClient_Integration(t *testing.T) {
+	newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	factory, err := NewFactory[*TestUser](NewConfig())
+	require.NoError(t, err)
+
+	first := factory.Create().(*UnitOfWork[*TestUser])
+	second := factory.CreateWithContext(ctx).(*UnitOfWork[*TestUser])
+	assert.Same(t, first.client, second.client)
+
+	require.NoError(t, first.Close(ctx))
+	require.NoError(t, second.client.Ping(ctx, nil), "closing a unit of work keeps the shared client open")
+
+	require.NoError(t, factory.Close(ctx))
+	assert.Nil(t, factory.client)
+
+	third := factory.Create().(*UnitOfWork[*TestUser])
+	assert.NotSame(t, first.client, third.client)
+	require.NoError(t, factory.Close(ctx))
+}
+
+func TestFactory_WarmUp_Unreachable(t *testing.T) {
+	factory, err := NewFactory[*TestUser](unreachableConfig())
+	require.NoError(t, err)
+
+	assert.NoError(t, factory.WarmUp(context.Background(), 0), "nothing to warm up")
+	assert.NoError(t, factory.WarmUp(context.Background(), -1), "a negative count is not clamped to the pool size")
+	assert.Nil(t, factory.client)
+
+	err = factory.WarmUp(context.Background(), 3)
+	assert.True(t, uowerrors.IsConnection(err))
+}
+
+func TestFactory_PoolMonitor_CountsConnections(t *testing.T) {
+	var forwarded []string
+	factory, err := NewFactory[*TestUser](NewConfig(), WithPoolMonitor(&event.PoolMonitor{
+		Event: func(e *event.PoolEvent) { forwarded = append(forwarded, e.Type) },
+	}))
+	require.NoError(t, err)
+
+	monitor := factory.poolMonitor()
+	for _, eventType := range []string{event.ConnectionCreated, event.ConnectionCreated, event.ConnectionReady, event.ConnectionClosed} {
+		monitor.Event(&event.PoolEvent{Type: eventType})
+	}
+
+	assert.Equal(t, int64(1), factory.connections.Load())
+	assert.Len(t, forwarded, 4, "every event reaches the caller's monitor")
+}
+
+func TestFactory_WarmUp_Integration(t *testing.T) {
+	newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	ready := 0
+	monitor := &event.PoolMonitor{Event: func(e *event.PoolEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch e.Type {
+		case event.ConnectionReady:
+			ready++
+		case event.ConnectionClosed:
+			ready--
+		}
+	}}
+	openConnections := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return ready
+	}
+
+	config := NewConfig()
+	config.MinPoolSize = 0
+	config.MaxPoolSize = 8
+	factory, err := NewFactory[*TestUser](config, WithPoolMonitor(monitor))
+	require.NoError(t, err)
+	defer factory.Close(ctx)
+
+	require.NoError(t, factory.WarmUp(ctx, 5))
+	assert.GreaterOrEqual(t, openConnections(), 5)
+
+	require.NoError(t, factory.WarmUp(ctx, 50))
+	assert.GreaterOrEqual(t, openConnections(), 8, "warm-up stops at MaxPoolSize")
+	assert.LessOrEqual(t, openConnections(), 8)
+}
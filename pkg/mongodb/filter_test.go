@@ -0,0 +1,87 @@
+package mongodb
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+)
+
+// buildFilterUncached is the original buildFilterFromModel, walking the
+// struct with reflection on every call; it is the reference the cached
+// implementation must match
+func buildFilterUncached(model interface{}) bson.M {
+	filter := bson.M{}
+
+	v := reflect.ValueOf(model)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		fieldType := t.Field(i)
+
+		if !field.CanInterface() {
+			continue
+		}
+
+		fieldName := fieldType.Name
+		if tag := fieldType.Tag.Get("bson"); tag != "" && tag != "-" {
+			fieldName = strings.Split(tag, ",")[0]
+		}
+
+		if field.IsZero() {
+			continue
+		}
+
+		filter[fieldName] = field.Interface()
+	}
+
+	return filter
+}
+
+func TestBuildFilterFromModel_MatchesUncached(t *testing.T) {
+	uow := &UnitOfWork[*TestUser]{}
+	now := time.Now()
+
+	models := []*TestUser{
+		{},
+		{Email: "a@example.com"},
+		{Email: "a@example.com", Age: 30, Active: true},
+		{BaseEntity: domain.BaseEntity{ID: primitive.NewObjectID(), Name: "alice", Slug: "alice"}, Age: 30},
+		{BaseEntity: domain.BaseEntity{CreatedAt: now, DeletedAt: &now}},
+	}
+
+	for _, model := range models {
+		assert.Equal(t, buildFilterUncached(model), uow.buildFilterFromModel(model))
+	}
+}
+
+func BenchmarkBuildFilterFromModel_Cached(b *testing.B) {
+	uow := &UnitOfWork[*TestUser]{}
+	model := &TestUser{BaseEntity: domain.BaseEntity{Name: "alice"}, Email: "a@example.com", Age: 30}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = uow.buildFilterFromModel(model)
+	}
+}
+
+func BenchmarkBuildFilterFromModel_Uncached(b *testing.B) {
+	model := &TestUser{BaseEntity: domain.BaseEntity{Name: "alice"}, Email: "a@example.com", Age: 30}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = buildFilterUncached(model)
+	}
+}
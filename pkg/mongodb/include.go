@@ -0,0 +1,115 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+)
+
+// Reference describes how an include name is populated from another
+// collection. The populated value is written to the include name, so the
+// entity needs a matching bson field (usually tagged omitempty).
+type Reference struct {
+	// Collection holds the referenced documents
+	Collection string
+	// LocalField is the field on this entity holding the referenced ID(s)
+	LocalField string
+	// ForeignField is matched in Collection; defaults to _id
+	ForeignField string
+	// Many keeps every match as an array instead of a single document
+	Many bool
+}
+
+// WithReference registers a reference that QueryParams.Include can populate
+func WithReference(include string, ref Reference) FactoryOption {
+	return func(s *factorySettings) {
+		if s.references == nil {
+			s.references = make(map[string]Reference)
+		}
+		s.references[include] = ref
+	}
+}
+
+// validateIncludes rejects include names without a registered reference so
+// they are not silently ignored
+func (uow *UnitOfWork[T]) validateIncludes(includes []string) error {
+	for _, include := range includes {
+		if _, ok := uow.references[include]; !ok {
+			return fmt.Errorf("%w: unknown include %q for %s", uowerrors.ErrInvalidQueryParams, include, uow.collectionName)
+		}
+	}
+	return nil
+}
+
+// lookupStages builds the $lookup (and $unwind for single references) stages
+// that populate the requested includes
+func (uow *UnitOfWork[T]) lookupStages(includes []string) bson.A {
+	stages := bson.A{}
+	for _, include := range includes {
+		ref := uow.references[include]
+
+		foreignField := ref.ForeignField
+		if foreignField == "" {
+			foreignField = "_id"
+		}
+
+		stages = append(stages, bson.M{"$lookup": bson.M{
+			"from":         ref.Collection,
+			"localField":   ref.LocalField,
+			"foreignField": foreignField,
+			"as":           include,
+		}})
+
+		if !ref.Many {
+			stages = append(stages, bson.M{"$unwind": bson.M{
+				"path":                       "$" + include,
+				"preserveNullAndEmptyArrays": true,
+			}})
+		}
+	}
+	return stages
+}
+
+// findAggregate runs the paginated query as an aggregation so references can
+// be populated with $lookup and array fields sorted by a chosen element
+func (uow *UnitOfWork[T]) findAggregate(ctx context.Context, collection *mongo.Collection, filter bson.M, query domain.QueryParams[T]) ([]T, error) {
+	pipeline := uow.aggregatePipeline(filter, query)
+
+	uow.track(opFind)
+	queryCtx := uow.getContext(ctx)
+	cursor, err := collection.Aggregate(queryCtx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find with aggregation: %w", err)
+	}
+	defer cursor.Close(queryCtx)
+
+	var results []T
+	if err := uow.decodeAll(queryCtx, cursor, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode results: %w", err)
+	}
+
+	return results, nil
+}
+
+func (uow *UnitOfWork[T]) aggregatePipeline(filter bson.M, query domain.QueryParams[T]) bson.A {
+	pipeline := bson.A{bson.M{"$match": filter}}
+	pipeline = append(pipeline, sortStages(query.ArraySort, sortFromMap(query.Sort))...)
+	if query.Offset > 0 {
+		pipeline = append(pipeline, bson.M{"$skip": int64(query.Offset)})
+	}
+	if query.Limit > 0 {
+		pipeline = append(pipeline, bson.M{"$limit": int64(query.Limit)})
+	}
+	pipeline = append(pipeline, uow.lookupStages(query.Include)...)
+
+	var zero T
+	if projection := listProjection(zero); projection != nil {
+		pipeline = append(pipeline, bson.M{"$project": projection})
+	}
+	return pipeline
+}
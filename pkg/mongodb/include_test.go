@@ -0,0 +1,84 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+)
+
+type TestPost struct {
+	domain.BaseEntity `bson:",inline"`
+	AuthorID          primitive.ObjectID `bson:"authorId" json:"authorId"`
+	Author            *TestUser          `bson:"author,omitempty" json:"author,omitempty"`
+}
+
+func TestUnitOfWork_UnknownIncludeRejected(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestPost](t, &Config{EnableStats: true})
+	query := domain.QueryParams[*TestPost]{Include: []string{"comments"}}
+
+	_, _, err := uow.FindAllWithPagination(context.Background(), query)
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQueryParams)
+
+	_, _, err = uow.GetTrashedWithPagination(context.Background(), query)
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQueryParams)
+
+	assert.Zero(t, uow.Stats().Total())
+}
+
+func TestUnitOfWork_LookupStages(t *testing.T) {
+	factory, err := NewFactory[*TestPost](NewConfig(),
+		WithReference("author", Reference{Collection: "testusers", LocalField: "authorId"}),
+		WithReference("tags", Reference{Collection: "tags", LocalField: "tagSlugs", ForeignField: "slug", Many: true}),
+	)
+	require.NoError(t, err)
+
+	uow := newOfflineUnitOfWork[*TestPost](t, nil)
+	factory.apply(uow)
+
+	require.NoError(t, uow.validateIncludes([]string{"author", "tags"}))
+	assert.Equal(t, bson.A{
+		bson.M{"$lookup": bson.M{"from": "testusers", "localField": "authorId", "foreignField": "_id", "as": "author"}},
+		bson.M{"$unwind": bson.M{"path": "$author", "preserveNullAndEmptyArrays": true}},
+		bson.M{"$lookup": bson.M{"from": "tags", "localField": "tagSlugs", "foreignField": "slug", "as": "tags"}},
+	}, uow.lookupStages([]string{"author", "tags"}))
+}
+
+func TestUnitOfWork_IncludePopulates_Integration(t *testing.T) {
+	users := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	posts := &UnitOfWork[*TestPost]{
+		client:         users.client,
+		database:       users.database,
+		ctx:            context.Background(),
+		collectionName: getCollectionName(TestPost{}),
+		softDelete:     true,
+		references: map[string]Reference{
+			"author": {Collection: users.collectionName, LocalField: "authorId"},
+		},
+	}
+
+	author, err := users.Insert(ctx, &TestUser{Email: "author@example.com"})
+	require.NoError(t, err)
+
+	_, err = posts.BulkInsert(ctx, []*TestPost{{AuthorID: author.GetID()}, {AuthorID: primitive.NewObjectID()}})
+	require.NoError(t, err)
+
+	results, total, err := posts.FindAllWithPagination(ctx, domain.QueryParams[*TestPost]{
+		Include: []string{"author"},
+		Sort:    domain.SortMap{"_id": domain.SortAsc},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), total)
+	require.Len(t, results, 2)
+	require.NotNil(t, results[0].Author)
+	assert.Equal(t, "author@example.com", results[0].Author.Email)
+	assert.Nil(t, results[1].Author)
+}
@@ -0,0 +1,111 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+)
+
+// IndexSpec declares an index created by EnsureIndexes
+type IndexSpec = persistence.IndexSpec
+
+// ActiveIndex builds an index on field covering only documents that are not
+// soft deleted. Its filter matches the condition every default read adds, so
+// the index serves those reads while staying smaller than a full index.
+func ActiveIndex(field string) IndexSpec {
+	return IndexSpec{
+		Keys:          bson.D{{Key: field, Value: 1}},
+		Name:          field + "_active",
+		PartialFilter: notDeletedFilter(),
+	}
+}
+
+func indexModel(spec IndexSpec) mongo.IndexModel {
+	opts := options.Index()
+	if spec.Name != "" {
+		opts.SetName(spec.Name)
+	}
+	if spec.Unique {
+		opts.SetUnique(true)
+	}
+	if spec.PartialFilter != nil {
+		opts.SetPartialFilterExpression(spec.PartialFilter)
+	}
+	if spec.Background {
+		opts.SetBackground(true)
+	}
+	return mongo.IndexModel{Keys: spec.Keys, Options: opts}
+}
+
+// EnsureIndexes creates the given indexes on the collection, leaving ones that
+// already exist with the same definition untouched, and returns their names
+func (uow *UnitOfWork[T]) EnsureIndexes(ctx context.Context, specs ...IndexSpec) ([]string, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	models := make([]mongo.IndexModel, len(specs))
+	for i, spec := range specs {
+		models[i] = indexModel(spec)
+	}
+
+	names, err := uow.getCollection().Indexes().CreateMany(uow.getContext(ctx), models)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create indexes: %w", err)
+	}
+	return names, nil
+}
+
+// EnsureIndexesAsync runs EnsureIndexes in a goroutine and returns at once,
+// so a rollout is not held up while indexes build on a large collection. The
+// result is delivered on the returned channel, which is then closed. ctx
+// bounds the wait for the build, so it should outlive the request that
+// started it.
+func (uow *UnitOfWork[T]) EnsureIndexesAsync(ctx context.Context, specs ...IndexSpec) <-chan persistence.IndexBuildResult {
+	done := make(chan persistence.IndexBuildResult, 1)
+	go func() {
+		defer close(done)
+		names, err := uow.EnsureIndexes(ctx, specs...)
+		done <- persistence.IndexBuildResult{Names: names, Err: err}
+	}()
+	return done
+}
+
+// CreateUniqueIndex creates a unique index on field, e.g. email, so the server
+// rejects duplicates that an in-app existence check would let through under
+// concurrent writes. Soft-deleted documents are left out of the index, so a
+// deleted entity does not block a new one with the same value; documents
+// without the field count as null, so at most one may omit it.
+func (uow *UnitOfWork[T]) CreateUniqueIndex(ctx context.Context, field string) error {
+	spec := IndexSpec{
+		Keys:   bson.D{{Key: field, Value: 1}},
+		Name:   field + "_unique",
+		Unique: true,
+	}
+	if uow.filtersDeleted() {
+		spec.PartialFilter = uow.excludeDeleted(bson.M{})
+	}
+
+	_, err := uow.EnsureIndexes(ctx, spec)
+	return err
+}
+
+// ErrDuplicateKey is returned, wrapped, when a unique index rejects a write, so
+// callers can check errors.Is(err, mongodb.ErrDuplicateKey). It is the same
+// sentinel as errors.ErrDuplicateKey.
+var ErrDuplicateKey = uowerrors.ErrDuplicateKey
+
+// duplicateKey wraps err as ErrDuplicateKey when a unique index rejected the
+// write, keeping the driver error so mongo.IsDuplicateKeyError still matches
+func duplicateKey(err error) error {
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("%w: %w", uowerrors.ErrDuplicateKey, err)
+	}
+	return err
+}
@@ -0,0 +1,180 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+)
+
+func TestActiveIndex(t *testing.T) {
+	spec := ActiveIndex("email")
+
+	assert.Equal(t, bson.D{{Key: "email", Value: 1}}, spec.Keys)
+	assert.Equal(t, "email_active", spec.Name)
+
+	// The partial filter must be the exact condition reads add, or the query
+	// planner will not consider the index
+	users := newOfflineUnitOfWork[*TestUser](t, nil)
+	assert.Equal(t, bson.M(spec.PartialFilter), users.excludeDeleted(bson.M{}))
+	assert.Equal(t, notDeletedFilter(), bson.M(spec.PartialFilter))
+
+	model := indexModel(spec)
+	assert.Equal(t, "email_active", *model.Options.Name)
+	assert.Equal(t, bson.M{"deletedAt": nil}, model.Options.PartialFilterExpression)
+	assert.Nil(t, model.Options.Unique)
+}
+
+func TestUnitOfWork_EnsureIndexes_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	names, err := uow.EnsureIndexes(ctx,
+		ActiveIndex("email"),
+		IndexSpec{Keys: bson.D{{Key: "age", Value: -1}}, PartialFilter: bson.M{"age": bson.M{"$gte": 18}}},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"email_active", "age_-1"}, names)
+
+	cursor, err := uow.getCollection().Indexes().List(ctx)
+	require.NoError(t, err)
+	var indexes []struct {
+		Name                    string `bson:"name"`
+		PartialFilterExpression bson.M `bson:"partialFilterExpression"`
+	}
+	require.NoError(t, cursor.All(ctx, &indexes))
+
+	filters := map[string]bson.M{}
+	for _, index := range indexes {
+		filters[index.Name] = index.PartialFilterExpression
+	}
+	assert.Equal(t, bson.M{"deletedAt": nil}, filters["email_active"])
+	assert.Equal(t, bson.M{"age": bson.M{"$gte": int32(18)}}, filters["age_-1"])
+
+	// Creating the same indexes again is a no-op
+	_, err = uow.EnsureIndexes(ctx, ActiveIndex("email"))
+	require.NoError(t, err)
+
+	active, err := uow.Insert(ctx, &TestUser{Email: "active@example.com"})
+	require.NoError(t, err)
+	trashed, err := uow.Insert(ctx, &TestUser{Email: "trashed@example.com"})
+	require.NoError(t, err)
+	_, err = uow.SoftDelete(ctx, identifier.New().Equal("_id", trashed.GetID()))
+	require.NoError(t, err)
+
+	found, err := uow.FindOneByIdentifier(ctx, identifier.New().Equal("email", "active@example.com"))
+	require.NoError(t, err)
+	assert.Equal(t, active.GetID(), found.GetID())
+
+	_, err = uow.FindOneByIdentifier(ctx, identifier.New().Equal("email", "trashed@example.com"))
+	assert.Error(t, err)
+}
+
+func TestUnitOfWork_CreateUniqueIndex_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	require.NoError(t, uow.CreateUniqueIndex(ctx, "email"))
+	require.NoError(t, uow.CreateUniqueIndex(ctx, "email"), "creating it again is a no-op")
+
+	_, err := uow.Insert(ctx, &TestUser{Email: "dup@example.com"})
+	require.NoError(t, err)
+	_, err = uow.Insert(ctx, &TestUser{Email: "dup@example.com"})
+	assert.ErrorIs(t, err, uowerrors.ErrDuplicateKey)
+	assert.True(t, mongo.IsDuplicateKeyError(err))
+
+	_, err = uow.BulkInsert(ctx, []*TestUser{{Email: "new@example.com"}, {Email: "dup@example.com"}})
+	assert.ErrorIs(t, err, uowerrors.ErrDuplicateKey)
+
+	_, err = uow.Upsert(ctx, identifier.New().Equal("email", "other@example.com"), &TestUser{Email: "dup@example.com"})
+	assert.ErrorIs(t, err, uowerrors.ErrDuplicateKey)
+}
+
+func TestDuplicateKey(t *testing.T) {
+	writeErr := mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: "E11000 duplicate key error"}}}
+	bulkErr := mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{{WriteError: mongo.WriteError{Code: 11000}}}}
+	commandErr := mongo.CommandError{Code: 11000, Name: "DuplicateKey"}
+
+	for _, err := range []error{writeErr, bulkErr, commandErr} {
+		wrapped := fmt.Errorf("failed to insert: %w", duplicateKey(err))
+		assert.ErrorIs(t, wrapped, uowerrors.ErrDuplicateKey)
+		assert.True(t, uowerrors.IsConstraint(wrapped))
+		assert.True(t, mongo.IsDuplicateKeyError(wrapped), "the driver error stays reachable")
+	}
+
+	other := mongo.CommandError{Code: 50}
+	assert.Equal(t, error(other), duplicateKey(other))
+	assert.NoError(t, duplicateKey(nil))
+}
+
+func TestErrDuplicateKey_Exported(t *testing.T) {
+	err := fmt.Errorf("failed to insert: %w", duplicateKey(mongo.CommandError{Code: 11000}))
+	assert.ErrorIs(t, err, ErrDuplicateKey)
+	assert.Same(t, uowerrors.ErrDuplicateKey, ErrDuplicateKey)
+}
+
+func TestIndexModel_Background(t *testing.T) {
+	model := indexModel(IndexSpec{Keys: bson.D{{Key: "email", Value: 1}}, Background: true})
+	require.NotNil(t, model.Options.Background)
+	assert.True(t, *model.Options.Background)
+
+	model = indexModel(IndexSpec{Keys: bson.D{{Key: "email", Value: 1}}})
+	assert.Nil(t, model.Options.Background)
+}
+
+func TestUnitOfWork_EnsureIndexesAsync_ReportsFailure(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+
+	// Server selection against the offline client takes 50ms to fail, so a
+	// result can only be pending when the call returns
+	done := uow.EnsureIndexesAsync(context.Background(), ActiveIndex("email"))
+	select {
+	case <-done:
+		t.Fatal("EnsureIndexesAsync blocked until the build finished")
+	default:
+	}
+
+	result := <-done
+	assert.Error(t, result.Err)
+	assert.Empty(t, result.Names)
+	_, open := <-done
+	assert.False(t, open)
+}
+
+func TestUnitOfWork_EnsureIndexesAsync_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	users := make([]*TestUser, 500)
+	for i := range users {
+		users[i] = &TestUser{Email: fmt.Sprintf("user%d@example.com", i), Age: i % 90}
+	}
+	_, err := uow.BulkInsert(ctx, users)
+	require.NoError(t, err)
+
+	done := uow.EnsureIndexesAsync(ctx, IndexSpec{Keys: bson.D{{Key: "age", Value: 1}}, Name: "age_async", Background: true})
+
+	select {
+	case result := <-done:
+		require.NoError(t, result.Err)
+		assert.Equal(t, []string{"age_async"}, result.Names)
+	case <-time.After(10 * time.Second):
+		t.Fatal("index build did not complete")
+	}
+
+	specs, err := uow.getCollection().Indexes().ListSpecifications(ctx)
+	require.NoError(t, err)
+	var names []string
+	for _, spec := range specs {
+		names = append(names, spec.Name)
+	}
+	assert.Contains(t, names, "age_async")
+}
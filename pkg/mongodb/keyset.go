@@ -0,0 +1,202 @@
+package mongodb
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+)
+
+const (
+	defaultKeysetLimit = 10
+	maxKeysetLimit     = 1000
+)
+
+// pageDirection tells whether a cursor continues after or before its position
+type pageDirection string
+
+const (
+	pageNext pageDirection = "next"
+	pagePrev pageDirection = "prev"
+)
+
+// keysetCursor is the position of a document in a keyset ordering
+type keysetCursor struct {
+	Field     string             `bson:"f"`
+	Value     interface{}        `bson:"v"`
+	ID        primitive.ObjectID `bson:"i"`
+	Direction pageDirection      `bson:"d"`
+}
+
+func encodeCursor(c keysetCursor) (string, error) {
+	data, err := bson.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(token string) (keysetCursor, error) {
+	var c keysetCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, fmt.Errorf("%w: malformed cursor", uowerrors.ErrInvalidQueryParams)
+	}
+	if err := bson.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("%w: malformed cursor", uowerrors.ErrInvalidQueryParams)
+	}
+	if c.Direction != pageNext && c.Direction != pagePrev {
+		return c, fmt.Errorf("%w: malformed cursor", uowerrors.ErrInvalidQueryParams)
+	}
+	return c, nil
+}
+
+// keysetFilter matches the documents strictly after position in an ordering
+// by field and then _id, ascending unless descending is set
+func keysetFilter(field string, value interface{}, id primitive.ObjectID, descending bool) bson.M {
+	op := "$gt"
+	if descending {
+		op = "$lt"
+	}
+
+	if field == "_id" {
+		return bson.M{"_id": bson.M{op: id}}
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{field: bson.M{op: value}},
+		bson.M{field: value, "_id": bson.M{op: id}},
+	}}
+}
+
+// keysetSort orders by field and then _id in one direction
+func keysetSort(field string, descending bool) bson.D {
+	direction := 1
+	if descending {
+		direction = -1
+	}
+
+	if field == "_id" {
+		return bson.D{{Key: "_id", Value: direction}}
+	}
+	return bson.D{{Key: field, Value: direction}, {Key: "_id", Value: direction}}
+}
+
+func (uow *UnitOfWork[T]) FindAllWithCursor(ctx context.Context, query domain.KeysetParams[T]) (persistence.CursorPage[T], error) {
+	var page persistence.CursorPage[T]
+
+	field := query.SortField
+	if field == "" {
+		field = "_id"
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultKeysetLimit
+	}
+	if limit > maxKeysetLimit {
+		limit = maxKeysetLimit
+	}
+
+	var position *keysetCursor
+	if query.Cursor != "" {
+		c, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return page, err
+		}
+		if c.Field != field {
+			return page, fmt.Errorf("%w: cursor is for field %q, not %q", uowerrors.ErrInvalidQueryParams, c.Field, field)
+		}
+		position = &c
+	}
+
+	backward := position != nil && position.Direction == pagePrev
+	descending := (query.Direction == domain.SortDesc) != backward
+
+	filter := uow.excludeDeleted(bson.M{})
+	if !isZeroValue(query.Filter) {
+		for k, v := range uow.buildFilterFromModel(query.Filter) {
+			filter[k] = v
+		}
+	}
+	if position != nil {
+		filter = bson.M{"$and": bson.A{filter, keysetFilter(field, position.Value, position.ID, descending)}}
+	}
+
+	opts := options.Find().
+		SetSort(keysetSort(field, descending)).
+		SetLimit(int64(limit + 1))
+	var zero T
+	if projection := listProjection(zero, field); projection != nil {
+		opts.SetProjection(projection)
+	}
+
+	uow.track(opFind)
+	queryCtx := uow.getContext(ctx)
+	cursor, err := uow.getCollection().Find(queryCtx, filter, opts)
+	if err != nil {
+		return page, fmt.Errorf("failed to find with cursor: %w", err)
+	}
+	defer cursor.Close(queryCtx)
+
+	var items []T
+	if err := uow.decodeAll(queryCtx, cursor, &items); err != nil {
+		return page, fmt.Errorf("failed to decode results: %w", err)
+	}
+
+	page.HasMore = len(items) > limit
+	if page.HasMore {
+		items = items[:limit]
+	}
+	if backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	page.Items = items
+
+	if len(items) == 0 {
+		return page, nil
+	}
+
+	// Going forward there is a previous page whenever we started from a
+	// cursor; going backward there is always a next page
+	if (backward && page.HasMore) || (!backward && position != nil) {
+		if page.PrevCursor, err = uow.cursorFor(items[0], field, pagePrev); err != nil {
+			return page, err
+		}
+	}
+	if backward || page.HasMore {
+		if page.NextCursor, err = uow.cursorFor(items[len(items)-1], field, pageNext); err != nil {
+			return page, err
+		}
+	}
+
+	return page, nil
+}
+
+// cursorFor encodes the position of entity in the ordering by field, reading
+// the sort value as the client registry stores it
+func (uow *UnitOfWork[T]) cursorFor(entity T, field string, direction pageDirection) (string, error) {
+	c := keysetCursor{Field: field, ID: entity.GetID(), Direction: direction}
+
+	if field != "_id" {
+		doc, err := uow.marshalPlain(entity)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode cursor: %w", err)
+		}
+		if value, err := doc.LookupErr(strings.Split(field, ".")...); err == nil {
+			c.Value = value
+		}
+	}
+
+	return encodeCursor(c)
+}
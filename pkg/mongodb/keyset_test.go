@@ -0,0 +1,124 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	id := primitive.NewObjectID()
+	createdAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	token, err := encodeCursor(keysetCursor{Field: "createdAt", Value: createdAt, ID: id, Direction: pagePrev})
+	require.NoError(t, err)
+	assert.NotContains(t, token, "createdAt", "cursor should be opaque")
+
+	c, err := decodeCursor(token)
+	require.NoError(t, err)
+	assert.Equal(t, "createdAt", c.Field)
+	assert.Equal(t, primitive.NewDateTimeFromTime(createdAt), c.Value)
+	assert.Equal(t, id, c.ID)
+	assert.Equal(t, pagePrev, c.Direction)
+}
+
+func TestCursor_Invalid(t *testing.T) {
+	sideways, err := encodeCursor(keysetCursor{Field: "_id", Direction: "sideways"})
+	require.NoError(t, err)
+
+	for _, token := range []string{"not base64!", "aGVsbG8", sideways} {
+		_, err := decodeCursor(token)
+		assert.ErrorIs(t, err, uowerrors.ErrInvalidQueryParams, token)
+	}
+}
+
+func TestKeysetFilter(t *testing.T) {
+	id := primitive.NewObjectID()
+
+	assert.Equal(t, bson.M{"_id": bson.M{"$lt": id}}, keysetFilter("_id", nil, id, true))
+	assert.Equal(t, bson.M{"$or": bson.A{
+		bson.M{"age": bson.M{"$gt": 30}},
+		bson.M{"age": 30, "_id": bson.M{"$gt": id}},
+	}}, keysetFilter("age", 30, id, false))
+
+	assert.Equal(t, bson.D{{Key: "age", Value: -1}, {Key: "_id", Value: -1}}, keysetSort("age", true))
+}
+
+func TestUnitOfWork_FindAllWithCursor_InvalidCursor(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, &Config{EnableStats: true})
+	ctx := context.Background()
+
+	_, err := uow.FindAllWithCursor(ctx, domain.KeysetParams[*TestUser]{Cursor: "garbage"})
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQueryParams)
+
+	token, err := encodeCursor(keysetCursor{Field: "email", Direction: pageNext})
+	require.NoError(t, err)
+	_, err = uow.FindAllWithCursor(ctx, domain.KeysetParams[*TestUser]{SortField: "age", Cursor: token})
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQueryParams)
+
+	assert.Zero(t, uow.Stats().Total())
+}
+
+func TestUnitOfWork_FindAllWithCursor_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	// Ages repeat so paging has to break ties on _id
+	for i, age := range []int{20, 30, 30, 30, 40} {
+		_, err := uow.Insert(ctx, &TestUser{Email: string(rune('a'+i)) + "@example.com", Age: age})
+		require.NoError(t, err)
+	}
+
+	query := domain.KeysetParams[*TestUser]{SortField: "age", Direction: domain.SortDesc, Limit: 2}
+	emails := func(page persistence.CursorPage[*TestUser]) []string {
+		result := make([]string, len(page.Items))
+		for i, user := range page.Items {
+			result[i] = user.Email
+		}
+		return result
+	}
+
+	first, err := uow.FindAllWithCursor(ctx, query)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"e@example.com", "d@example.com"}, emails(first))
+	assert.True(t, first.HasMore)
+	assert.Empty(t, first.PrevCursor)
+	require.NotEmpty(t, first.NextCursor)
+
+	query.Cursor = first.NextCursor
+	second, err := uow.FindAllWithCursor(ctx, query)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c@example.com", "b@example.com"}, emails(second))
+	assert.True(t, second.HasMore)
+	require.NotEmpty(t, second.PrevCursor)
+
+	query.Cursor = second.NextCursor
+	last, err := uow.FindAllWithCursor(ctx, query)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a@example.com"}, emails(last))
+	assert.False(t, last.HasMore)
+	assert.Empty(t, last.NextCursor)
+
+	query.Cursor = last.PrevCursor
+	back, err := uow.FindAllWithCursor(ctx, query)
+	require.NoError(t, err)
+	assert.Equal(t, emails(second), emails(back))
+	assert.True(t, back.HasMore)
+
+	query.Cursor = back.PrevCursor
+	start, err := uow.FindAllWithCursor(ctx, query)
+	require.NoError(t, err)
+	assert.Equal(t, emails(first), emails(start))
+	assert.False(t, start.HasMore)
+	assert.Empty(t, start.PrevCursor)
+	assert.NotEmpty(t, start.NextCursor)
+}
@@ -0,0 +1,165 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+)
+
+const (
+	defaultLoaderWait     = 2 * time.Millisecond
+	defaultLoaderMaxBatch = 1000
+)
+
+// BatchFinder loads several entities by ID in one query. Both IBaseRepository
+// and IUnitOfWork satisfy it.
+type BatchFinder[T persistence.ModelConstraint] interface {
+	FindByIds(ctx context.Context, ids []primitive.ObjectID) ([]T, error)
+}
+
+// Loader batches Load calls made within a short window into one FindByIds
+// query, dataloader style. Create one per request so results are not shared
+// across requests; a Loader does not cache between batches.
+type Loader[T persistence.ModelConstraint] struct {
+	source   BatchFinder[T]
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	pending *loaderBatch[T]
+}
+
+// LoaderOption customizes a Loader
+type LoaderOption func(*loaderSettings)
+
+type loaderSettings struct {
+	wait     time.Duration
+	maxBatch int
+}
+
+// WithLoaderWait sets how long a batch collects IDs before it is queried
+func WithLoaderWait(wait time.Duration) LoaderOption {
+	return func(s *loaderSettings) {
+		s.wait = wait
+	}
+}
+
+// WithLoaderMaxBatch dispatches a batch early once it holds max distinct IDs
+func WithLoaderMaxBatch(max int) LoaderOption {
+	return func(s *loaderSettings) {
+		s.maxBatch = max
+	}
+}
+
+type loaderBatch[T persistence.ModelConstraint] struct {
+	ctx     context.Context
+	ids     []primitive.ObjectID
+	seen    map[primitive.ObjectID]bool
+	timer   *time.Timer
+	done    chan struct{}
+	results map[primitive.ObjectID]T
+	err     error
+}
+
+// NewLoader creates a Loader resolving IDs through source
+func NewLoader[T persistence.ModelConstraint](source BatchFinder[T], opts ...LoaderOption) *Loader[T] {
+	settings := loaderSettings{
+		wait:     defaultLoaderWait,
+		maxBatch: defaultLoaderMaxBatch,
+	}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	return &Loader[T]{
+		source:   source,
+		wait:     settings.wait,
+		maxBatch: settings.maxBatch,
+	}
+}
+
+// Load returns the entity with id, waiting for the batch it joins to be
+// queried. The batch runs with the values of its first Load's context but not
+// its cancellation, so a caller giving up never fails the others sharing the
+// batch. A missing or soft-deleted entity yields ErrEntityNotFound.
+func (l *Loader[T]) Load(ctx context.Context, id primitive.ObjectID) (T, error) {
+	var zero T
+
+	l.mu.Lock()
+	batch := l.pending
+	if batch == nil {
+		batch = &loaderBatch[T]{
+			ctx:  context.WithoutCancel(ctx),
+			seen: make(map[primitive.ObjectID]bool),
+			done: make(chan struct{}),
+		}
+		batch.timer = time.AfterFunc(l.wait, func() { l.dispatch(batch) })
+		l.pending = batch
+	}
+	if !batch.seen[id] {
+		batch.seen[id] = true
+		batch.ids = append(batch.ids, id)
+	}
+	full := l.maxBatch > 0 && len(batch.ids) >= l.maxBatch
+	l.mu.Unlock()
+
+	if full {
+		l.dispatch(batch)
+	}
+
+	select {
+	case <-batch.done:
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+
+	if batch.err != nil {
+		return zero, batch.err
+	}
+	entity, ok := batch.results[id]
+	if !ok {
+		return zero, fmt.Errorf("%w: %s", uowerrors.ErrEntityNotFound, id.Hex())
+	}
+	return entity, nil
+}
+
+// Flush queries the pending batch now instead of waiting for the window
+func (l *Loader[T]) Flush() {
+	l.mu.Lock()
+	batch := l.pending
+	l.mu.Unlock()
+
+	if batch != nil {
+		l.dispatch(batch)
+	}
+}
+
+// dispatch runs batch unless the timer, Flush or a full batch already did
+func (l *Loader[T]) dispatch(batch *loaderBatch[T]) {
+	l.mu.Lock()
+	if l.pending != batch {
+		l.mu.Unlock()
+		return
+	}
+	l.pending = nil
+	l.mu.Unlock()
+
+	batch.timer.Stop()
+
+	entities, err := l.source.FindByIds(batch.ctx, batch.ids)
+	if err != nil {
+		batch.err = fmt.Errorf("failed to load batch: %w", err)
+	} else {
+		batch.results = make(map[primitive.ObjectID]T, len(entities))
+		for _, entity := range entities {
+			batch.results[entity.GetID()] = entity
+		}
+	}
+	close(batch.done)
+}
@@ -0,0 +1,219 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+)
+
+// fakeBatchFinder records every FindByIds call
+type fakeBatchFinder struct {
+	mu      sync.Mutex
+	users   map[primitive.ObjectID]*TestUser
+	batches [][]primitive.ObjectID
+	err     error
+}
+
+func (f *fakeBatchFinder) FindByIds(ctx context.Context, ids []primitive.ObjectID) ([]*TestUser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.batches = append(f.batches, ids)
+	if f.err != nil {
+		return nil, f.err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var users []*TestUser
+	for _, id := range ids {
+		if user, ok := f.users[id]; ok {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func newFakeBatchFinder(count int) (*fakeBatchFinder, []primitive.ObjectID) {
+	finder := &fakeBatchFinder{users: make(map[primitive.ObjectID]*TestUser)}
+	ids := make([]primitive.ObjectID, count)
+	for i := range ids {
+		ids[i] = primitive.NewObjectID()
+		user := &TestUser{}
+		user.SetID(ids[i])
+		finder.users[ids[i]] = user
+	}
+	return finder, ids
+}
+
+// loadAll calls Load concurrently for every id and returns the results in order
+func loadAll(loader *Loader[*TestUser], ids []primitive.ObjectID) ([]*TestUser, []error) {
+	users := make([]*TestUser, len(ids))
+	errs := make([]error, len(ids))
+
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id primitive.ObjectID) {
+			defer wg.Done()
+			users[i], errs[i] = loader.Load(context.Background(), id)
+		}(i, id)
+	}
+	wg.Wait()
+	return users, errs
+}
+
+func TestLoader_CollapsesLoadsIntoOneQuery(t *testing.T) {
+	finder, ids := newFakeBatchFinder(20)
+	loader := NewLoader[*TestUser](finder, WithLoaderWait(20*time.Millisecond))
+
+	// Request every ID twice; duplicates share one slot in the batch
+	requested := append(append([]primitive.ObjectID{}, ids...), ids...)
+	users, errs := loadAll(loader, requested)
+
+	for i, id := range requested {
+		require.NoError(t, errs[i])
+		assert.Equal(t, id, users[i].GetID())
+	}
+	require.Len(t, finder.batches, 1)
+	assert.ElementsMatch(t, ids, finder.batches[0])
+}
+
+func TestLoader_MissingID(t *testing.T) {
+	finder, ids := newFakeBatchFinder(1)
+	loader := NewLoader[*TestUser](finder)
+
+	missing := primitive.NewObjectID()
+	users, errs := loadAll(loader, []primitive.ObjectID{ids[0], missing})
+
+	require.NoError(t, errs[0])
+	assert.Equal(t, ids[0], users[0].GetID())
+	assert.ErrorIs(t, errs[1], uowerrors.ErrEntityNotFound)
+	assert.Nil(t, users[1])
+}
+
+func TestLoader_ErrorReachesEveryCaller(t *testing.T) {
+	finder, ids := newFakeBatchFinder(3)
+	finder.err = errors.New("connection reset")
+	loader := NewLoader[*TestUser](finder)
+
+	_, errs := loadAll(loader, ids)
+	for _, err := range errs {
+		assert.ErrorIs(t, err, finder.err)
+	}
+	assert.Len(t, finder.batches, 1)
+}
+
+func TestLoader_MaxBatch(t *testing.T) {
+	finder, ids := newFakeBatchFinder(10)
+	loader := NewLoader[*TestUser](finder, WithLoaderWait(time.Hour), WithLoaderMaxBatch(5))
+
+	_, errs := loadAll(loader, ids)
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	require.Len(t, finder.batches, 2)
+	assert.Len(t, finder.batches[0], 5)
+	assert.Len(t, finder.batches[1], 5)
+}
+
+func TestLoader_Flush(t *testing.T) {
+	finder, ids := newFakeBatchFinder(1)
+	loader := NewLoader[*TestUser](finder, WithLoaderWait(time.Hour))
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := loader.Load(context.Background(), ids[0])
+		result <- err
+	}()
+
+	require.Eventually(t, func() bool {
+		loader.mu.Lock()
+		defer loader.mu.Unlock()
+		return loader.pending != nil
+	}, time.Second, time.Millisecond)
+	loader.Flush()
+
+	select {
+	case err := <-result:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Flush did not dispatch the pending batch")
+	}
+	assert.Len(t, finder.batches, 1)
+}
+
+func TestLoader_ContextCanceled(t *testing.T) {
+	finder, ids := newFakeBatchFinder(1)
+	loader := NewLoader[*TestUser](finder, WithLoaderWait(time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := loader.Load(ctx, ids[0])
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestLoader_FirstCallerCanceled(t *testing.T) {
+	finder, ids := newFakeBatchFinder(2)
+	loader := NewLoader[*TestUser](finder, WithLoaderWait(time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := loader.Load(ctx, ids[0])
+		first <- err
+	}()
+	require.Eventually(t, func() bool {
+		loader.mu.Lock()
+		defer loader.mu.Unlock()
+		return loader.pending != nil
+	}, time.Second, time.Millisecond)
+
+	second := make(chan error, 1)
+	go func() {
+		_, err := loader.Load(context.Background(), ids[1])
+		second <- err
+	}()
+	require.Eventually(t, func() bool {
+		loader.mu.Lock()
+		defer loader.mu.Unlock()
+		return len(loader.pending.ids) == 2
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-first, context.Canceled)
+
+	loader.Flush()
+	assert.NoError(t, <-second, "the batch outlives the caller that opened it")
+}
+
+func TestLoader_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	var ids []primitive.ObjectID
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		user, err := uow.Insert(ctx, &TestUser{Email: email})
+		require.NoError(t, err)
+		ids = append(ids, user.GetID())
+	}
+
+	uow.stats = &operationCounters{}
+	users, errs := loadAll(NewLoader[*TestUser](uow, WithLoaderWait(20*time.Millisecond)), ids)
+
+	for i, id := range ids {
+		require.NoError(t, errs[i])
+		assert.Equal(t, id, users[i].GetID())
+	}
+	assert.Equal(t, int64(1), uow.Stats().Finds)
+}
@@ -0,0 +1,38 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+)
+
+// FindAllMapped finds the entities matching id and converts each one with fn
+// as it is decoded, e.g. into API models, without building an intermediate
+// entity slice. Soft-deleted entities are excluded unless id filters on
+// deletedAt.
+func FindAllMapped[T persistence.ModelConstraint, R any](ctx context.Context, uow *UnitOfWork[T], id identifier.IIdentifier, fn func(T) R) ([]R, error) {
+	filter := id.ToBSON()
+	if !id.Has("deletedAt") {
+		filter = uow.excludeDeleted(filter)
+	}
+
+	uow.track(opFind)
+	queryCtx := uow.getContext(ctx)
+	cursor, err := uow.getCollection().Find(queryCtx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find all: %w", err)
+	}
+	defer cursor.Close(queryCtx)
+
+	results := make([]R, 0, cursor.RemainingBatchLength())
+	err = uow.decodeEach(queryCtx, cursor, func(entity T) {
+		results = append(results, fn(entity))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode results: %w", err)
+	}
+
+	return results, nil
+}
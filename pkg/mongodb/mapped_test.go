@@ -0,0 +1,76 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+)
+
+type userDTO struct {
+	ID    string
+	Label string
+}
+
+func toUserDTO(user *TestUser) userDTO {
+	return userDTO{ID: user.GetID().Hex(), Label: user.Name + " <" + user.Email + ">"}
+}
+
+func TestUnitOfWork_DecodeEach(t *testing.T) {
+	ctx := context.Background()
+	docs := []interface{}{
+		bson.M{"name": "Ann", "email": "ann@example.com"},
+		bson.M{"name": "Bob", "email": "bob@example.com"},
+	}
+
+	for _, strict := range []bool{false, true} {
+		uow := newOfflineUnitOfWork[*TestUser](t, nil)
+		uow.strictDecoding = strict
+
+		cursor, err := mongo.NewCursorFromDocuments(docs, nil, nil)
+		require.NoError(t, err)
+
+		var labels []string
+		require.NoError(t, uow.decodeEach(ctx, cursor, func(user *TestUser) {
+			labels = append(labels, toUserDTO(user).Label)
+		}))
+		assert.Equal(t, []string{"Ann <ann@example.com>", "Bob <bob@example.com>"}, labels)
+	}
+}
+
+func TestUnitOfWork_DecodeEach_Strict(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+	uow.strictDecoding = true
+
+	cursor, err := mongo.NewCursorFromDocuments([]interface{}{bson.M{"sku": "X-1"}}, nil, nil)
+	require.NoError(t, err)
+
+	err = uow.decodeEach(context.Background(), cursor, func(*TestUser) {
+		t.Fatal("mismatched document must not reach fn")
+	})
+	assert.Error(t, err)
+}
+
+func TestFindAllMapped_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	ann, err := uow.Insert(ctx, &TestUser{BaseEntity: domain.BaseEntity{Name: "Ann"}, Email: "ann@example.com", Age: 30})
+	require.NoError(t, err)
+	_, err = uow.Insert(ctx, &TestUser{BaseEntity: domain.BaseEntity{Name: "Bob"}, Email: "bob@example.com", Age: 40})
+	require.NoError(t, err)
+
+	dtos, err := FindAllMapped(ctx, uow, identifier.New().Equal("age", 30), toUserDTO)
+	require.NoError(t, err)
+	assert.Equal(t, []userDTO{{ID: ann.GetID().Hex(), Label: "Ann <ann@example.com>"}}, dtos)
+
+	none, err := FindAllMapped(ctx, uow, identifier.New().Equal("age", 99), toUserDTO)
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
@@ -0,0 +1,84 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MergeOption customizes the $merge stage appended by AggregateMerge
+type MergeOption func(*mergeSettings)
+
+type mergeSettings struct {
+	on             []string
+	whenMatched    interface{}
+	whenNotMatched string
+}
+
+// MergeOn sets the fields identifying a target document; they must be backed
+// by a unique index on the target. The default is _id.
+func MergeOn(fields ...string) MergeOption {
+	return func(s *mergeSettings) {
+		s.on = fields
+	}
+}
+
+// MergeWhenMatched sets the action for results matching a target document:
+// "replace", "keepExisting", "merge" (the default), "fail", or an update
+// pipeline given as mongo.Pipeline
+func MergeWhenMatched(action interface{}) MergeOption {
+	return func(s *mergeSettings) {
+		s.whenMatched = action
+	}
+}
+
+// MergeWhenNotMatched sets the action for results matching no target document:
+// "insert" (the default), "discard" or "fail"
+func MergeWhenNotMatched(action string) MergeOption {
+	return func(s *mergeSettings) {
+		s.whenNotMatched = action
+	}
+}
+
+// mergeStage builds the $merge stage writing into target in the same database
+func mergeStage(database, target string, opts ...MergeOption) bson.D {
+	var settings mergeSettings
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	merge := bson.D{{Key: "into", Value: bson.D{
+		{Key: "db", Value: database},
+		{Key: "coll", Value: target},
+	}}}
+	if len(settings.on) == 1 {
+		merge = append(merge, bson.E{Key: "on", Value: settings.on[0]})
+	} else if len(settings.on) > 1 {
+		merge = append(merge, bson.E{Key: "on", Value: settings.on})
+	}
+	if settings.whenMatched != nil {
+		merge = append(merge, bson.E{Key: "whenMatched", Value: settings.whenMatched})
+	}
+	if settings.whenNotMatched != "" {
+		merge = append(merge, bson.E{Key: "whenNotMatched", Value: settings.whenNotMatched})
+	}
+
+	return bson.D{{Key: "$merge", Value: merge}}
+}
+
+// AggregateMerge runs pipeline over the collection and writes its output into
+// targetCollection with $merge, e.g. to refresh a materialized rollup.
+// Soft-deleted documents are excluded from the input.
+func (uow *UnitOfWork[T]) AggregateMerge(ctx context.Context, pipeline mongo.Pipeline, targetCollection string, mergeOpts ...MergeOption) error {
+	stages := append(uow.livePipeline(pipeline), mergeStage(uow.database.Name(), targetCollection, mergeOpts...))
+
+	uow.track(opUpdate)
+	queryCtx := uow.getContext(ctx)
+	cursor, err := uow.getCollection().Aggregate(queryCtx, stages)
+	if err != nil {
+		return fmt.Errorf("failed to merge into %s: %w", targetCollection, err)
+	}
+	return cursor.Close(queryCtx)
+}
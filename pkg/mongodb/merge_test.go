@@ -0,0 +1,75 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+)
+
+func TestMergeStage(t *testing.T) {
+	assert.Equal(t, bson.D{{Key: "$merge", Value: bson.D{
+		{Key: "into", Value: bson.D{{Key: "db", Value: "shop"}, {Key: "coll", Value: "rollup"}}},
+	}}}, mergeStage("shop", "rollup"))
+
+	assert.Equal(t, bson.D{{Key: "$merge", Value: bson.D{
+		{Key: "into", Value: bson.D{{Key: "db", Value: "shop"}, {Key: "coll", Value: "rollup"}}},
+		{Key: "on", Value: []string{"category", "day"}},
+		{Key: "whenMatched", Value: "replace"},
+		{Key: "whenNotMatched", Value: "discard"},
+	}}}, mergeStage("shop", "rollup",
+		MergeOn("category", "day"),
+		MergeWhenMatched("replace"),
+		MergeWhenNotMatched("discard"),
+	))
+
+	on := mergeStage("shop", "rollup", MergeOn("category"))[0].Value.(bson.D)[1]
+	assert.Equal(t, bson.E{Key: "on", Value: "category"}, on)
+}
+
+func TestUnitOfWork_AggregateMerge_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*persistence.Product](t)
+	ctx := context.Background()
+
+	_, err := uow.BulkInsert(ctx, []*persistence.Product{
+		{BaseEntity: domain.BaseEntity{Name: "Laptop"}, Category: "electronics", Price: 1000},
+		{BaseEntity: domain.BaseEntity{Name: "Phone"}, Category: "electronics", Price: 500},
+		{BaseEntity: domain.BaseEntity{Name: "Desk"}, Category: "furniture", Price: 200},
+		{BaseEntity: domain.BaseEntity{Name: "Old Chair"}, Category: "furniture", Price: 50},
+	})
+	require.NoError(t, err)
+	_, err = uow.SoftDelete(ctx, identifier.New().Equal("name", "Old Chair"))
+	require.NoError(t, err)
+
+	rollup := mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$category"},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "total", Value: bson.D{{Key: "$sum", Value: "$price"}}},
+		}}},
+	}
+	require.NoError(t, uow.AggregateMerge(ctx, rollup, "category_rollup", MergeWhenMatched("replace")))
+
+	type categoryTotal struct {
+		Category string  `bson:"_id"`
+		Count    int     `bson:"count"`
+		Total    float64 `bson:"total"`
+	}
+	cursor, err := uow.database.Collection("category_rollup").Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	require.NoError(t, err)
+	var totals []categoryTotal
+	require.NoError(t, cursor.All(ctx, &totals))
+
+	assert.Equal(t, []categoryTotal{
+		{Category: "electronics", Count: 2, Total: 1500},
+		{Category: "furniture", Count: 1, Total: 200},
+	}, totals)
+}
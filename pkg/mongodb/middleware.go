@@ -0,0 +1,136 @@
+package mongodb
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+)
+
+// unitOfWorkKey is the context key for the unit of work of T, so units of work
+// for different entities can share one request context
+type unitOfWorkKey[T persistence.ModelConstraint] struct{}
+
+// ContextWithUnitOfWork returns a copy of ctx carrying uow
+func ContextWithUnitOfWork[T persistence.ModelConstraint](ctx context.Context, uow persistence.IUnitOfWork[T]) context.Context {
+	return context.WithValue(ctx, unitOfWorkKey[T]{}, uow)
+}
+
+// UnitOfWorkFromContext returns the unit of work stored by Middleware or
+// TransactionMiddleware and whether one was found
+func UnitOfWorkFromContext[T persistence.ModelConstraint](ctx context.Context) (persistence.IUnitOfWork[T], bool) {
+	uow, ok := ctx.Value(unitOfWorkKey[T]{}).(persistence.IUnitOfWork[T])
+	return uow, ok
+}
+
+// Middleware stores a unit of work created by factory in the context of each
+// request. Requests fail with 503 when the factory cannot connect to MongoDB.
+func Middleware[T persistence.ModelConstraint](factory persistence.IUnitOfWorkFactory[T]) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			uow, err := createUnitOfWork(r.Context(), factory)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(ContextWithUnitOfWork(r.Context(), uow)))
+		})
+	}
+}
+
+// TransactionMiddleware runs each request in a transaction on a unit of work
+// stored in the request context. The transaction is committed when the
+// handler writes a status below 400, so a failed commit can still be reported
+// as 500, and rolled back on any other status or a panic. Like Middleware, it
+// responds 503 when the factory cannot connect.
+func TransactionMiddleware[T persistence.ModelConstraint](factory persistence.IUnitOfWorkFactory[T]) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			uow, err := createUnitOfWork(ctx, factory)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+				return
+			}
+			if err := uow.BeginTransaction(ctx); err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+
+			tw := &transactionWriter[T]{ResponseWriter: w, ctx: ctx, uow: uow}
+			defer func() {
+				if p := recover(); p != nil {
+					tw.finish(http.StatusInternalServerError)
+					panic(p)
+				}
+				tw.WriteHeader(http.StatusOK)
+			}()
+
+			next.ServeHTTP(tw, r.WithContext(ContextWithUnitOfWork[T](ctx, uow)))
+		})
+	}
+}
+
+// createUnitOfWork returns a unit of work from factory, or the ErrNotConnected
+// error held by the disconnected one Create returns when MongoDB is
+// unreachable
+func createUnitOfWork[T persistence.ModelConstraint](ctx context.Context, factory persistence.IUnitOfWorkFactory[T]) (persistence.IUnitOfWork[T], error) {
+	uow := factory.CreateWithContext(ctx)
+	if disconnected, ok := uow.(*disconnectedUnitOfWork[T]); ok {
+		return nil, disconnected.err
+	}
+	return uow, nil
+}
+
+// transactionWriter ends the transaction of a request when its status is
+// written
+type transactionWriter[T persistence.ModelConstraint] struct {
+	http.ResponseWriter
+	ctx         context.Context
+	uow         persistence.IUnitOfWork[T]
+	wroteHeader bool
+	done        bool
+	// failed is set once a failed commit replaced the response with a 500
+	failed bool
+}
+
+// finish commits or rolls back the transaction for status and returns the
+// status to send
+func (w *transactionWriter[T]) finish(status int) int {
+	if w.done {
+		return status
+	}
+	w.done = true
+
+	if status >= http.StatusBadRequest {
+		w.uow.RollbackTransaction(w.ctx)
+		return status
+	}
+	if err := w.uow.CommitTransaction(w.ctx); err != nil {
+		w.uow.RollbackTransaction(w.ctx)
+		return http.StatusInternalServerError
+	}
+	return status
+}
+
+func (w *transactionWriter[T]) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	if sent := w.finish(status); sent != status {
+		w.failed = true
+		http.Error(w.ResponseWriter, http.StatusText(sent), sent)
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *transactionWriter[T]) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	if w.failed {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
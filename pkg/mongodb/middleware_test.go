@@ -0,0 +1,165 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+)
+
+// recordingUnitOfWork records the transaction calls and inserts made through
+// it; every other method panics through the nil embedded interface
+type recordingUnitOfWork struct {
+	persistence.IUnitOfWork[*TestUser]
+	commitErr error
+	calls     []string
+	inserted  []*TestUser
+}
+
+func (u *recordingUnitOfWork) BeginTransaction(context.Context) error {
+	u.calls = append(u.calls, "begin")
+	return nil
+}
+
+func (u *recordingUnitOfWork) CommitTransaction(context.Context) error {
+	u.calls = append(u.calls, "commit")
+	return u.commitErr
+}
+
+func (u *recordingUnitOfWork) RollbackTransaction(context.Context) {
+	u.calls = append(u.calls, "rollback")
+}
+
+func (u *recordingUnitOfWork) Insert(_ context.Context, user *TestUser) (*TestUser, error) {
+	u.inserted = append(u.inserted, user)
+	return user, nil
+}
+
+// stubFactory hands out uow, or a disconnected unit of work like Factory.Create
+// when it is nil
+type stubFactory struct {
+	persistence.IUnitOfWorkFactory[*TestUser]
+	uow *recordingUnitOfWork
+}
+
+func (f *stubFactory) CreateWithContext(context.Context) persistence.IUnitOfWork[*TestUser] {
+	if f.uow == nil {
+		return newDisconnectedUnitOfWork[*TestUser](errors.New("connection refused"))
+	}
+	return f.uow
+}
+
+// insertHandler inserts a user through the request unit of work and responds
+// with status
+func insertHandler(status int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uow, ok := UnitOfWorkFromContext[*TestUser](r.Context())
+		if !ok {
+			http.Error(w, "no unit of work", http.StatusInternalServerError)
+			return
+		}
+		if _, err := uow.Insert(r.Context(), &TestUser{Email: "http@example.com"}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("done"))
+	})
+}
+
+func serve(handler http.Handler) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/users", nil))
+	return rec
+}
+
+func TestUnitOfWorkFromContext(t *testing.T) {
+	_, ok := UnitOfWorkFromContext[*TestUser](context.Background())
+	assert.False(t, ok)
+
+	uow := &recordingUnitOfWork{}
+	ctx := ContextWithUnitOfWork[*TestUser](context.Background(), uow)
+	got, ok := UnitOfWorkFromContext[*TestUser](ctx)
+	require.True(t, ok)
+	assert.Same(t, uow, got)
+
+	_, ok = UnitOfWorkFromContext[*TestPost](ctx)
+	assert.False(t, ok, "units of work are keyed by entity type")
+}
+
+func TestMiddleware(t *testing.T) {
+	uow := &recordingUnitOfWork{}
+	rec := serve(Middleware[*TestUser](&stubFactory{uow: uow})(insertHandler(http.StatusCreated)))
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Len(t, uow.inserted, 1)
+	assert.Empty(t, uow.calls)
+}
+
+func TestMiddleware_CreateFails(t *testing.T) {
+	rec := serve(Middleware[*TestUser](&stubFactory{})(insertHandler(http.StatusCreated)))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	rec = serve(TransactionMiddleware[*TestUser](&stubFactory{})(insertHandler(http.StatusCreated)))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestMiddleware_Unreachable(t *testing.T) {
+	factory, err := NewFactory[*TestUser](unreachableConfig())
+	require.NoError(t, err)
+
+	rec := serve(Middleware[*TestUser](factory)(insertHandler(http.StatusCreated)))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestTransactionMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		commitErr error
+		wantCode  int
+		wantBody  string
+		wantCalls []string
+	}{
+		{"commits on success", http.StatusCreated, nil, http.StatusCreated, "done", []string{"begin", "commit"}},
+		{"rolls back on client error", http.StatusConflict, nil, http.StatusConflict, "done", []string{"begin", "rollback"}},
+		{"reports failed commit", http.StatusCreated, errors.New("write conflict"), http.StatusInternalServerError, "Internal Server Error\n", []string{"begin", "commit", "rollback"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uow := &recordingUnitOfWork{commitErr: tt.commitErr}
+			rec := serve(TransactionMiddleware[*TestUser](&stubFactory{uow: uow})(insertHandler(tt.status)))
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Equal(t, tt.wantBody, rec.Body.String())
+			assert.Equal(t, tt.wantCalls, uow.calls)
+			assert.Len(t, uow.inserted, 1)
+		})
+	}
+}
+
+func TestTransactionMiddleware_ImplicitStatus(t *testing.T) {
+	uow := &recordingUnitOfWork{}
+	handler := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	rec := serve(TransactionMiddleware[*TestUser](&stubFactory{uow: uow})(handler))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"begin", "commit"}, uow.calls)
+}
+
+func TestTransactionMiddleware_Panic(t *testing.T) {
+	uow := &recordingUnitOfWork{}
+	handler := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { panic("boom") })
+
+	assert.PanicsWithValue(t, "boom", func() {
+		serve(TransactionMiddleware[*TestUser](&stubFactory{uow: uow})(handler))
+	})
+	assert.Equal(t, []string{"begin", "rollback"}, uow.calls)
+}
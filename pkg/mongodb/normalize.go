@@ -0,0 +1,54 @@
+package mongodb
+
+import (
+	"reflect"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+)
+
+// normalizedFields returns the fields model declares through
+// domain.NormalizedFields
+func normalizedFields(model interface{}) []string {
+	if normalized, ok := modelInstance(model).(domain.NormalizedFields); ok {
+		return normalized.NormalizedFields()
+	}
+	return nil
+}
+
+// normalizeFields normalizes the declared string fields of entity in place.
+// Declared fields that are missing or not strings are skipped.
+func normalizeFields(entity interface{}) {
+	names := normalizedFields(entity)
+	if len(names) == 0 {
+		return
+	}
+
+	v := reflect.ValueOf(entity)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	v = v.Elem()
+
+	for _, field := range filterFieldsFor(v.Type()) {
+		if !slices.Contains(names, field.name) {
+			continue
+		}
+		value := v.FieldByIndex(field.index)
+		if value.Kind() == reflect.String && value.CanSet() {
+			value.SetString(domain.Normalize(value.String()))
+		}
+	}
+}
+
+// normalizeSet normalizes the string values of the declared fields in a $set
+// document
+func normalizeSet(model interface{}, set bson.M) {
+	for _, name := range normalizedFields(model) {
+		if value, ok := set[name].(string); ok {
+			set[name] = domain.Normalize(value)
+		}
+	}
+}
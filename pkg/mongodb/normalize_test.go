@@ -0,0 +1,83 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/services"
+)
+
+type Account struct {
+	persistence.User `bson:",inline"`
+	Username         string `bson:"username" json:"username"`
+	Nickname         string `bson:"nickname" json:"nickname"`
+	Score            int    `bson:"score" json:"score"`
+}
+
+func (Account) NormalizedFields() []string { return []string{"email", "username", "score"} }
+
+func TestNormalizeFields(t *testing.T) {
+	account := &Account{Username: "  JaneDoe ", Nickname: " Jane ", Score: 7}
+	account.Email = "Jane.Doe@Example.COM"
+
+	normalizeFields(account)
+	assert.Equal(t, "jane.doe@example.com", account.Email, "fields of inline structs are normalized")
+	assert.Equal(t, "janedoe", account.Username)
+	assert.Equal(t, " Jane ", account.Nickname, "undeclared fields are untouched")
+	assert.Equal(t, 7, account.Score, "non-string fields are skipped")
+
+	user := &TestUser{Email: "Mixed@Example.com"}
+	normalizeFields(user)
+	assert.Equal(t, "Mixed@Example.com", user.Email)
+}
+
+func TestUnitOfWork_SetFields_Normalizes(t *testing.T) {
+	uow := newOfflineUnitOfWork[*persistence.User](t, nil)
+
+	set, err := uow.setFields(bson.M{"email": " New@Example.com", "age": 30})
+	require.NoError(t, err)
+	assert.Equal(t, "new@example.com", set["email"])
+	assert.Equal(t, 30, set["age"])
+}
+
+func TestNormalize_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*persistence.User](t)
+	ctx := context.Background()
+
+	inserted, err := uow.Insert(ctx, &persistence.User{Email: " Alice@Example.COM ", Age: 30})
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", inserted.Email)
+
+	var raw bson.M
+	require.NoError(t, uow.getCollection().FindOne(ctx, bson.M{"_id": inserted.GetID()}).Decode(&raw))
+	assert.Equal(t, "alice@example.com", raw["email"], "the email is stored lowercased")
+
+	bulk, err := uow.BulkInsert(ctx, []*persistence.User{{Email: "BOB@example.com"}})
+	require.NoError(t, err)
+	assert.Equal(t, "bob@example.com", bulk[0].Email)
+
+	config := NewConfig()
+	config.Database = uow.database.Name()
+	factory, err := NewFactory[*persistence.User](config)
+	require.NoError(t, err)
+	defer factory.Close(ctx)
+	repo := NewUserRepository(NewBaseRepository[*persistence.User](factory))
+
+	found, err := repo.FindByEmail(ctx, "ALICE@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, inserted.GetID(), found.GetID())
+
+	inserted.Email = "Alice.Smith@Example.com"
+	updated, err := uow.Update(ctx, identifier.New().Equal("_id", inserted.GetID()), inserted)
+	require.NoError(t, err)
+	assert.Equal(t, "alice.smith@example.com", updated.Email)
+
+	_, err = services.NewUserService(repo).CreateUser(ctx, "BOB@EXAMPLE.COM", 40)
+	assert.ErrorContains(t, err, "already exists", "duplicates are detected regardless of case")
+}
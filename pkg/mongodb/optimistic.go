@@ -0,0 +1,103 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+)
+
+// RetryOnConflict reads the live entity with id, applies mutate and writes it
+// back only if nobody else updated it in between, using updatedAt as the
+// version. On a conflict it re-reads and re-applies mutate, giving up with
+// ErrVersionConflict after maxAttempts. An error from mutate aborts without
+// writing.
+func (uow *UnitOfWork[T]) RetryOnConflict(ctx context.Context, id primitive.ObjectID, mutate func(T) error, maxAttempts int) (T, error) {
+	var zero T
+	if maxAttempts < 1 {
+		return zero, fmt.Errorf("%w: maxAttempts must be positive, got %d", uowerrors.ErrInvalidQueryParams, maxAttempts)
+	}
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		entity, err := uow.FindOneById(ctx, id)
+		if err != nil {
+			return zero, err
+		}
+		version := entity.GetUpdatedAt()
+		if err := mutate(entity); err != nil {
+			return zero, err
+		}
+
+		updated, err := uow.updateVersion(ctx, id, version, entity)
+		if errors.Is(err, uowerrors.ErrVersionConflict) {
+			continue
+		}
+		return updated, err
+	}
+	return zero, fmt.Errorf("%w: %s after %d attempts", uowerrors.ErrVersionConflict, id.Hex(), maxAttempts)
+}
+
+// updateVersion writes entity over the live document with id while its
+// updatedAt still equals version, returning ErrVersionConflict otherwise. The
+// write is audited as Update's is.
+func (uow *UnitOfWork[T]) updateVersion(ctx context.Context, id primitive.ObjectID, version time.Time, entity T) (T, error) {
+	var zero T
+	if err := checkEntity(entity); err != nil {
+		return zero, err
+	}
+	if err := uow.appendOnly("update"); err != nil {
+		return zero, err
+	}
+
+	filter := uow.excludeDeleted(bson.M{"_id": id, "updatedAt": version.UTC()})
+
+	entity.SetID(id)
+	uow.setEntityTimestamp(entity, "updatedAt", nextVersion(version))
+	normalizeFields(entity)
+	if _, err := uow.stampContentHash(entity); err != nil {
+		return zero, err
+	}
+
+	returnDocument := options.After
+	if uow.auditSink != nil {
+		returnDocument = options.Before
+	}
+
+	uow.track(opUpdate)
+	result := uow.getCollection().FindOneAndUpdate(
+		uow.getContext(ctx),
+		filter,
+		bson.M{"$set": entity},
+		options.FindOneAndUpdate().SetReturnDocument(returnDocument),
+	)
+
+	var stored T
+	if err := uow.decode(result, &stored); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return zero, uowerrors.ErrVersionConflict
+		}
+		return zero, fmt.Errorf("failed to update: %w", err)
+	}
+	if uow.auditSink != nil {
+		return uow.auditWritten(ctx, stored, entity), nil
+	}
+	return stored, nil
+}
+
+// nextVersion returns the updatedAt to write over version. The server keeps
+// milliseconds, so the result is moved past version when both fall in the
+// same millisecond and the write would otherwise leave the version unchanged.
+func nextVersion(version time.Time) time.Time {
+	next := utcNow().Truncate(time.Millisecond)
+	if !next.After(version) {
+		next = version.UTC().Truncate(time.Millisecond).Add(time.Millisecond)
+	}
+	return next
+}
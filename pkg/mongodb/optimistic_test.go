@@ -0,0 +1,92 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+)
+
+func TestNextVersion(t *testing.T) {
+	past := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	next := nextVersion(past)
+	assert.True(t, next.After(past))
+	assert.Equal(t, next, next.Truncate(time.Millisecond), "versions are stored with millisecond precision")
+
+	// A version in the future, e.g. from clock skew, still moves forward
+	future := utcNow().Add(time.Hour).Truncate(time.Millisecond)
+	assert.Equal(t, future.Add(time.Millisecond), nextVersion(future))
+}
+
+func TestUnitOfWork_RetryOnConflict_InvalidAttempts(t *testing.T) {
+	users := newOfflineUnitOfWork[*TestUser](t, nil)
+
+	_, err := users.RetryOnConflict(context.Background(), primitive.NewObjectID(), func(*TestUser) error { return nil }, 0)
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQueryParams)
+}
+
+func TestUnitOfWork_RetryOnConflict_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	user, err := uow.Insert(ctx, &TestUser{Email: "a@example.com", Age: 30})
+	require.NoError(t, err)
+	byID := identifier.New().Equal("_id", user.GetID())
+
+	attempts := 0
+	updated, err := uow.RetryOnConflict(ctx, user.GetID(), func(user *TestUser) error {
+		attempts++
+		if attempts == 1 {
+			// A concurrent writer lands between the read and the write
+			_, err := uow.UpdateFields(ctx, byID, bson.M{"active": true})
+			require.NoError(t, err)
+		}
+		user.Age++
+		return nil
+	}, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, 31, updated.Age)
+	assert.True(t, updated.Active, "the retry re-reads the concurrent change")
+
+	stored, err := uow.FindOneById(ctx, user.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, 31, stored.Age)
+	assert.True(t, stored.Active)
+}
+
+func TestUnitOfWork_RetryOnConflict_GivesUp_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	user, err := uow.Insert(ctx, &TestUser{Email: "a@example.com", Age: 30})
+	require.NoError(t, err)
+	byID := identifier.New().Equal("_id", user.GetID())
+
+	attempts := 0
+	_, err = uow.RetryOnConflict(ctx, user.GetID(), func(user *TestUser) error {
+		attempts++
+		_, err := uow.UpdateFields(ctx, byID, bson.M{"age": 40 + attempts})
+		require.NoError(t, err)
+		user.Age = 0
+		return nil
+	}, 3)
+	assert.ErrorIs(t, err, uowerrors.ErrVersionConflict)
+	assert.Equal(t, 3, attempts)
+
+	stored, err := uow.FindOneById(ctx, user.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, 43, stored.Age, "no conflicting write was applied")
+
+	failure := errors.New("rejected")
+	_, err = uow.RetryOnConflict(ctx, user.GetID(), func(*TestUser) error { return failure }, 3)
+	assert.ErrorIs(t, err, failure)
+}
@@ -0,0 +1,87 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+)
+
+// warmUpRounds bounds how many rounds of concurrent pings WarmUp sends before
+// giving up on reaching the requested pool size
+const warmUpRounds = 10
+
+// WithPoolMonitor receives the connection pool events of the factory client,
+// e.g. to export pool metrics
+func WithPoolMonitor(monitor *event.PoolMonitor) FactoryOption {
+	return func(s *factorySettings) {
+		s.poolMonitor = monitor
+	}
+}
+
+// poolMonitor counts open connections for WarmUp and forwards every event to
+// the monitor set with WithPoolMonitor
+func (f *Factory[T]) poolMonitor() *event.PoolMonitor {
+	next := f.settings.poolMonitor
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			switch e.Type {
+			case event.ConnectionCreated:
+				f.connections.Add(1)
+			case event.ConnectionClosed:
+				f.connections.Add(-1)
+			}
+			if next != nil && next.Event != nil {
+				next.Event(e)
+			}
+		},
+	}
+}
+
+// WarmUp connects the shared client and opens connections until the pool
+// holds at least n, capped at MaxPoolSize, so the first requests after a
+// cold start do not pay for connection setup. The driver only opens
+// connections on demand, so WarmUp sends concurrent pings until enough are
+// open. Idle connections are still closed after MaxIdleTime.
+func (f *Factory[T]) WarmUp(ctx context.Context, n int) error {
+	if n <= 0 {
+		return nil
+	}
+	if max := f.config.MaxPoolSize; max > 0 && uint64(n) > max {
+		n = int(max)
+	}
+
+	client, err := f.sharedClient()
+	if err != nil {
+		return fmt.Errorf("%w: %w", uowerrors.ErrDatabaseConnection, err)
+	}
+
+	for round := 0; f.connections.Load() < int64(n); round++ {
+		if round == warmUpRounds {
+			return fmt.Errorf("connection pool reached %d of %d connections", f.connections.Load(), n)
+		}
+
+		var wg sync.WaitGroup
+		errs := make(chan error, n)
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := client.Ping(ctx, nil); err != nil {
+					errs <- err
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		if err := <-errs; err != nil {
+			return fmt.Errorf("%w: %w", uowerrors.ErrDatabaseConnection, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package mongodb
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+)
+
+type projectionMode int
+
+const (
+	projectionNeutral projectionMode = iota
+	projectionInclude
+	projectionExclude
+)
+
+// ValidateProjection checks that a projection either only includes or only
+// excludes fields, as MongoDB requires. Excluding _id is allowed in an
+// inclusion projection, and operator documents such as $slice or $elemMatch
+// are accepted in either mode.
+func ValidateProjection(projection interface{}) error {
+	var keys []string
+	var values []interface{}
+
+	switch p := projection.(type) {
+	case nil:
+		return nil
+	case bson.M:
+		for k, v := range p {
+			keys = append(keys, k)
+			values = append(values, v)
+		}
+	case map[string]interface{}:
+		return ValidateProjection(bson.M(p))
+	case bson.D:
+		for _, e := range p {
+			keys = append(keys, e.Key)
+			values = append(values, e.Value)
+		}
+	default:
+		return nil
+	}
+
+	var included, excluded []string
+	for i, key := range keys {
+		mode := projectionModeOf(values[i])
+		if key == "_id" && mode == projectionExclude {
+			continue
+		}
+
+		switch mode {
+		case projectionInclude:
+			included = append(included, key)
+		case projectionExclude:
+			excluded = append(excluded, key)
+		}
+	}
+
+	if len(included) > 0 && len(excluded) > 0 {
+		sort.Strings(included)
+		sort.Strings(excluded)
+		return fmt.Errorf("%w (included: %s; excluded: %s)", uowerrors.ErrInvalidProjection,
+			strings.Join(included, ", "), strings.Join(excluded, ", "))
+	}
+
+	return nil
+}
+
+func projectionModeOf(value interface{}) projectionMode {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return projectionInclude
+		}
+		return projectionExclude
+	case int:
+		return numericProjectionMode(float64(v))
+	case int32:
+		return numericProjectionMode(float64(v))
+	case int64:
+		return numericProjectionMode(float64(v))
+	case float64:
+		return numericProjectionMode(v)
+	case bson.M:
+		for k := range v {
+			if strings.HasPrefix(k, "$") {
+				return projectionNeutral
+			}
+		}
+		return projectionInclude
+	case bson.D:
+		for _, e := range v {
+			if strings.HasPrefix(e.Key, "$") {
+				return projectionNeutral
+			}
+		}
+		return projectionInclude
+	default:
+		return projectionInclude
+	}
+}
+
+func numericProjectionMode(v float64) projectionMode {
+	if v == 0 {
+		return projectionExclude
+	}
+	return projectionInclude
+}
+
+// listProjection excludes the fields a model declares through
+// domain.ListProjected, except those in keep. It returns nil when nothing is
+// excluded.
+func listProjection(model interface{}, keep ...string) bson.D {
+	listed, ok := modelInstance(model).(domain.ListProjected)
+	if !ok {
+		return nil
+	}
+
+	var projection bson.D
+	for _, field := range listed.ListExcludedFields() {
+		if field == "_id" || slices.Contains(keep, field) {
+			continue
+		}
+		projection = append(projection, bson.E{Key: field, Value: 0})
+	}
+	return projection
+}
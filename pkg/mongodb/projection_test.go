@@ -0,0 +1,109 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+)
+
+func TestValidateProjection(t *testing.T) {
+	tests := []struct {
+		name       string
+		projection interface{}
+		wantErr    bool
+	}{
+		{name: "nil", projection: nil},
+		{name: "inclusion", projection: bson.M{"name": 1, "email": 1}},
+		{name: "inclusion excluding _id", projection: bson.M{"name": 1, "_id": 0}},
+		{name: "exclusion", projection: bson.M{"password": 0, "history": false}},
+		{name: "exclusion with _id", projection: bson.D{{Key: "_id", Value: 0}, {Key: "history", Value: 0}}},
+		{name: "inclusion with $slice", projection: bson.M{"name": 1, "tags": bson.M{"$slice": 5}}},
+		{name: "exclusion with $slice", projection: bson.M{"history": 0, "tags": bson.M{"$slice": 5}}},
+		{name: "mixed", projection: bson.M{"name": 1, "email": 0}, wantErr: true},
+		{name: "mixed bool", projection: bson.D{{Key: "name", Value: true}, {Key: "email", Value: false}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateProjection(tt.projection)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, uowerrors.ErrInvalidProjection)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateProjection_ErrorNamesFields(t *testing.T) {
+	err := ValidateProjection(bson.M{"name": 1, "email": 0})
+
+	assert.EqualError(t, err, uowerrors.ErrInvalidProjection.Error()+" (included: name; excluded: email)")
+}
+
+func TestUnitOfWork_FindAllRaw_RejectsMixedProjection(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, &Config{EnableStats: true})
+
+	_, err := uow.FindAllRaw(context.Background(), bson.M{}, options.Find().SetProjection(bson.M{"name": 1, "email": 0}))
+
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidProjection)
+	assert.Zero(t, uow.Stats().Total())
+}
+
+type Listing struct {
+	domain.BaseEntity `bson:",inline"`
+	Title             string `bson:"title" json:"title"`
+	Description       string `bson:"description" json:"description"`
+	Photo             []byte `bson:"photo,omitempty" json:"photo,omitempty"`
+}
+
+func (Listing) ListExcludedFields() []string { return []string{"description", "photo"} }
+
+func TestListProjection(t *testing.T) {
+	expected := bson.D{{Key: "description", Value: 0}, {Key: "photo", Value: 0}}
+	assert.Equal(t, expected, listProjection((*Listing)(nil)))
+	assert.Equal(t, expected, listProjection(Listing{}))
+	assert.Equal(t, bson.D{{Key: "photo", Value: 0}}, listProjection((*Listing)(nil), "description"))
+	assert.Nil(t, listProjection((*TestUser)(nil)))
+	assert.Nil(t, listProjection(nil))
+}
+
+func TestUnitOfWork_ListProjection_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*Listing](t)
+	ctx := context.Background()
+
+	created, err := uow.Insert(ctx, &Listing{Title: "Loft", Description: "A long description", Photo: []byte{0xff, 0xd8}})
+	require.NoError(t, err)
+
+	assertLight := func(listings []*Listing) {
+		t.Helper()
+		require.Len(t, listings, 1)
+		assert.Equal(t, "Loft", listings[0].Title)
+		assert.Empty(t, listings[0].Description)
+		assert.Empty(t, listings[0].Photo)
+	}
+
+	all, err := uow.FindAll(ctx)
+	require.NoError(t, err)
+	assertLight(all)
+
+	page, _, err := uow.FindAllWithPagination(ctx, domain.QueryParams[*Listing]{Sort: domain.SortMap{"title": domain.SortAsc}})
+	require.NoError(t, err)
+	assertLight(page)
+
+	cursorPage, err := uow.FindAllWithCursor(ctx, domain.KeysetParams[*Listing]{Limit: 10})
+	require.NoError(t, err)
+	assertLight(cursorPage.Items)
+
+	one, err := uow.FindOneById(ctx, created.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, "A long description", one.Description)
+	assert.Equal(t, []byte{0xff, 0xd8}, one.Photo)
+}
@@ -0,0 +1,134 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+)
+
+// readOnlyRepository serves the queries of a repository over one unit of
+// work. It is unexported so callers only ever hold the read-only interface.
+type readOnlyRepository[T persistence.ModelConstraint] struct {
+	uow persistence.IUnitOfWork[T]
+}
+
+// NewReadOnlyRepository wraps uow in a repository that can only read, with
+// reads sent to secondaries
+func NewReadOnlyRepository[T persistence.ModelConstraint](uow persistence.IUnitOfWork[T]) persistence.IReadOnlyRepository[T] {
+	return NewReadOnlyRepositoryWithPreference(uow, readpref.Secondary())
+}
+
+// NewReadOnlyRepositoryWithPreference is NewReadOnlyRepository with reads
+// routed by rp, e.g. readpref.SecondaryPreferred() to fall back to the
+// primary when no secondary is available
+func NewReadOnlyRepositoryWithPreference[T persistence.ModelConstraint](uow persistence.IUnitOfWork[T], rp *readpref.ReadPref) persistence.IReadOnlyRepository[T] {
+	return &readOnlyRepository[T]{uow: uow.WithReadPreference(rp)}
+}
+
+func (r *readOnlyRepository[T]) FindOneById(ctx context.Context, id primitive.ObjectID) (T, error) {
+	return r.uow.FindOneById(ctx, id)
+}
+
+func (r *readOnlyRepository[T]) FindOneByHexId(ctx context.Context, hexID string) (T, error) {
+	return r.uow.FindOneByHexId(ctx, hexID)
+}
+
+func (r *readOnlyRepository[T]) FindOneByIdWithTrashed(ctx context.Context, id primitive.ObjectID) (T, error) {
+	return r.uow.FindOneByIdWithTrashed(ctx, id)
+}
+
+func (r *readOnlyRepository[T]) FindByIds(ctx context.Context, ids []primitive.ObjectID) ([]T, error) {
+	return r.uow.FindByIds(ctx, ids)
+}
+
+func (r *readOnlyRepository[T]) FindByIdsOrdered(ctx context.Context, ids []primitive.ObjectID) ([]T, error) {
+	return r.uow.FindByIdsOrdered(ctx, ids)
+}
+
+func (r *readOnlyRepository[T]) FindOne(ctx context.Context, id identifier.IIdentifier) (T, error) {
+	return r.uow.FindOneByIdentifier(ctx, id)
+}
+
+func (r *readOnlyRepository[T]) TryFindOneById(ctx context.Context, id primitive.ObjectID) (T, bool, error) {
+	return r.uow.TryFindOneById(ctx, id)
+}
+
+func (r *readOnlyRepository[T]) TryFindOne(ctx context.Context, id identifier.IIdentifier) (T, bool, error) {
+	return r.uow.TryFindOne(ctx, id)
+}
+
+// FindAll finds the live entities matching id, or every live entity when id
+// is nil
+func (r *readOnlyRepository[T]) FindAll(ctx context.Context, id identifier.IIdentifier) ([]T, error) {
+	if id == nil {
+		return r.uow.FindAll(ctx)
+	}
+	return r.uow.FindAllRaw(ctx, id.ToBSON())
+}
+
+func (r *readOnlyRepository[T]) FindAllMatchingAny(ctx context.Context, ids ...identifier.IIdentifier) ([]T, error) {
+	return r.findAllMatching(ctx, identifier.New().Or(ids...))
+}
+
+func (r *readOnlyRepository[T]) FindAllMatchingAll(ctx context.Context, ids ...identifier.IIdentifier) ([]T, error) {
+	return r.findAllMatching(ctx, identifier.New().And(ids...))
+}
+
+func (r *readOnlyRepository[T]) FindAllSatisfying(ctx context.Context, spec identifier.Specification) ([]T, error) {
+	return r.findAllMatching(ctx, specIdentifier(spec))
+}
+
+func (r *readOnlyRepository[T]) findAllMatching(ctx context.Context, combined identifier.IIdentifier) ([]T, error) {
+	filter, err := predicateFilter(combined)
+	if err != nil {
+		return nil, err
+	}
+	return r.uow.FindAllRaw(ctx, filter)
+}
+
+func (r *readOnlyRepository[T]) FindAllWithPagination(ctx context.Context, query domain.QueryParams[T]) ([]T, int64, error) {
+	entities, count, err := r.uow.FindAllWithPagination(ctx, query)
+	return entities, int64(count), err
+}
+
+func (r *readOnlyRepository[T]) Count(ctx context.Context, id identifier.IIdentifier) (int64, error) {
+	return r.uow.Count(ctx, id)
+}
+
+func (r *readOnlyRepository[T]) Exists(ctx context.Context, id identifier.IIdentifier) (bool, error) {
+	return r.uow.Exists(ctx, id)
+}
+
+func (r *readOnlyRepository[T]) Distinct(ctx context.Context, field string, filter identifier.IIdentifier) ([]interface{}, error) {
+	return r.uow.Distinct(ctx, field, filter)
+}
+
+func (r *readOnlyRepository[T]) DistinctWithCounts(ctx context.Context, field string, filter identifier.IIdentifier) ([]persistence.ValueCount, error) {
+	return r.uow.DistinctWithCounts(ctx, field, filter)
+}
+
+// Aggregate runs pipeline, refusing $out and $merge stages since they write
+func (r *readOnlyRepository[T]) Aggregate(ctx context.Context, pipeline mongo.Pipeline, result interface{}) error {
+	for _, stage := range pipeline {
+		if len(stage) > 0 && (stage[0].Key == "$out" || stage[0].Key == "$merge") {
+			return fmt.Errorf("%w: %s is not allowed in a read-only repository", uowerrors.ErrInvalidQuery, stage[0].Key)
+		}
+	}
+	return r.uow.Aggregate(ctx, pipeline, result)
+}
+
+func (r *readOnlyRepository[T]) GetTrashed(ctx context.Context) ([]T, error) {
+	return r.uow.GetTrashed(ctx)
+}
+
+func (r *readOnlyRepository[T]) CollectionName() string {
+	return r.uow.CollectionName()
+}
@@ -0,0 +1,105 @@
+package mongodb
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+)
+
+// A full repository can be handed to code that only needs reads
+var _ persistence.IReadOnlyRepository[*TestUser] = persistence.IBaseRepository[*TestUser](nil)
+
+func TestReadOnlyRepository_HasNoMutatingMethods(t *testing.T) {
+	mutating := []string{"Insert", "Update", "Upsert", "Delete", "HardDelete", "SoftDelete", "Restore", "Bulk", "Begin", "Commit", "Rollback"}
+
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+	for _, typ := range []reflect.Type{
+		reflect.TypeOf((*persistence.IReadOnlyRepository[*TestUser])(nil)).Elem(),
+		reflect.TypeOf(NewReadOnlyRepository[*TestUser](uow)),
+	} {
+		for i := 0; i < typ.NumMethod(); i++ {
+			name := typ.Method(i).Name
+			for _, prefix := range mutating {
+				assert.False(t, strings.HasPrefix(name, prefix), "%s exposes %s", typ, name)
+			}
+		}
+	}
+}
+
+func TestReadOnlyRepository_ReadPreference(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+
+	repo := NewReadOnlyRepository[*TestUser](uow).(*readOnlyRepository[*TestUser])
+	assert.Equal(t, readpref.SecondaryMode, repo.uow.(*UnitOfWork[*TestUser]).database.ReadPreference().Mode())
+
+	repo = NewReadOnlyRepositoryWithPreference[*TestUser](uow, readpref.Nearest()).(*readOnlyRepository[*TestUser])
+	assert.Equal(t, readpref.NearestMode, repo.uow.(*UnitOfWork[*TestUser]).database.ReadPreference().Mode())
+	assert.Equal(t, readpref.PrimaryMode, uow.database.ReadPreference().Mode(), "the wrapped unit of work is left as it was")
+}
+
+func TestReadOnlyRepository_AggregateRejectsWrites(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, &Config{EnableStats: true})
+	repo := NewReadOnlyRepository[*TestUser](uow)
+
+	for _, stage := range []string{"$out", "$merge"} {
+		var results []bson.M
+		err := repo.Aggregate(context.Background(), mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{}}},
+			{{Key: stage, Value: "elsewhere"}},
+		}, &results)
+		assert.ErrorIs(t, err, uowerrors.ErrInvalidQuery, stage)
+	}
+	assert.Zero(t, uow.Stats().Total())
+}
+
+func TestReadOnlyRepository_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	users, err := uow.BulkInsert(ctx, []*TestUser{
+		{Email: "a@example.com", Age: 20, Active: true},
+		{Email: "b@example.com", Age: 30, Active: false},
+		{Email: "c@example.com", Age: 40, Active: true},
+	})
+	require.NoError(t, err)
+
+	// PrimaryPreferred keeps the test working on servers without secondaries
+	repo := NewReadOnlyRepositoryWithPreference[*TestUser](uow, readpref.PrimaryPreferred())
+	active := identifier.New().Equal("active", true)
+
+	found, err := repo.FindOneById(ctx, users[1].GetID())
+	require.NoError(t, err)
+	assert.Equal(t, "b@example.com", found.Email)
+
+	all, err := repo.FindAll(ctx, active)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	count, err := repo.Count(ctx, active)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	exists, err := repo.Exists(ctx, identifier.New().Equal("email", "c@example.com"))
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	var totals []struct {
+		Sum int `bson:"sum"`
+	}
+	require.NoError(t, repo.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{{Key: "_id", Value: nil}, {Key: "sum", Value: bson.D{{Key: "$sum", Value: "$age"}}}}}},
+	}, &totals))
+	require.Len(t, totals, 1)
+	assert.Equal(t, 90, totals[0].Sum)
+}
@@ -0,0 +1,143 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+)
+
+// Money has no exported fields, so it only round-trips through a custom codec
+type Money struct {
+	cents int64
+}
+
+var moneyType = reflect.TypeOf(Money{})
+
+// TestInvoice stores its total as a decimal string via the Money codec
+type TestInvoice struct {
+	domain.BaseEntity `bson:",inline"`
+	Total             Money `bson:"total" json:"total"`
+}
+
+func encodeMoney(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	money := val.Interface().(Money)
+	return vw.WriteString(fmt.Sprintf("%d.%02d", money.cents/100, money.cents%100))
+}
+
+func decodeMoney(_ bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if vr.Type() != bsontype.String {
+		return fmt.Errorf("cannot decode %v into Money", vr.Type())
+	}
+	s, err := vr.ReadString()
+	if err != nil {
+		return err
+	}
+
+	var units, cents int64
+	if _, err := fmt.Sscanf(s, "%d.%d", &units, &cents); err != nil {
+		return fmt.Errorf("invalid money value %q: %w", s, err)
+	}
+	val.Set(reflect.ValueOf(Money{cents: units*100 + cents}))
+	return nil
+}
+
+func newMoneyRegistry() *bsoncodec.Registry {
+	registry := bson.NewRegistry()
+	registry.RegisterTypeEncoder(moneyType, bsoncodec.ValueEncoderFunc(encodeMoney))
+	registry.RegisterTypeDecoder(moneyType, bsoncodec.ValueDecoderFunc(decodeMoney))
+	return registry
+}
+
+func TestFactory_WithRegistry(t *testing.T) {
+	registry := newMoneyRegistry()
+
+	factory, err := NewFactory[*TestInvoice](NewConfig(), WithRegistry(registry))
+	require.NoError(t, err)
+
+	assert.Same(t, registry, factory.settings.registry)
+}
+
+func TestUnitOfWork_StrictDecoding_UsesRegistry(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestInvoice](t, nil)
+	uow.strictDecoding = true
+
+	result := mongo.NewSingleResultFromDocument(bson.M{"name": "march", "total": "12.34"}, nil, newMoneyRegistry())
+
+	var invoice *TestInvoice
+	require.NoError(t, uow.decode(result, &invoice))
+	assert.Equal(t, Money{cents: 1234}, invoice.Total)
+}
+
+func TestUnitOfWork_CustomRegistry_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestInvoice](t, WithRegistry(newMoneyRegistry()))
+	ctx := context.Background()
+
+	invoice := &TestInvoice{Total: Money{cents: 1234}}
+	invoice.Name = "March invoice"
+
+	created, err := uow.Insert(ctx, invoice)
+	require.NoError(t, err)
+
+	var stored bson.M
+	require.NoError(t, uow.getCollection().FindOne(ctx, bson.M{"_id": created.GetID()}).Decode(&stored))
+	assert.Equal(t, "12.34", stored["total"])
+
+	found, err := uow.FindOneById(ctx, created.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, Money{cents: 1234}, found.Total)
+}
+
+func TestUnitOfWork_DiffUpdate_UsesRegistry(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestInvoice](t, nil)
+	uow.registry = newMoneyRegistry()
+
+	before := &TestInvoice{Total: Money{cents: 1234}}
+	before.SetName("march")
+	entity := &TestInvoice{Total: Money{cents: 1500}}
+	entity.SetName("march")
+
+	updated, changes, err := uow.diffUpdate(before, entity)
+	require.NoError(t, err)
+	assert.Equal(t, Money{cents: 1500}, updated.Total)
+	assert.Equal(t, FieldChange{Old: "12.34", New: "15.00"}, changes["total"])
+	assert.NotContains(t, changes, "name")
+}
+
+func TestUnitOfWork_UpsertUpdate_UsesRegistry(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestInvoice](t, nil)
+	uow.registry = newMoneyRegistry()
+
+	update, err := uow.upsertUpdate(&TestInvoice{Total: Money{cents: 1234}})
+	require.NoError(t, err)
+
+	var total bson.RawValue
+	for _, e := range update["$set"].(bson.D) {
+		if e.Key == "total" {
+			total = e.Value.(bson.RawValue)
+		}
+	}
+	assert.Equal(t, "12.34", total.StringValue(), "codec-backed fields are encoded as Insert stores them")
+}
+
+func TestUnitOfWork_CursorFor_UsesRegistry(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestInvoice](t, nil)
+	uow.registry = newMoneyRegistry()
+
+	token, err := uow.cursorFor(&TestInvoice{Total: Money{cents: 1234}}, "total", pageNext)
+	require.NoError(t, err)
+
+	c, err := decodeCursor(token)
+	require.NoError(t, err)
+	assert.Equal(t, "12.34", c.Value, "the cursor holds the stored sort value")
+}
@@ -2,7 +2,12 @@ package mongodb
 
 import (
 	"context"
+	"sort"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
 )
@@ -18,48 +23,42 @@ func NewUserRepository(baseRepo persistence.IBaseRepository[*persistence.User])
 }
 
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*persistence.User, error) {
-	id := identifier.New().Equal("email", email)
+	id := identifier.New().Equal("email", domain.Normalize(email))
 	return r.FindOne(ctx, id)
 }
 
 func (r *UserRepository) FindActiveUsers(ctx context.Context) ([]*persistence.User, error) {
-	id := identifier.New().Equal("active", true).Equal("deletedAt", nil)
+	id := identifier.New().Equal("active", true)
 	return r.FindAll(ctx, id)
 }
 
 func (r *UserRepository) FindUsersByAgeRange(ctx context.Context, minAge, maxAge int) ([]*persistence.User, error) {
 
-	id := identifier.New().Between("age", minAge, maxAge).Equal("deletedAt", nil)
+	id := identifier.New().Between("age", minAge, maxAge)
 	return r.FindAll(ctx, id)
 }
 
-func (r *UserRepository) GetUserStats(ctx context.Context) (*persistence.UserStats, error) {
-
-	allUsers, err := r.FindAll(ctx, identifier.New().Equal("deletedAt", nil))
-	if err != nil {
-		return nil, err
-	}
+// userStatsPipeline groups every user into one document holding the counts
+// and the average age
+var userStatsPipeline = mongo.Pipeline{
+	{{Key: "$group", Value: bson.D{
+		{Key: "_id", Value: nil},
+		{Key: "totalUsers", Value: bson.D{{Key: "$sum", Value: 1}}},
+		{Key: "activeUsers", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{"$active", 1, 0}}}}}},
+		{Key: "averageAge", Value: bson.D{{Key: "$avg", Value: "$age"}}},
+	}}},
+}
 
-	activeUsers, err := r.FindActiveUsers(ctx)
-	if err != nil {
+func (r *UserRepository) GetUserStats(ctx context.Context) (*persistence.UserStats, error) {
+	var results []persistence.UserStats
+	if err := r.Aggregate(ctx, userStatsPipeline, &results); err != nil {
 		return nil, err
 	}
 
-	var totalAge int64
-	for _, user := range allUsers {
-		totalAge += int64(user.Age)
+	if len(results) == 0 {
+		return &persistence.UserStats{}, nil
 	}
-
-	var averageAge float64
-	if len(allUsers) > 0 {
-		averageAge = float64(totalAge) / float64(len(allUsers))
-	}
-
-	return &persistence.UserStats{
-		TotalUsers:  int64(len(allUsers)),
-		ActiveUsers: int64(len(activeUsers)),
-		AverageAge:  averageAge,
-	}, nil
+	return &results[0], nil
 }
 
 type ProductRepository struct {
@@ -73,54 +72,53 @@ func NewProductRepository(baseRepo persistence.IBaseRepository[*persistence.Prod
 }
 
 func (r *ProductRepository) FindByCategory(ctx context.Context, category string) ([]*persistence.Product, error) {
-	id := identifier.New().Equal("category", category).Equal("deletedAt", nil)
+	id := identifier.New().Equal("category", category)
 	return r.FindAll(ctx, id)
 }
 
 func (r *ProductRepository) FindInStockProducts(ctx context.Context) ([]*persistence.Product, error) {
-	id := identifier.New().Equal("inStock", true).Equal("deletedAt", nil)
+	id := identifier.New().Equal("inStock", true)
 	return r.FindAll(ctx, id)
 }
 
 func (r *ProductRepository) FindProductsByPriceRange(ctx context.Context, minPrice, maxPrice float64) ([]*persistence.Product, error) {
 
-	id := identifier.New().Between("price", minPrice, maxPrice).Equal("deletedAt", nil)
+	id := identifier.New().Between("price", minPrice, maxPrice)
 	return r.FindAll(ctx, id)
 }
 
-func (r *ProductRepository) GetProductStats(ctx context.Context) (*persistence.ProductStats, error) {
-
-	allProducts, err := r.FindAll(ctx, identifier.New().Equal("deletedAt", nil))
-	if err != nil {
-		return nil, err
-	}
+// productStatsPipeline groups every product into one document holding the
+// counts and the average price
+var productStatsPipeline = mongo.Pipeline{
+	{{Key: "$group", Value: bson.D{
+		{Key: "_id", Value: nil},
+		{Key: "totalProducts", Value: bson.D{{Key: "$sum", Value: 1}}},
+		{Key: "inStockProducts", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{"$inStock", 1, 0}}}}}},
+		{Key: "averagePrice", Value: bson.D{{Key: "$avg", Value: "$price"}}},
+	}}},
+}
 
-	inStockProducts, err := r.FindInStockProducts(ctx)
-	if err != nil {
+func (r *ProductRepository) GetProductStats(ctx context.Context) (*persistence.ProductStats, error) {
+	var results []persistence.ProductStats
+	if err := r.Aggregate(ctx, productStatsPipeline, &results); err != nil {
 		return nil, err
 	}
 
-	var totalPrice float64
-	categorySet := make(map[string]bool)
-	for _, product := range allProducts {
-		totalPrice += product.Price
-		categorySet[product.Category] = true
+	if len(results) == 0 {
+		return &persistence.ProductStats{}, nil
 	}
+	stats := &results[0]
 
-	var averagePrice float64
-	if len(allProducts) > 0 {
-		averagePrice = totalPrice / float64(len(allProducts))
+	categories, err := r.Distinct(ctx, "category", nil)
+	if err != nil {
+		return nil, err
 	}
-
-	var categories []string
-	for category := range categorySet {
-		categories = append(categories, category)
+	for _, category := range categories {
+		if name, ok := category.(string); ok {
+			stats.Categories = append(stats.Categories, name)
+		}
 	}
+	sort.Strings(stats.Categories)
 
-	return &persistence.ProductStats{
-		TotalProducts:   int64(len(allProducts)),
-		InStockProducts: int64(len(inStockProducts)),
-		AveragePrice:    averagePrice,
-		Categories:      categories,
-	}, nil
+	return stats, nil
 }
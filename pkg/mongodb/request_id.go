@@ -0,0 +1,75 @@
+package mongodb
+
+import (
+	"context"
+	"log/slog"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// RequestIDKey is the log attribute holding the request ID
+const RequestIDKey = "request_id"
+
+type requestIDContextKey struct{}
+
+// WithRequestID returns a context carrying id. Every record the factory logger
+// writes for an operation run with that context includes it under
+// RequestIDKey, tying database logs to the originating request.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID set with WithRequestID
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	id, ok := ctx.Value(requestIDContextKey{}).(string)
+	return id, ok && id != ""
+}
+
+// requestIDHandler adds the request ID of the record's context to every record
+type requestIDHandler struct {
+	slog.Handler
+}
+
+func (h requestIDHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id, ok := RequestIDFromContext(ctx); ok {
+		r = r.Clone()
+		r.AddAttrs(slog.String(RequestIDKey, id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h requestIDHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return requestIDHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h requestIDHandler) WithGroup(name string) slog.Handler {
+	return requestIDHandler{h.Handler.WithGroup(name)}
+}
+
+// commandMonitor traces every command the client sends at debug level, or
+// returns nil when no logger is set
+func commandMonitor(logger *slog.Logger) *event.CommandMonitor {
+	if logger == nil {
+		return nil
+	}
+	return &event.CommandMonitor{
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			logger.DebugContext(ctx, "mongodb command",
+				slog.String("command", e.CommandName),
+				slog.String("database", e.DatabaseName),
+				slog.Duration("duration", e.Duration),
+			)
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			logger.WarnContext(ctx, "mongodb command failed",
+				slog.String("command", e.CommandName),
+				slog.String("database", e.DatabaseName),
+				slog.Duration("duration", e.Duration),
+				slog.String("error", e.Failure),
+			)
+		},
+	}
+}
@@ -0,0 +1,84 @@
+package mongodb
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+func TestRequestIDFromContext(t *testing.T) {
+	id, ok := RequestIDFromContext(WithRequestID(context.Background(), "req-1"))
+	assert.True(t, ok)
+	assert.Equal(t, "req-1", id)
+
+	_, ok = RequestIDFromContext(context.Background())
+	assert.False(t, ok)
+	_, ok = RequestIDFromContext(WithRequestID(context.Background(), ""))
+	assert.False(t, ok)
+}
+
+func TestWithLogger_AddsRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	factory, err := NewFactory[*TestUser](NewConfig(), WithLogger(slog.New(slog.NewTextHandler(&logs, nil)).With("service", "billing")))
+	require.NoError(t, err)
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+	factory.apply(uow)
+
+	uow.logCollectionWide(WithRequestID(context.Background(), "req-7"), "RestoreAll", 2)
+	assert.Contains(t, logs.String(), "request_id=req-7")
+	assert.Contains(t, logs.String(), "service=billing", "attributes added to the logger are kept")
+
+	logs.Reset()
+	uow.logCollectionWide(context.Background(), "RestoreAll", 2)
+	assert.NotContains(t, logs.String(), RequestIDKey)
+
+	factory, err = NewFactory[*TestUser](NewConfig(), WithLogger(nil))
+	require.NoError(t, err)
+	assert.Nil(t, factory.settings.logger)
+	assert.Nil(t, commandMonitor(factory.settings.logger))
+}
+
+func TestCommandMonitor_LogsRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	factory, err := NewFactory[*TestUser](NewConfig(), WithLogger(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+	require.NoError(t, err)
+	monitor := commandMonitor(factory.settings.logger)
+	ctx := WithRequestID(context.Background(), "req-9")
+
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{
+		CommandName: "find", DatabaseName: "shop", Duration: 3 * time.Millisecond,
+	}})
+	assert.Contains(t, logs.String(), "level=DEBUG")
+	assert.Contains(t, logs.String(), "command=find")
+	assert.Contains(t, logs.String(), "request_id=req-9")
+
+	logs.Reset()
+	monitor.Failed(ctx, &event.CommandFailedEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "insert", DatabaseName: "shop"},
+		Failure:              "duplicate key",
+	})
+	assert.Contains(t, logs.String(), "level=WARN")
+	assert.Contains(t, logs.String(), `error="duplicate key"`)
+	assert.Contains(t, logs.String(), "request_id=req-9")
+}
+
+func TestRequestID_Integration(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	uow := newIntegrationUnitOfWork[*TestUser](t, WithLogger(logger))
+	ctx := WithRequestID(context.Background(), "req-42")
+
+	_, err := uow.Insert(ctx, &TestUser{Email: "traced@example.com"})
+	require.NoError(t, err)
+	_, err = uow.FindAll(ctx)
+	require.NoError(t, err)
+
+	assert.Regexp(t, `command=insert .*request_id=req-42`, logs.String())
+	assert.Regexp(t, `command=find .*request_id=req-42`, logs.String())
+}
@@ -0,0 +1,243 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// defaultSlugAttempts bounds the collision search during slug generation
+const defaultSlugAttempts = 100
+
+// SlugStrategy turns entity names into slugs and derives alternatives when a
+// slug is already taken
+type SlugStrategy interface {
+	// Slugify converts a name into a slug; an empty result skips generation
+	Slugify(name string) string
+	// WithSuffix returns the candidate for the n-th collision of slug, n >= 1
+	WithSuffix(slug string, n int) string
+}
+
+// DefaultSlugStrategy folds to ASCII, lowercases, hyphenates and resolves
+// collisions with a counter suffix ("name", "name-2", "name-3", ...)
+type DefaultSlugStrategy struct {
+	// MaxLength truncates slugs, suffix included; zero means no limit
+	MaxLength int
+}
+
+// Slugify implements SlugStrategy
+func (s DefaultSlugStrategy) Slugify(name string) string {
+	folded, _, err := transform.String(
+		transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC),
+		name,
+	)
+	if err != nil {
+		folded = name
+	}
+
+	var builder strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(folded) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if pendingHyphen && builder.Len() > 0 {
+				builder.WriteByte('-')
+			}
+			builder.WriteRune(r)
+			pendingHyphen = false
+			continue
+		}
+		pendingHyphen = true
+	}
+
+	return s.truncate(builder.String(), 0)
+}
+
+// WithSuffix implements SlugStrategy
+func (s DefaultSlugStrategy) WithSuffix(slug string, n int) string {
+	suffix := fmt.Sprintf("-%d", n+1)
+	return s.truncate(slug, len(suffix)) + suffix
+}
+
+func (s DefaultSlugStrategy) truncate(slug string, reserve int) string {
+	if s.MaxLength <= 0 || len(slug)+reserve <= s.MaxLength {
+		return slug
+	}
+	limit := s.MaxLength - reserve
+	if limit < 0 {
+		limit = 0
+	}
+	return strings.TrimRight(slug[:limit], "-")
+}
+
+// WithSlugAttempts sets how many slug candidates are tried before an insert
+// gives up; values below one keep the default of 100
+func WithSlugAttempts(attempts int) FactoryOption {
+	return func(s *factorySettings) {
+		if attempts > 0 {
+			s.slugAttempts = attempts
+		}
+	}
+}
+
+// slugIndexSpec is the unique index on slug; documents without a slug are not
+// indexed
+var slugIndexSpec = IndexSpec{
+	Keys:          bson.D{{Key: "slug", Value: 1}},
+	Unique:        true,
+	PartialFilter: bson.M{"slug": bson.M{"$type": "string"}},
+}
+
+// EnsureSlugIndex creates the unique index on slug that generated slugs rely
+// on to detect collisions. The first insert generating a slug creates it when
+// it is missing.
+func (uow *UnitOfWork[T]) EnsureSlugIndex(ctx context.Context) error {
+	_, err := uow.EnsureIndexes(ctx, slugIndexSpec)
+	if err == nil && uow.slugIndex != nil {
+		uow.slugIndex.mark(uow.database.Name(), true)
+	}
+	return err
+}
+
+// slugIndexes remembers, per database, whether the unique slug index could
+// be created, so each unit of work of a factory tries at most once
+type slugIndexes struct {
+	mu    sync.Mutex
+	ready map[string]bool
+}
+
+func newSlugIndexes() *slugIndexes {
+	return &slugIndexes{ready: map[string]bool{}}
+}
+
+func (s *slugIndexes) mark(database string, ready bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ready[database] = ready
+}
+
+// hasSlugIndex creates the unique slug index on first use and reports whether
+// it is in place. Creation fails, for example, when stored slugs already
+// collide; generation then falls back to checking candidates before insert.
+func (uow *UnitOfWork[T]) hasSlugIndex(ctx context.Context) bool {
+	if uow.slugIndex == nil {
+		return false
+	}
+	uow.slugIndex.mu.Lock()
+	defer uow.slugIndex.mu.Unlock()
+
+	name := uow.database.Name()
+	if ready, tried := uow.slugIndex.ready[name]; tried {
+		return ready
+	}
+	_, err := uow.EnsureIndexes(ctx, slugIndexSpec)
+	if err != nil && uow.logger != nil {
+		uow.logger.WarnContext(ctx, "slug index unavailable, checking slugs before insert",
+			slog.String("collection", uow.collectionName), slog.Any("error", err))
+	}
+	// A cancelled context says nothing about the index, so try again next time
+	if ctx.Err() == nil {
+		uow.slugIndex.ready[name] = err == nil
+	}
+	return err == nil
+}
+
+// insertOne writes entity, generating its slug from the name when none was
+// set. With the unique slug index in place each candidate is inserted directly
+// and a duplicate key on slug moves on to the next suffix, so concurrent
+// inserts cannot end up with the same slug. Inside a transaction, where a
+// duplicate key would abort it, or without the index, candidates are checked
+// before inserting.
+func (uow *UnitOfWork[T]) insertOne(ctx context.Context, entity T) error {
+	base := ""
+	if uow.slugStrategy != nil && entity.GetSlug() == "" {
+		base = uow.slugStrategy.Slugify(entity.GetName())
+	}
+
+	if base != "" && (uow.inTx || !uow.hasSlugIndex(ctx)) {
+		if err := uow.ensureSlug(ctx, entity, base); err != nil {
+			return err
+		}
+		base = ""
+	}
+
+	collection := uow.getCollection()
+	if base == "" {
+		uow.track(opInsert)
+		_, err := collection.InsertOne(uow.getContext(ctx), entity)
+		return err
+	}
+
+	for n := 0; n < uow.slugAttempts; n++ {
+		candidate := base
+		if n > 0 {
+			candidate = uow.slugStrategy.WithSuffix(base, n)
+		}
+		entity.SetSlug(candidate)
+
+		uow.track(opInsert)
+		_, err := collection.InsertOne(uow.getContext(ctx), entity)
+		if err == nil {
+			return nil
+		}
+		if !isSlugConflict(err) {
+			entity.SetSlug("")
+			return err
+		}
+	}
+
+	entity.SetSlug("")
+	return fmt.Errorf("failed to generate unique slug for %q after %d attempts", base, uow.slugAttempts)
+}
+
+// isSlugConflict reports whether err is a duplicate key error on slug
+func isSlugConflict(err error) bool {
+	var writeException mongo.WriteException
+	if !errors.As(err, &writeException) {
+		return false
+	}
+
+	for _, writeError := range writeException.WriteErrors {
+		if writeError.Code != 11000 {
+			continue
+		}
+		if _, lookupErr := writeError.Raw.LookupErr("keyPattern", "slug"); lookupErr == nil {
+			return true
+		}
+		if strings.Contains(writeError.Message, "dup key: { slug:") {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureSlug sets the first suffixed candidate of base that is unused in the
+// collection
+func (uow *UnitOfWork[T]) ensureSlug(ctx context.Context, entity T, base string) error {
+	collection := uow.getCollection()
+	candidate := base
+	for n := 1; n <= uow.slugAttempts; n++ {
+		uow.track(opCount)
+		count, err := collection.CountDocuments(uow.getContext(ctx), bson.M{"slug": candidate}, options.Count().SetLimit(1))
+		if err != nil {
+			return fmt.Errorf("failed to check slug: %w", err)
+		}
+		if count == 0 {
+			entity.SetSlug(candidate)
+			return nil
+		}
+		candidate = uow.slugStrategy.WithSuffix(base, n)
+	}
+
+	return fmt.Errorf("failed to generate unique slug for %q after %d attempts", base, uow.slugAttempts)
+}
@@ -0,0 +1,225 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestDefaultSlugStrategy_Slugify(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "ascii", input: "Hello World", expected: "hello-world"},
+		{name: "punctuation", input: "  Hello, World!! ", expected: "hello-world"},
+		{name: "accents", input: "Crème Brûlée", expected: "creme-brulee"},
+		{name: "umlauts", input: "Ärger über Öl", expected: "arger-uber-ol"},
+		{name: "mixed digits", input: "iPhone 15 Pro", expected: "iphone-15-pro"},
+		{name: "no latin characters", input: "日本語", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DefaultSlugStrategy{}.Slugify(tt.input))
+		})
+	}
+}
+
+func TestDefaultSlugStrategy_MaxLength(t *testing.T) {
+	strategy := DefaultSlugStrategy{MaxLength: 10}
+
+	assert.Equal(t, "creme-brul", strategy.Slugify("Crème Brûlée"))
+	assert.Equal(t, "creme-br-2", strategy.WithSuffix("creme-brul", 1))
+	assert.LessOrEqual(t, len(strategy.WithSuffix("creme-brul", 99)), 10)
+}
+
+func TestDefaultSlugStrategy_WithSuffix(t *testing.T) {
+	strategy := DefaultSlugStrategy{}
+
+	assert.Equal(t, "laptop-2", strategy.WithSuffix("laptop", 1))
+	assert.Equal(t, "laptop-3", strategy.WithSuffix("laptop", 2))
+}
+
+type upperSlugStrategy struct{}
+
+func (upperSlugStrategy) Slugify(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, " ", "_"))
+}
+
+func (upperSlugStrategy) WithSuffix(slug string, n int) string {
+	return slug + strings.Repeat("_", n)
+}
+
+func TestFactory_WithSlugStrategy(t *testing.T) {
+	factory, err := NewFactory[*TestUser](NewConfig(), WithSlugStrategy(upperSlugStrategy{}))
+	require.NoError(t, err)
+
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+	factory.apply(uow)
+
+	assert.Equal(t, upperSlugStrategy{}, uow.slugStrategy)
+}
+
+func TestFactory_SlugGenerationDisabledByDefault(t *testing.T) {
+	factory, err := NewFactory[*TestUser](NewConfig())
+	require.NoError(t, err)
+	assert.Nil(t, factory.settings.slugStrategy)
+
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+	factory.apply(uow)
+	assert.Nil(t, uow.slugStrategy)
+}
+
+func TestFactory_WithSlugAttempts(t *testing.T) {
+	factory, err := NewFactory[*TestUser](NewConfig(), WithSlugAttempts(5))
+	require.NoError(t, err)
+	assert.Equal(t, 5, factory.settings.slugAttempts)
+
+	factory, err = NewFactory[*TestUser](NewConfig(), WithSlugAttempts(0))
+	require.NoError(t, err)
+	assert.Equal(t, defaultSlugAttempts, factory.settings.slugAttempts)
+}
+
+func TestUnitOfWork_HasSlugIndex_Remembered(t *testing.T) {
+	ctx := context.Background()
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+
+	uow.slugIndex.mark(uow.database.Name(), true)
+	assert.True(t, uow.hasSlugIndex(ctx))
+	assert.True(t, uow.view().hasSlugIndex(ctx), "views share the factory's record")
+
+	uow.slugIndex.mark(uow.database.Name(), false)
+	assert.False(t, uow.hasSlugIndex(ctx), "a failed creation is not retried on every insert")
+
+	uow.slugIndex = nil
+	assert.False(t, uow.hasSlugIndex(ctx))
+}
+
+func TestIsSlugConflict(t *testing.T) {
+	keyPattern, err := bson.Marshal(bson.M{"keyPattern": bson.M{"slug": 1}})
+	require.NoError(t, err)
+
+	duplicate := func(message string, raw bson.Raw) error {
+		return mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: message, Raw: raw}}}
+	}
+
+	assert.True(t, isSlugConflict(duplicate("E11000 duplicate key error", keyPattern)))
+	assert.True(t, isSlugConflict(fmt.Errorf("wrapped: %w", duplicate(`E11000 duplicate key error collection: db.testusers index: slug_1 dup key: { slug: "laptop" }`, nil))))
+	assert.False(t, isSlugConflict(duplicate(`E11000 duplicate key error collection: db.testusers index: _id_ dup key: { _id: ObjectId('507f1f77bcf86cd799439011') }`, nil)))
+	assert.False(t, isSlugConflict(mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 121, Message: "Document failed validation"}}}))
+	assert.False(t, isSlugConflict(fmt.Errorf("network error")))
+}
+
+func TestUnitOfWork_EnsureSlug_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t, WithSlugStrategy(DefaultSlugStrategy{}))
+	ctx := context.Background()
+	require.NoError(t, uow.EnsureSlugIndex(ctx))
+
+	first := &TestUser{}
+	first.SetName("Crème Brûlée")
+	_, err := uow.Insert(ctx, first)
+	require.NoError(t, err)
+	assert.Equal(t, "creme-brulee", first.GetSlug())
+
+	second := &TestUser{}
+	second.SetName("Creme Brulee")
+	_, err = uow.Insert(ctx, second)
+	require.NoError(t, err)
+	assert.Equal(t, "creme-brulee-2", second.GetSlug())
+
+	explicit := &TestUser{}
+	explicit.SetName("Creme Brulee")
+	explicit.SetSlug("custom")
+	_, err = uow.Insert(ctx, explicit)
+	require.NoError(t, err)
+	assert.Equal(t, "custom", explicit.GetSlug())
+
+	uow.slugStrategy = upperSlugStrategy{}
+	custom := &TestUser{}
+	custom.SetName("Creme Brulee")
+	_, err = uow.Insert(ctx, custom)
+	require.NoError(t, err)
+	assert.Equal(t, "CREME_BRULEE", custom.GetSlug())
+}
+
+func TestUnitOfWork_SlugIndexCreatedOnFirstInsert_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t, WithSlugStrategy(DefaultSlugStrategy{}))
+	ctx := context.Background()
+
+	for _, want := range []string{"laptop", "laptop-2"} {
+		user := &TestUser{}
+		user.SetName("Laptop")
+		_, err := uow.Insert(ctx, user)
+		require.NoError(t, err)
+		assert.Equal(t, want, user.GetSlug())
+	}
+
+	specs, err := uow.getCollection().Indexes().ListSpecifications(ctx)
+	require.NoError(t, err)
+	unique := false
+	for _, spec := range specs {
+		if spec.Name == "slug_1" && spec.Unique != nil {
+			unique = *spec.Unique
+		}
+	}
+	assert.True(t, unique, "the unique slug index is created without EnsureSlugIndex")
+}
+
+func TestUnitOfWork_ConcurrentSlugs_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t, WithSlugStrategy(DefaultSlugStrategy{}))
+	ctx := context.Background()
+	require.NoError(t, uow.EnsureSlugIndex(ctx))
+
+	const inserts = 10
+	slugs := make([]string, inserts)
+	errs := make([]error, inserts)
+
+	var wg sync.WaitGroup
+	for i := 0; i < inserts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			user := &TestUser{}
+			user.SetName("Same Name")
+			_, errs[i] = uow.Insert(ctx, user)
+			slugs[i] = user.GetSlug()
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	for i := range slugs {
+		require.NoError(t, errs[i])
+		assert.False(t, seen[slugs[i]], "duplicate slug %q", slugs[i])
+		seen[slugs[i]] = true
+	}
+	assert.True(t, seen["same-name"])
+}
+
+func TestUnitOfWork_SlugAttemptsExhausted_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t, WithSlugStrategy(DefaultSlugStrategy{}), WithSlugAttempts(2))
+	ctx := context.Background()
+	require.NoError(t, uow.EnsureSlugIndex(ctx))
+
+	for i := 0; i < 2; i++ {
+		user := &TestUser{}
+		user.SetName("Laptop")
+		_, err := uow.Insert(ctx, user)
+		require.NoError(t, err)
+	}
+
+	user := &TestUser{}
+	user.SetName("Laptop")
+	_, err := uow.Insert(ctx, user)
+	assert.ErrorContains(t, err, "after 2 attempts")
+	assert.Empty(t, user.GetSlug())
+}
@@ -0,0 +1,105 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+)
+
+// TimeSeriesOptions declares the factory collection a MongoDB time-series
+// collection. TimeField names the date field every document must carry;
+// MetaField optionally names the field identifying the series; Granularity
+// is "seconds", "minutes" or "hours" and defaults to seconds.
+type TimeSeriesOptions struct {
+	TimeField   string
+	MetaField   string
+	Granularity string
+}
+
+func (o TimeSeriesOptions) validate() error {
+	if o.TimeField == "" {
+		return fmt.Errorf("time field is required")
+	}
+	switch o.Granularity {
+	case "", "seconds", "minutes", "hours":
+		return nil
+	default:
+		return fmt.Errorf("unknown granularity %q", o.Granularity)
+	}
+}
+
+// WithTimeSeries makes the factory collection a time-series collection,
+// created with opts on the first insert unless it already exists. Time-series
+// data is append-oriented: updates, upserts and soft deletes fail with
+// ErrAppendOnly, while HardDelete still removes documents.
+func WithTimeSeries(opts TimeSeriesOptions) FactoryOption {
+	return func(s *factorySettings) {
+		s.timeSeries = &timeSeriesCollection{options: opts, created: map[string]bool{}}
+	}
+}
+
+// timeSeriesCollection remembers the databases in which the collection was
+// created, so each unit of work of a factory creates it at most once
+type timeSeriesCollection struct {
+	options TimeSeriesOptions
+
+	mu      sync.Mutex
+	created map[string]bool
+}
+
+// ensure creates the collection in database. An existing collection of the
+// same name is left as it is.
+func (ts *timeSeriesCollection) ensure(ctx context.Context, database *mongo.Database, name string) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.created[database.Name()] {
+		return nil
+	}
+
+	timeSeries := options.TimeSeries().SetTimeField(ts.options.TimeField)
+	if ts.options.MetaField != "" {
+		timeSeries.SetMetaField(ts.options.MetaField)
+	}
+	if ts.options.Granularity != "" {
+		timeSeries.SetGranularity(ts.options.Granularity)
+	}
+
+	err := database.CreateCollection(ctx, name, options.CreateCollection().SetTimeSeriesOptions(timeSeries))
+	if err != nil && !isNamespaceExists(err) {
+		return fmt.Errorf("failed to create time-series collection %s: %w", name, err)
+	}
+	ts.created[database.Name()] = true
+	return nil
+}
+
+// isNamespaceExists reports whether err is the NamespaceExists error returned
+// when creating a collection that already exists
+func isNamespaceExists(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(48)
+}
+
+// ensureTimeSeries creates the time-series collection before the first write.
+// It runs with ctx rather than the transaction context, since a transaction
+// cannot create a time-series collection.
+func (uow *UnitOfWork[T]) ensureTimeSeries(ctx context.Context) error {
+	if uow.timeSeries == nil {
+		return nil
+	}
+	return uow.timeSeries.ensure(ctx, uow.database, uow.collectionName)
+}
+
+// appendOnly rejects op on a time-series collection
+func (uow *UnitOfWork[T]) appendOnly(op string) error {
+	if uow.timeSeries == nil {
+		return nil
+	}
+	return fmt.Errorf("%w: %s on %s", uowerrors.ErrAppendOnly, op, uow.collectionName)
+}
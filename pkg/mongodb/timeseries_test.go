@@ -0,0 +1,91 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+)
+
+type TestMeasurement struct {
+	domain.BaseEntity `bson:",inline"`
+	Sensor            string    `bson:"sensor" json:"sensor"`
+	Value             float64   `bson:"value" json:"value"`
+	Timestamp         time.Time `bson:"timestamp" json:"timestamp"`
+}
+
+var measurementSeries = TimeSeriesOptions{TimeField: "timestamp", MetaField: "sensor", Granularity: "minutes"}
+
+func TestWithTimeSeries_Invalid(t *testing.T) {
+	_, err := NewFactory[*TestMeasurement](NewConfig(), WithTimeSeries(TimeSeriesOptions{}))
+	assert.ErrorContains(t, err, "time field is required")
+
+	_, err = NewFactory[*TestMeasurement](NewConfig(), WithTimeSeries(TimeSeriesOptions{TimeField: "timestamp", Granularity: "days"}))
+	assert.ErrorContains(t, err, "unknown granularity")
+}
+
+func TestUnitOfWork_TimeSeriesIsAppendOnly(t *testing.T) {
+	factory, err := NewFactory[*TestMeasurement](NewConfig(), WithTimeSeries(measurementSeries), WithTrashCollection())
+	require.NoError(t, err)
+
+	uow := newOfflineUnitOfWork[*TestMeasurement](t, &Config{EnableStats: true})
+	factory.apply(uow)
+	assert.False(t, uow.softDelete)
+	assert.False(t, uow.trashMode)
+
+	ctx := context.Background()
+	bySensor := identifier.New().Equal("sensor", "s1")
+	reading := &TestMeasurement{Sensor: "s1"}
+
+	_, err = uow.Update(ctx, bySensor, reading)
+	assert.ErrorIs(t, err, uowerrors.ErrAppendOnly)
+	_, err = uow.UpdateFields(ctx, bySensor, bson.M{"value": 1.5})
+	assert.ErrorIs(t, err, uowerrors.ErrAppendOnly)
+	_, err = uow.UpdateMany(ctx, bySensor, bson.M{"value": 1.5})
+	assert.ErrorIs(t, err, uowerrors.ErrAppendOnly)
+	_, err = uow.BulkUpdate(ctx, []*TestMeasurement{reading})
+	assert.ErrorIs(t, err, uowerrors.ErrAppendOnly)
+	_, err = uow.Upsert(ctx, bySensor, reading)
+	assert.ErrorIs(t, err, uowerrors.ErrAppendOnly)
+	_, err = uow.SoftDelete(ctx, bySensor)
+	assert.ErrorIs(t, err, uowerrors.ErrAppendOnly)
+	_, err = uow.SoftDeleteMany(ctx, bySensor)
+	assert.ErrorIs(t, err, uowerrors.ErrAppendOnly)
+	_, err = uow.BulkSoftDelete(ctx, []identifier.IIdentifier{bySensor})
+	assert.ErrorIs(t, err, uowerrors.ErrAppendOnly)
+
+	assert.Zero(t, uow.Stats().Total())
+}
+
+func TestUnitOfWork_TimeSeries_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestMeasurement](t, WithTimeSeries(measurementSeries))
+	ctx := context.Background()
+
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	_, err := uow.Insert(ctx, &TestMeasurement{Sensor: "s1", Value: 20.5, Timestamp: start})
+	require.NoError(t, err)
+	_, err = uow.BulkInsert(ctx, []*TestMeasurement{
+		{Sensor: "s1", Value: 21, Timestamp: start.Add(time.Minute)},
+		{Sensor: "s2", Value: 18, Timestamp: start.Add(time.Minute)},
+	})
+	require.NoError(t, err)
+
+	specs, err := uow.database.ListCollectionSpecifications(ctx, bson.M{"name": uow.collectionName})
+	require.NoError(t, err)
+	require.Len(t, specs, 1)
+	assert.Equal(t, "timeseries", specs[0].Type)
+
+	readings, err := uow.FindAllRaw(ctx, bson.M{"sensor": "s1"})
+	require.NoError(t, err)
+	require.Len(t, readings, 2)
+	for _, reading := range readings {
+		assert.False(t, reading.Timestamp.IsZero())
+	}
+}
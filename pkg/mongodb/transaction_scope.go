@@ -0,0 +1,58 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+)
+
+// WithTransaction runs fn inside one transaction on the factory client,
+// committing when fn returns nil and aborting otherwise, so writes to several
+// collections succeed or fail together. fn may run again when the server
+// reports a transient error.
+//
+// Units of work used inside fn must reuse the passed session: pass sc as the
+// context of every operation, and create them on the same client, with Create
+// on this factory or NewUnitOfWorkWithClient(config, sc.Client()) for other
+// entity types. Units of work with their own client or their own open
+// transaction do not take part in it.
+func (f *Factory[T]) WithTransaction(ctx context.Context, fn func(sc mongo.SessionContext) error) error {
+	client, err := f.sharedClient()
+	if err != nil {
+		return fmt.Errorf("%w: %w", uowerrors.ErrDatabaseConnection, err)
+	}
+
+	supported, err := clientSupportsTransactions(ctx, client)
+	if err != nil {
+		return err
+	}
+	if !supported && !f.settings.txFallback {
+		return uowerrors.ErrTransactionsUnsupported
+	}
+
+	session, err := client.StartSession()
+	if err != nil {
+		return fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	if !supported {
+		if f.settings.logger != nil {
+			f.settings.logger.WarnContext(ctx, "transactions unsupported, running without one",
+				slog.String("database", f.config.Database))
+		}
+		return fn(mongo.NewSessionContext(ctx, session))
+	}
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	}, transactionOptions(f.config))
+	if isTransactionExpired(err) {
+		return fmt.Errorf("%w: %w", uowerrors.ErrTransactionExpired, err)
+	}
+	return transactionsUnsupported(err)
+}
@@ -0,0 +1,72 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+)
+
+func TestFactory_WithTransaction_Unreachable(t *testing.T) {
+	factory, err := NewFactory[*TestUser](unreachableConfig())
+	require.NoError(t, err)
+
+	called := false
+	err = factory.WithTransaction(context.Background(), func(mongo.SessionContext) error {
+		called = true
+		return nil
+	})
+	assert.True(t, uowerrors.IsConnection(err))
+	assert.False(t, called)
+}
+
+func TestFactory_WithTransaction_RollsBackAllCollections_Integration(t *testing.T) {
+	users := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	config := NewConfig()
+	config.Database = users.database.Name()
+	factory, err := NewFactory[*TestUser](config)
+	require.NoError(t, err)
+	defer factory.Close(ctx)
+
+	supported, err := users.transactionsSupported(ctx)
+	require.NoError(t, err)
+	if !supported {
+		t.Skip("Transactions require a replica set")
+	}
+
+	errAbort := errors.New("abort")
+	err = factory.WithTransaction(ctx, func(sc mongo.SessionContext) error {
+		userUow := factory.Create()
+		postUow, err := NewUnitOfWorkWithClient[*TestPost](config, sc.Client())
+		if err != nil {
+			return err
+		}
+
+		user, err := userUow.Insert(sc, &TestUser{Email: "scoped@example.com"})
+		if err != nil {
+			return err
+		}
+		if _, err := postUow.Insert(sc, &TestPost{AuthorID: user.GetID()}); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	assert.ErrorIs(t, err, errAbort)
+
+	userCount, err := users.Count(ctx, nil)
+	require.NoError(t, err)
+	assert.Zero(t, userCount)
+
+	posts, err := NewUnitOfWorkWithClient[*TestPost](config, users.client)
+	require.NoError(t, err)
+	postCount, err := posts.Count(ctx, nil)
+	require.NoError(t, err)
+	assert.Zero(t, postCount)
+}
@@ -0,0 +1,65 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+)
+
+// WithTransactionFallback makes BeginTransaction on a standalone server, which
+// cannot run transactions, succeed without one: operations then apply
+// immediately and RollbackTransaction cannot undo them. Meant for local
+// development only.
+func WithTransactionFallback() FactoryOption {
+	return func(s *factorySettings) {
+		s.txFallback = true
+	}
+}
+
+// transactionsSupported asks the server whether it is a replica set member or
+// mongos, the deployments that can run transactions
+func (uow *UnitOfWork[T]) transactionsSupported(ctx context.Context) (bool, error) {
+	return clientSupportsTransactions(ctx, uow.client)
+}
+
+func clientSupportsTransactions(ctx context.Context, client *mongo.Client) (bool, error) {
+	var hello bson.M
+	err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		return false, fmt.Errorf("failed to detect deployment type: %w", err)
+	}
+	return helloSupportsTransactions(hello), nil
+}
+
+func helloSupportsTransactions(hello bson.M) bool {
+	if _, ok := hello["setName"]; ok {
+		return true
+	}
+	return hello["msg"] == "isdbgrid"
+}
+
+// isTransactionsUnsupported reports whether err is the IllegalOperation a
+// standalone server returns for operations inside a transaction
+func isTransactionsUnsupported(err error) bool {
+	var serverErr mongo.ServerError
+	if !errors.As(err, &serverErr) {
+		return false
+	}
+	return serverErr.HasErrorCode(20) &&
+		strings.Contains(err.Error(), "Transaction numbers are only allowed on a replica set member or mongos")
+}
+
+// transactionsUnsupported wraps err as ErrTransactionsUnsupported when it
+// comes from a standalone server
+func transactionsUnsupported(err error) error {
+	if isTransactionsUnsupported(err) {
+		return fmt.Errorf("%w: %v", uowerrors.ErrTransactionsUnsupported, err)
+	}
+	return err
+}
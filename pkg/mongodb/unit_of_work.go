@@ -2,31 +2,55 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"reflect"
 	"strings"
 	"sync"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
 )
 
 type UnitOfWork[T persistence.ModelConstraint] struct {
 	client         *mongo.Client
+	ownsClient     bool
 	database       *mongo.Database
 	session        mongo.Session
 	ctx            context.Context
 	repositories   map[string]interface{}
 	mu             sync.RWMutex
 	inTx           bool
+	inFallbackTx   bool
 	collectionName string
+	softDelete     bool
+	trashMode      bool
+	slugStrategy   SlugStrategy
+	slugAttempts   int
+	slugIndex      *slugIndexes
+	references     map[string]Reference
+	strictDecoding bool
+	strictSort     bool
+	txFallback     bool
+	logger         *slog.Logger
+	auditSink      AuditSink
+	txOptions      *options.TransactionOptions
+	encryption     *fieldEncryption
+	registry       *bsoncodec.Registry
+	stats          *operationCounters
+	timeSeries     *timeSeriesCollection
+	bulkBatchSize  int
 }
 
 func NewUnitOfWork[T domain.BaseModel](config *Config) (*UnitOfWork[T], error) {
@@ -34,43 +58,161 @@ func NewUnitOfWork[T domain.BaseModel](config *Config) (*UnitOfWork[T], error) {
 		return nil, fmt.Errorf("invalid config: %w", err)
 	}
 
+	client, err := connectClient(config, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	uow := newUnitOfWork[T](config, client)
+	uow.ownsClient = true
+	return uow, nil
+}
+
+// NewUnitOfWorkWithClient creates a unit of work on an already connected
+// client instead of dialing a new one. Close leaves the client connected; its
+// owner is responsible for disconnecting it.
+func NewUnitOfWorkWithClient[T domain.BaseModel](config *Config, client *mongo.Client) (*UnitOfWork[T], error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if client == nil {
+		return nil, fmt.Errorf("client is required")
+	}
+
+	return newUnitOfWork[T](config, client), nil
+}
+
+// connectClient dials MongoDB with the pool settings of config and verifies
+// the connection, encoding and decoding with registry when it is non-nil
+func connectClient(config *Config, registry *bsoncodec.Registry, pool *event.PoolMonitor, commands *event.CommandMonitor) (*mongo.Client, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
 	defer cancel()
 
+	clientOptions, err := newClientOptions(config)
+	if err != nil {
+		return nil, err
+	}
+	if registry != nil {
+		clientOptions.SetRegistry(registry)
+	}
+	if pool != nil {
+		clientOptions.SetPoolMonitor(pool)
+	}
+	if commands != nil {
+		clientOptions.SetMonitor(commands)
+	}
+
+	client, err := mongo.Connect(ctx, clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+
+	if err := client.Ping(ctx, nil); err != nil {
+		_ = client.Disconnect(context.Background())
+		return nil, fmt.Errorf("%w: failed to ping MongoDB: %w", uowerrors.ErrNotConnected, err)
+	}
+
+	return client, nil
+}
+
+// newClientOptions translates the pool, timeout, TLS, read and write settings
+// of config into client options
+func newClientOptions(config *Config) (*options.ClientOptions, error) {
 	clientOptions := options.Client().ApplyURI(config.ConnectionString())
 	clientOptions.SetMaxPoolSize(config.MaxPoolSize)
 	clientOptions.SetMinPoolSize(config.MinPoolSize)
 	clientOptions.SetMaxConnIdleTime(config.MaxIdleTime)
+	if config.ServerSelectionTimeout > 0 {
+		clientOptions.SetServerSelectionTimeout(config.ServerSelectionTimeout)
+	}
+	if config.ConnectTimeout > 0 {
+		clientOptions.SetConnectTimeout(config.ConnectTimeout)
+	}
 
-	client, err := mongo.Connect(ctx, clientOptions)
+	tlsConfig, err := config.tlsConfig()
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+		return nil, err
+	}
+	if tlsConfig != nil {
+		clientOptions.SetTLSConfig(tlsConfig)
 	}
 
-	if err := client.Ping(ctx, nil); err != nil {
-		return nil, fmt.Errorf("failed to ping MongoDB: %w", err)
+	rp, err := config.readPreference()
+	if err != nil {
+		return nil, err
+	}
+	if rp != nil {
+		clientOptions.SetReadPreference(rp)
 	}
 
-	database := client.Database(config.Database)
+	rc, err := config.readConcern()
+	if err != nil {
+		return nil, err
+	}
+	if rc != nil {
+		clientOptions.SetReadConcern(rc)
+	}
+
+	wc, err := config.writeConcern()
+	if err != nil {
+		return nil, err
+	}
+	if wc != nil {
+		clientOptions.SetWriteConcern(wc)
+	}
+
+	return clientOptions, clientOptions.Validate()
+}
 
+// newUnitOfWork builds a unit of work for T on client
+func newUnitOfWork[T domain.BaseModel](config *Config, client *mongo.Client) *UnitOfWork[T] {
 	var zero T
-	collectionName := getCollectionName(zero)
+
+	var stats *operationCounters
+	if config.EnableStats {
+		stats = &operationCounters{}
+	}
 
 	return &UnitOfWork[T]{
 		client:         client,
-		database:       database,
+		database:       client.Database(config.Database),
 		ctx:            context.Background(),
 		repositories:   make(map[string]interface{}),
-		collectionName: collectionName,
-	}, nil
+		collectionName: getCollectionName(zero),
+		softDelete:     supportsSoftDelete(zero),
+		slugAttempts:   defaultSlugAttempts,
+		slugIndex:      newSlugIndexes(),
+		txOptions:      transactionOptions(config),
+		stats:          stats,
+		bulkBatchSize:  config.BulkBatchSize,
+	}
 }
 
+// transactionOptions builds the options applied to every transaction started
+// by the unit of work
+func transactionOptions(config *Config) *options.TransactionOptions {
+	opts := options.Transaction()
+	if config.MaxCommitTime > 0 {
+		opts.SetMaxCommitTime(&config.MaxCommitTime)
+	}
+	return opts
+}
+
+// getCollectionName returns the name chosen by a domain.CollectionNamer model,
+// falling back to the lowercased type name with an "s" appended
 func getCollectionName(model interface{}) string {
 	t := reflect.TypeOf(model)
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
 
+	// A fresh value avoids calling the method on a nil pointer
+	if namer, ok := reflect.New(t).Interface().(domain.CollectionNamer); ok {
+		if name := namer.CollectionName(); name != "" {
+			return name
+		}
+	}
+
 	name := t.Name()
 
 	return strings.ToLower(name) + "s"
@@ -80,20 +222,67 @@ func (uow *UnitOfWork[T]) getCollection() *mongo.Collection {
 	return uow.database.Collection(uow.collectionName)
 }
 
+// notDeletedFilter selects live documents. deletedAt: null matches a missing
+// field as well as an explicit null and, unlike $exists: false, is allowed as
+// a partial index filter, so indexes built with ActiveIndex can serve these
+// reads. Every live-only filter is built from it.
+func notDeletedFilter() bson.M {
+	return bson.M{"deletedAt": nil}
+}
+
+// deletedCondition is the deletedAt condition selecting soft-deleted
+// documents, the complement of notDeletedFilter
+func deletedCondition() bson.M {
+	return bson.M{"$ne": nil}
+}
+
+// withNotDeleted adds notDeletedFilter to filter
+func withNotDeleted(filter bson.M) bson.M {
+	for k, v := range notDeletedFilter() {
+		filter[k] = v
+	}
+	return filter
+}
+
+// excludeDeleted adds the soft-delete condition to filter unless the model
+// type has opted out of soft deletion
+func (uow *UnitOfWork[T]) excludeDeleted(filter bson.M) bson.M {
+	if uow.filtersDeleted() {
+		return withNotDeleted(filter)
+	}
+	return filter
+}
+
 func (uow *UnitOfWork[T]) BeginTransaction(ctx context.Context) error {
 	uow.mu.Lock()
 	defer uow.mu.Unlock()
 
-	if uow.inTx {
+	if uow.inTx || uow.inFallbackTx {
 		return fmt.Errorf("transaction already in progress")
 	}
 
+	supported, err := uow.transactionsSupported(ctx)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		if !uow.txFallback {
+			return uowerrors.ErrTransactionsUnsupported
+		}
+		if uow.logger != nil {
+			uow.logger.WarnContext(ctx, "transactions unsupported, running without one",
+				slog.String("collection", uow.collectionName))
+		}
+		uow.inFallbackTx = true
+		return nil
+	}
+
 	session, err := uow.client.StartSession()
 	if err != nil {
 		return fmt.Errorf("failed to start session: %w", err)
 	}
 
-	err = session.StartTransaction()
+	err = session.StartTransaction(uow.txOptions)
 	if err != nil {
 		session.EndSession(ctx)
 		return fmt.Errorf("failed to start transaction: %w", err)
@@ -110,12 +299,19 @@ func (uow *UnitOfWork[T]) CommitTransaction(ctx context.Context) error {
 	uow.mu.Lock()
 	defer uow.mu.Unlock()
 
+	if uow.inFallbackTx {
+		uow.inFallbackTx = false
+		return nil
+	}
 	if !uow.inTx {
 		return fmt.Errorf("no transaction in progress")
 	}
 
 	err := uow.session.CommitTransaction(ctx)
 	if err != nil {
+		if isTransactionExpired(err) {
+			return fmt.Errorf("%w: %w", uowerrors.ErrTransactionExpired, err)
+		}
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
@@ -127,10 +323,16 @@ func (uow *UnitOfWork[T]) CommitTransaction(ctx context.Context) error {
 	return nil
 }
 
+// RollbackTransaction aborts the open transaction. Under
+// WithTransactionFallback the writes already applied are kept.
 func (uow *UnitOfWork[T]) RollbackTransaction(ctx context.Context) {
 	uow.mu.Lock()
 	defer uow.mu.Unlock()
 
+	if uow.inFallbackTx {
+		uow.inFallbackTx = false
+		return
+	}
 	if !uow.inTx {
 		return
 	}
@@ -142,19 +344,213 @@ func (uow *UnitOfWork[T]) RollbackTransaction(ctx context.Context) {
 	uow.inTx = false
 }
 
+// RunInTransaction runs fn in a new transaction, committing when it returns
+// nil and aborting otherwise. Unlike Begin/Commit, the whole transaction is
+// retried when the server labels an error TransientTransactionError or the
+// commit UnknownTransactionCommitResult, so fn may run more than once and
+// must pass its ctx to every operation. Inside a transaction opened with
+// BeginTransaction, fn joins it and runs once.
+func (uow *UnitOfWork[T]) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return uow.inTransaction(ctx, fn)
+}
+
+// transactionExpiredCodes are the server errors reported when a transaction
+// outlives its time limits: MaxTimeMSExpired when maxCommitTimeMS elapses and
+// TransactionExceededLifetimeLimitSeconds. NoSuchTransaction is left out, as
+// it also covers transactions aborted for other reasons.
+var transactionExpiredCodes = []int{50, 290}
+
+func isTransactionExpired(err error) bool {
+	var serverErr mongo.ServerError
+	if !errors.As(err, &serverErr) {
+		return false
+	}
+	for _, code := range transactionExpiredCodes {
+		if serverErr.HasErrorCode(code) {
+			return true
+		}
+	}
+	return false
+}
+
 func (uow *UnitOfWork[T]) FindAll(ctx context.Context) ([]T, error) {
-	collection := uow.getCollection()
+	var zero T
+	opts := options.Find()
+	if projection := listProjection(zero); projection != nil {
+		opts.SetProjection(projection)
+	}
+	return uow.findAllIn(ctx, uow.getCollection(), uow.excludeDeleted(bson.M{}), opts)
+}
 
-	filter := bson.M{"deletedAt": bson.M{"$exists": false}}
+// FindAllWithTrashed returns every entity, soft-deleted ones included with
+// DeletedAt set
+func (uow *UnitOfWork[T]) FindAllWithTrashed(ctx context.Context) ([]T, error) {
+	results, err := uow.findAll(ctx, bson.M{})
+	if err != nil || !uow.trashMode {
+		return results, err
+	}
 
-	cursor, err := collection.Find(uow.getContext(ctx), filter)
+	trashed, err := uow.findAllIn(ctx, uow.getTrashCollection(), bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	return append(results, trashed...), nil
+}
+
+// Count returns how many documents match identifier without loading them.
+// Soft-deleted documents are excluded unless identifier filters on
+// deletedAt; a nil identifier counts every live document.
+func (uow *UnitOfWork[T]) Count(ctx context.Context, identifier identifier.IIdentifier) (int64, error) {
+	filter := bson.M{}
+	if identifier != nil {
+		filter = identifier.ToBSON()
+	}
+	if identifier == nil || !identifier.Has("deletedAt") {
+		filter = uow.excludeDeleted(filter)
+	}
+
+	uow.track(opCount)
+	count, err := uow.getCollection().CountDocuments(uow.getContext(ctx), filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count documents: %w", err)
+	}
+	return count, nil
+}
+
+// Exists reports whether a document matches identifier, fetching only its
+// _id. A nil identifier matches any document. Soft-deleted documents are
+// excluded unless identifier filters on deletedAt.
+func (uow *UnitOfWork[T]) Exists(ctx context.Context, identifier identifier.IIdentifier) (bool, error) {
+	filter := bson.M{}
+	if identifier != nil {
+		filter = identifier.ToBSON()
+	}
+	if identifier == nil || !identifier.Has("deletedAt") {
+		filter = uow.excludeDeleted(filter)
+	}
+
+	uow.track(opFind)
+	err := uow.getCollection().FindOne(
+		uow.getContext(ctx),
+		filter,
+		options.FindOne().SetProjection(bson.M{"_id": 1}),
+	).Err()
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check existence: %w", err)
+	}
+	return true, nil
+}
+
+// Distinct returns the unique values of field among the documents matching
+// filter, which may be nil. Soft-deleted documents are always excluded.
+func (uow *UnitOfWork[T]) Distinct(ctx context.Context, field string, filter identifier.IIdentifier) ([]interface{}, error) {
+	query := bson.M{}
+	if filter != nil {
+		query = filter.ToBSON()
+	}
+
+	uow.track(opFind)
+	values, err := uow.getCollection().Distinct(uow.getContext(ctx), field, uow.excludeDeleted(query))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get distinct %s: %w", field, err)
+	}
+	return values, nil
+}
+
+// DistinctWithCounts returns the values of field among the documents matching
+// filter, which may be nil, with how many documents hold each, most common
+// first. Like Distinct, array elements count separately; documents missing
+// the field or holding null are skipped, and soft-deleted documents are
+// always excluded.
+func (uow *UnitOfWork[T]) DistinctWithCounts(ctx context.Context, field string, filter identifier.IIdentifier) ([]persistence.ValueCount, error) {
+	if field == "" || strings.HasPrefix(field, "$") {
+		return nil, fmt.Errorf("%w: invalid field %q", uowerrors.ErrInvalidQuery, field)
+	}
+	query := bson.M{}
+	if filter != nil {
+		query = filter.ToBSON()
+	}
+
+	uow.track(opFind)
+	queryCtx := uow.getContext(ctx)
+	cursor, err := uow.getCollection().Aggregate(queryCtx, valueCountsPipeline(field, uow.excludeDeleted(query)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count distinct %s: %w", field, err)
+	}
+	defer cursor.Close(queryCtx)
+
+	counts := []persistence.ValueCount{}
+	if err := cursor.All(queryCtx, &counts); err != nil {
+		return nil, fmt.Errorf("failed to decode distinct counts: %w", err)
+	}
+	return counts, nil
+}
+
+// valueCountsPipeline groups the documents matching filter by each value of
+// field, ties broken by value so the order is stable
+func valueCountsPipeline(field string, filter bson.M) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$unwind", Value: "$" + field}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$" + field},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "_id", Value: 0},
+			{Key: "value", Value: "$_id"},
+			{Key: "count", Value: 1},
+		}}},
+	}
+}
+
+// Aggregate runs pipeline over the collection and decodes every result into
+// result, a pointer to a slice. Soft-deleted documents are excluded from the
+// input by a leading $match stage.
+func (uow *UnitOfWork[T]) Aggregate(ctx context.Context, pipeline mongo.Pipeline, result interface{}) error {
+	uow.track(opFind)
+	queryCtx := uow.getContext(ctx)
+	cursor, err := uow.getCollection().Aggregate(queryCtx, uow.livePipeline(pipeline))
+	if err != nil {
+		return fmt.Errorf("failed to aggregate: %w", err)
+	}
+	defer cursor.Close(queryCtx)
+
+	if err := cursor.All(queryCtx, result); err != nil {
+		return fmt.Errorf("failed to decode aggregation results: %w", err)
+	}
+	return nil
+}
+
+// livePipeline prepends a $match excluding soft-deleted documents to pipeline
+// when the unit of work filters them
+func (uow *UnitOfWork[T]) livePipeline(pipeline mongo.Pipeline) mongo.Pipeline {
+	stages := make(mongo.Pipeline, 0, len(pipeline)+2)
+	if uow.filtersDeleted() {
+		stages = append(stages, bson.D{{Key: "$match", Value: uow.excludeDeleted(bson.M{})}})
+	}
+	return append(stages, pipeline...)
+}
+
+func (uow *UnitOfWork[T]) findAll(ctx context.Context, filter bson.M) ([]T, error) {
+	return uow.findAllIn(ctx, uow.getCollection(), filter)
+}
+
+func (uow *UnitOfWork[T]) findAllIn(ctx context.Context, collection *mongo.Collection, filter bson.M, opts ...*options.FindOptions) ([]T, error) {
+	uow.track(opFind)
+	queryCtx := uow.getContext(ctx)
+	cursor, err := collection.Find(queryCtx, filter, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find all: %w", err)
 	}
-	defer cursor.Close(ctx)
+	defer cursor.Close(queryCtx)
 
 	var results []T
-	if err := cursor.All(ctx, &results); err != nil {
+	if err := uow.decodeAll(queryCtx, cursor, &results); err != nil {
 		return nil, fmt.Errorf("failed to decode results: %w", err)
 	}
 
@@ -162,21 +558,156 @@ func (uow *UnitOfWork[T]) FindAll(ctx context.Context) ([]T, error) {
 }
 
 func (uow *UnitOfWork[T]) FindAllWithPagination(ctx context.Context, query domain.QueryParams[T]) ([]T, uint, error) {
-	collection := uow.getCollection()
+	collection, filter, err := uow.pageScope(query)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	filter := bson.M{"deletedAt": bson.M{"$exists": false}}
+	return uow.findPage(ctx, collection, filter, query)
+}
+
+// FindAllWithOptions is FindAllWithPagination with the trash mode taken from
+// opts, so soft-deleted records can be listed alongside or instead of live
+// ones
+func (uow *UnitOfWork[T]) FindAllWithOptions(ctx context.Context, query domain.QueryParams[T], opts domain.FindOptions) ([]T, uint, error) {
+	mode, err := findOptionsTrashMode(opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	if query.Trash != "" && query.Trash != mode {
+		return nil, 0, fmt.Errorf("%w: trash mode %q conflicts with find options", uowerrors.ErrInvalidQueryParams, query.Trash)
+	}
+	query.Trash = mode
+	return uow.FindAllWithPagination(ctx, query)
+}
+
+// findOptionsTrashMode returns the trash mode opts selects
+func findOptionsTrashMode(opts domain.FindOptions) (domain.TrashMode, error) {
+	switch {
+	case opts.IncludeTrashed && opts.OnlyTrashed:
+		return "", fmt.Errorf("%w: IncludeTrashed and OnlyTrashed are mutually exclusive", uowerrors.ErrInvalidQueryParams)
+	case opts.IncludeTrashed:
+		return domain.All, nil
+	case opts.OnlyTrashed:
+		return domain.TrashedOnly, nil
+	default:
+		return domain.LiveOnly, nil
+	}
+}
+
+// pageScope returns the collection and filter FindAllWithPagination queries
+func (uow *UnitOfWork[T]) pageScope(query domain.QueryParams[T]) (*mongo.Collection, bson.M, error) {
+	collection, filter, err := uow.trashScope(query.Trash)
+	if err != nil {
+		return nil, nil, err
+	}
 	if !isZeroValue(query.Filter) {
 		filterBSON := uow.buildFilterFromModel(query.Filter)
 		for k, v := range filterBSON {
 			filter[k] = v
 		}
 	}
+	return collection, filter, nil
+}
 
-	total, err := collection.CountDocuments(uow.getContext(ctx), filter)
+// trashScope returns the collection and deletedAt condition that select the
+// records mode asks for
+func (uow *UnitOfWork[T]) trashScope(mode domain.TrashMode) (*mongo.Collection, bson.M, error) {
+	switch mode {
+	case "", domain.LiveOnly:
+		return uow.getCollection(), uow.excludeDeleted(bson.M{}), nil
+	case domain.TrashedOnly:
+		if uow.trashMode {
+			return uow.getTrashCollection(), bson.M{}, nil
+		}
+		return uow.getCollection(), bson.M{"deletedAt": deletedCondition()}, nil
+	case domain.All:
+		if uow.trashMode {
+			return nil, nil, fmt.Errorf("%w: trash mode %q cannot span the trash collection", uowerrors.ErrInvalidQueryParams, mode)
+		}
+		return uow.getCollection(), bson.M{}, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: unknown trash mode %q", uowerrors.ErrInvalidQueryParams, mode)
+	}
+}
+
+// findPage counts the documents matching filter and returns the page of them
+// described by query
+func (uow *UnitOfWork[T]) findPage(ctx context.Context, collection *mongo.Collection, filter bson.M, query domain.QueryParams[T]) ([]T, uint, error) {
+	if err := uow.validatePage(query); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := uow.countPage(ctx, collection, filter, query)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count documents: %w", err)
 	}
 
+	if needsAggregate(query) {
+		results, err := uow.findAggregate(ctx, collection, filter, query)
+		if err != nil {
+			return nil, 0, err
+		}
+		return results, uint(total), nil
+	}
+
+	uow.track(opFind)
+	queryCtx := uow.getContext(ctx)
+	cursor, err := collection.Find(queryCtx, filter, pageOptions(query))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find with pagination: %w", err)
+	}
+	defer cursor.Close(queryCtx)
+
+	var results []T
+	if err := uow.decodeAll(queryCtx, cursor, &results); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode results: %w", err)
+	}
+
+	return results, uint(total), nil
+}
+
+func (uow *UnitOfWork[T]) validatePage(query domain.QueryParams[T]) error {
+	switch query.Count {
+	case "", domain.CountExact, domain.CountEstimated:
+	default:
+		return fmt.Errorf("%w: unknown count mode %q", uowerrors.ErrInvalidQueryParams, query.Count)
+	}
+	if err := uow.validateSort(query); err != nil {
+		return err
+	}
+	if err := uow.validateIncludes(query.Include); err != nil {
+		return err
+	}
+	return validateArraySort(query.ArraySort)
+}
+
+// countPage returns the total of a page query
+func (uow *UnitOfWork[T]) countPage(ctx context.Context, collection *mongo.Collection, filter bson.M, query domain.QueryParams[T]) (int64, error) {
+	uow.track(opCount)
+	if uow.usesEstimatedCount(query, filter) {
+		return collection.EstimatedDocumentCount(ctx)
+	}
+	return collection.CountDocuments(uow.getContext(ctx), filter)
+}
+
+// usesEstimatedCount reports whether the total of query can come from
+// collection metadata: CountEstimated was asked for, filter is empty, and no
+// transaction is open, since transactions reject the count command. The
+// implicit live-only filter counts as a filter, as the estimate would include
+// soft-deleted documents.
+func (uow *UnitOfWork[T]) usesEstimatedCount(query domain.QueryParams[T], filter bson.M) bool {
+	return query.Count == domain.CountEstimated && !uow.inTx && len(filter) == 0
+}
+
+// needsAggregate reports whether the page must be read with an aggregation
+// rather than a find
+func needsAggregate[T persistence.ModelConstraint](query domain.QueryParams[T]) bool {
+	return len(query.Include) > 0 || len(query.ArraySort) > 0
+}
+
+// pageOptions builds the find options for a page of query
+func pageOptions[T persistence.ModelConstraint](query domain.QueryParams[T]) *options.FindOptions {
 	opts := options.Find()
 	if query.Limit > 0 {
 		opts.SetLimit(int64(query.Limit))
@@ -185,30 +716,36 @@ func (uow *UnitOfWork[T]) FindAllWithPagination(ctx context.Context, query domai
 		opts.SetSkip(int64(query.Offset))
 	}
 
-	if query.Sort != nil && len(query.Sort) > 0 {
-		sort := bson.D{}
-		for field, direction := range query.Sort {
-			if direction == domain.SortAsc {
-				sort = append(sort, bson.E{Key: field, Value: 1})
-			} else {
-				sort = append(sort, bson.E{Key: field, Value: -1})
-			}
-		}
+	if sort := sortFromMap(query.Sort); len(sort) > 0 {
 		opts.SetSort(sort)
 	}
 
-	cursor, err := collection.Find(uow.getContext(ctx), filter, opts)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to find with pagination: %w", err)
+	var zero T
+	if projection := listProjection(zero); projection != nil {
+		opts.SetProjection(projection)
 	}
-	defer cursor.Close(ctx)
+	return opts
+}
 
-	var results []T
-	if err := cursor.All(ctx, &results); err != nil {
-		return nil, 0, fmt.Errorf("failed to decode results: %w", err)
+// validateSort enforces WithStrictSort
+func (uow *UnitOfWork[T]) validateSort(query domain.QueryParams[T]) error {
+	if uow.strictSort && len(query.Sort) == 0 && len(query.ArraySort) == 0 {
+		return uowerrors.ErrSortRequired
 	}
+	return nil
+}
 
-	return results, uint(total), nil
+// sortFromMap converts a SortMap into a driver sort document
+func sortFromMap(sortMap domain.SortMap) bson.D {
+	sort := bson.D{}
+	for field, direction := range sortMap {
+		if direction == domain.SortAsc {
+			sort = append(sort, bson.E{Key: field, Value: 1})
+		} else {
+			sort = append(sort, bson.E{Key: field, Value: -1})
+		}
+	}
+	return sort
 }
 
 func (uow *UnitOfWork[T]) FindOne(ctx context.Context, filter T) (T, error) {
@@ -217,10 +754,11 @@ func (uow *UnitOfWork[T]) FindOne(ctx context.Context, filter T) (T, error) {
 
 	filterBSON := uow.buildFilterFromModel(filter)
 
-	filterBSON["deletedAt"] = bson.M{"$exists": false}
+	uow.excludeDeleted(filterBSON)
 
 	var result T
-	err := collection.FindOne(uow.getContext(ctx), filterBSON).Decode(&result)
+	uow.track(opFind)
+	err := uow.decode(collection.FindOne(uow.getContext(ctx), filterBSON), &result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return zero, fmt.Errorf("entity not found")
@@ -232,16 +770,33 @@ func (uow *UnitOfWork[T]) FindOne(ctx context.Context, filter T) (T, error) {
 }
 
 func (uow *UnitOfWork[T]) FindOneById(ctx context.Context, id primitive.ObjectID) (T, error) {
-	var zero T
-	collection := uow.getCollection()
+	return uow.findOneById(ctx, uow.excludeDeleted(bson.M{"_id": id}))
+}
 
-	filter := bson.M{
-		"_id":       id,
-		"deletedAt": bson.M{"$exists": false},
+// FindOneByIdWithTrashed finds an entity by ID whether or not it is soft
+// deleted; IsDeleted reports which on the returned entity
+func (uow *UnitOfWork[T]) FindOneByIdWithTrashed(ctx context.Context, id primitive.ObjectID) (T, error) {
+	if !uow.trashMode {
+		return uow.findOneById(ctx, bson.M{"_id": id})
 	}
 
+	entity, found, err := uow.tryFindOne(ctx, bson.M{"_id": id})
+	if err != nil || found {
+		return entity, err
+	}
+	return uow.findOneIn(ctx, uow.getTrashCollection(), bson.M{"_id": id})
+}
+
+func (uow *UnitOfWork[T]) findOneById(ctx context.Context, filter bson.M) (T, error) {
+	return uow.findOneIn(ctx, uow.getCollection(), filter)
+}
+
+func (uow *UnitOfWork[T]) findOneIn(ctx context.Context, collection *mongo.Collection, filter bson.M) (T, error) {
+	var zero T
+
 	var result T
-	err := collection.FindOne(uow.getContext(ctx), filter).Decode(&result)
+	uow.track(opFind)
+	err := uow.decode(collection.FindOne(uow.getContext(ctx), filter), &result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return zero, fmt.Errorf("entity not found")
@@ -252,6 +807,50 @@ func (uow *UnitOfWork[T]) FindOneById(ctx context.Context, id primitive.ObjectID
 	return result, nil
 }
 
+// FindByIds returns the entities whose IDs are in ids with a single $in query.
+// Missing IDs are skipped and the order of the result is unspecified.
+func (uow *UnitOfWork[T]) FindByIds(ctx context.Context, ids []primitive.ObjectID) ([]T, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return uow.findAll(ctx, uow.excludeDeleted(bson.M{"_id": bson.M{"$in": ids}}))
+}
+
+// FindByIdsOrdered is FindByIds with the result aligned to ids: element i is
+// the entity with ids[i], or the zero value (nil for pointer models) when it
+// is missing or soft deleted. Repeated IDs repeat the entity.
+func (uow *UnitOfWork[T]) FindByIdsOrdered(ctx context.Context, ids []primitive.ObjectID) ([]T, error) {
+	entities, err := uow.FindByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return alignByID(ids, entities), nil
+}
+
+// alignByID orders entities to match ids, leaving the zero value for IDs
+// without an entity
+func alignByID[T domain.BaseModel](ids []primitive.ObjectID, entities []T) []T {
+	byID := make(map[primitive.ObjectID]T, len(entities))
+	for _, entity := range entities {
+		byID[entity.GetID()] = entity
+	}
+
+	aligned := make([]T, len(ids))
+	for i, id := range ids {
+		aligned[i] = byID[id]
+	}
+	return aligned
+}
+
+func (uow *UnitOfWork[T]) FindOneByHexId(ctx context.Context, hexID string) (T, error) {
+	id, err := parseHexID(hexID)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return uow.FindOneById(ctx, id)
+}
+
 func (uow *UnitOfWork[T]) FindOneByIdentifier(ctx context.Context, identifier identifier.IIdentifier) (T, error) {
 	var zero T
 	collection := uow.getCollection()
@@ -259,11 +858,12 @@ func (uow *UnitOfWork[T]) FindOneByIdentifier(ctx context.Context, identifier id
 	filter := identifier.ToBSON()
 
 	if !identifier.Has("deletedAt") {
-		filter["deletedAt"] = bson.M{"$exists": false}
+		uow.excludeDeleted(filter)
 	}
 
 	var result T
-	err := collection.FindOne(uow.getContext(ctx), filter).Decode(&result)
+	uow.track(opFind)
+	err := uow.decode(collection.FindOne(uow.getContext(ctx), filter), &result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return zero, fmt.Errorf("entity not found")
@@ -274,15 +874,152 @@ func (uow *UnitOfWork[T]) FindOneByIdentifier(ctx context.Context, identifier id
 	return result, nil
 }
 
-func (uow *UnitOfWork[T]) ResolveIDByUniqueField(ctx context.Context, model domain.BaseModel, field string, value interface{}) (primitive.ObjectID, error) {
+func (uow *UnitOfWork[T]) TryFindOneById(ctx context.Context, id primitive.ObjectID) (T, bool, error) {
+	filter := uow.excludeDeleted(bson.M{"_id": id})
+
+	return uow.tryFindOne(ctx, filter)
+}
+
+func (uow *UnitOfWork[T]) TryFindOne(ctx context.Context, identifier identifier.IIdentifier) (T, bool, error) {
+	filter := identifier.ToBSON()
+
+	if !identifier.Has("deletedAt") {
+		uow.excludeDeleted(filter)
+	}
+
+	return uow.tryFindOne(ctx, filter)
+}
+
+// tryFindOne returns found=false with a nil error when nothing matches, so
+// only real failures surface as errors.
+func (uow *UnitOfWork[T]) tryFindOne(ctx context.Context, filter bson.M) (T, bool, error) {
+	var zero T
+	collection := uow.getCollection()
+
+	var result T
+	uow.track(opFind)
+	err := uow.decode(collection.FindOne(uow.getContext(ctx), filter), &result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return zero, false, nil
+		}
+		return zero, false, fmt.Errorf("failed to find one: %w", err)
+	}
+
+	return result, true, nil
+}
+
+func (uow *UnitOfWork[T]) FindAllRaw(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]T, error) {
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if err := ValidateProjection(opt.Projection); err != nil {
+			return nil, err
+		}
+	}
+
 	collection := uow.getCollection()
 
-	filter := bson.M{
-		field:       value,
-		"deletedAt": bson.M{"$exists": false},
+	query := filter
+	if uow.filtersDeleted() {
+		query = rawFilter(filter)
 	}
 
+	uow.track(opFind)
+	queryCtx := uow.getContext(ctx)
+	cursor, err := collection.Find(queryCtx, query, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find raw: %w", err)
+	}
+	defer cursor.Close(queryCtx)
+
+	var results []T
+	if err := uow.decodeAll(queryCtx, cursor, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode results: %w", err)
+	}
+
+	return results, nil
+}
+
+func (uow *UnitOfWork[T]) FindOneRaw(ctx context.Context, filter bson.M) (T, error) {
+	var zero T
+	collection := uow.getCollection()
+
+	query := filter
+	if uow.filtersDeleted() {
+		query = rawFilter(filter)
+	}
+
+	var result T
+	uow.track(opFind)
+	err := uow.decode(collection.FindOne(uow.getContext(ctx), query), &result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return zero, fmt.Errorf("entity not found")
+		}
+		return zero, fmt.Errorf("failed to find raw: %w", err)
+	}
+
+	return result, nil
+}
+
+// rawFilter copies a caller-supplied filter and adds the soft-delete
+// condition unless the filter already mentions deletedAt at any depth.
+func rawFilter(filter bson.M) bson.M {
+	query := bson.M{}
+	for k, v := range filter {
+		query[k] = v
+	}
+
+	if !referencesField(filter, "deletedAt") {
+		withNotDeleted(query)
+	}
+
+	return query
+}
+
+func referencesField(value interface{}, field string) bool {
+	switch v := value.(type) {
+	case bson.M:
+		for k, nested := range v {
+			if k == field || referencesField(nested, field) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		return referencesField(bson.M(v), field)
+	case bson.D:
+		for _, e := range v {
+			if e.Key == field || referencesField(e.Value, field) {
+				return true
+			}
+		}
+	case bson.A:
+		for _, nested := range v {
+			if referencesField(nested, field) {
+				return true
+			}
+		}
+	case []interface{}:
+		return referencesField(bson.A(v), field)
+	case []bson.M:
+		for _, nested := range v {
+			if referencesField(nested, field) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (uow *UnitOfWork[T]) ResolveIDByUniqueField(ctx context.Context, model domain.BaseModel, field string, value interface{}) (primitive.ObjectID, error) {
+	collection := uow.getCollection()
+
+	filter := uow.excludeDeleted(bson.M{field: value})
+
 	var result bson.M
+	uow.track(opFind)
 	err := collection.FindOne(uow.getContext(ctx), filter, options.FindOne().SetProjection(bson.M{"_id": 1})).Decode(&result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -300,9 +1037,14 @@ func (uow *UnitOfWork[T]) ResolveIDByUniqueField(ctx context.Context, model doma
 }
 
 func (uow *UnitOfWork[T]) Insert(ctx context.Context, entity T) (T, error) {
-	collection := uow.getCollection()
+	if err := checkEntity(entity); err != nil {
+		return entity, err
+	}
+	if err := uow.ensureTimeSeries(ctx); err != nil {
+		return entity, err
+	}
 
-	now := time.Now()
+	now := utcNow()
 	uow.setEntityTimestamp(entity, "createdAt", now)
 	uow.setEntityTimestamp(entity, "updatedAt", now)
 
@@ -310,34 +1052,87 @@ func (uow *UnitOfWork[T]) Insert(ctx context.Context, entity T) (T, error) {
 		entity.SetID(primitive.NewObjectID())
 	}
 
-	_, err := collection.InsertOne(uow.getContext(ctx), entity)
-	if err != nil {
-		return entity, fmt.Errorf("failed to insert: %w", err)
+	normalizeFields(entity)
+	if _, err := uow.stampContentHash(entity); err != nil {
+		return entity, err
+	}
+
+	if err := uow.insertOne(ctx, entity); err != nil {
+		return entity, fmt.Errorf("failed to insert: %w", duplicateKey(err))
 	}
 
 	return entity, nil
 }
 
 func (uow *UnitOfWork[T]) Update(ctx context.Context, identifier identifier.IIdentifier, entity T) (T, error) {
+	if uow.auditSink != nil {
+		return uow.auditedUpdate(ctx, identifier, entity)
+	}
+	return uow.update(ctx, identifier, entity, options.After)
+}
+
+// UpdateReturningBefore applies the same update as Update but returns the
+// document as it was before the update, e.g. for computing diffs
+func (uow *UnitOfWork[T]) UpdateReturningBefore(ctx context.Context, identifier identifier.IIdentifier, entity T) (T, error) {
+	return uow.update(ctx, identifier, entity, options.Before)
+}
+
+// UpdateModified applies the same update as Update and also reports whether
+// any field other than updatedAt actually changed, so callers can tell a
+// no-op write of identical values from a real change. When the change cannot
+// be determined the write is reported as modified.
+func (uow *UnitOfWork[T]) UpdateModified(ctx context.Context, identifier identifier.IIdentifier, entity T) (T, bool, error) {
+	before, updated, changes, err := uow.diffedUpdate(ctx, identifier, entity)
+	if err != nil {
+		return before, false, err
+	}
+	if changes == nil {
+		return updated, true, nil
+	}
+
+	if uow.auditSink != nil {
+		uow.audit(ctx, before, changes)
+	}
+
+	for field := range changes {
+		if field != "updatedAt" {
+			return updated, true, nil
+		}
+	}
+	return updated, false, nil
+}
+
+func (uow *UnitOfWork[T]) update(ctx context.Context, identifier identifier.IIdentifier, entity T, returnDocument options.ReturnDocument) (T, error) {
+	if err := checkEntity(entity); err != nil {
+		return entity, err
+	}
+	if err := uow.appendOnly("update"); err != nil {
+		return entity, err
+	}
+
 	collection := uow.getCollection()
 
-	filter := identifier.ToBSON()
+	filter := uow.excludeDeleted(identifier.ToBSON())
 
-	filter["deletedAt"] = bson.M{"$exists": false}
+	uow.setEntityTimestamp(entity, "updatedAt", utcNow())
 
-	uow.setEntityTimestamp(entity, "updatedAt", time.Now())
+	normalizeFields(entity)
+	if _, err := uow.stampContentHash(entity); err != nil {
+		return entity, err
+	}
 
 	update := bson.M{"$set": entity}
 
+	uow.track(opUpdate)
 	result := collection.FindOneAndUpdate(
 		uow.getContext(ctx),
 		filter,
 		update,
-		options.FindOneAndUpdate().SetReturnDocument(options.After),
+		options.FindOneAndUpdate().SetReturnDocument(returnDocument),
 	)
 
 	var updated T
-	if err := result.Decode(&updated); err != nil {
+	if err := uow.decode(result, &updated); err != nil {
 		if err == mongo.ErrNoDocuments {
 			return entity, fmt.Errorf("entity not found")
 		}
@@ -347,11 +1142,198 @@ func (uow *UnitOfWork[T]) Update(ctx context.Context, identifier identifier.IIde
 	return updated, nil
 }
 
+// UpdateFields sets only the given fields on the live entity matched by
+// identifier and returns it after the update. Unlike Update, fields left out
+// of the map keep their stored values, zero values included.
+func (uow *UnitOfWork[T]) UpdateFields(ctx context.Context, identifier identifier.IIdentifier, fields bson.M) (T, error) {
+	var zero T
+
+	set, err := uow.setFields(fields)
+	if err != nil {
+		return zero, err
+	}
+
+	filter := identifier.ToBSON()
+	if !identifier.Has("deletedAt") {
+		filter = uow.excludeDeleted(filter)
+	}
+
+	uow.track(opUpdate)
+	result := uow.getCollection().FindOneAndUpdate(
+		uow.getContext(ctx),
+		filter,
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+
+	var updated T
+	if err := uow.decode(result, &updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return zero, fmt.Errorf("entity not found")
+		}
+		return zero, fmt.Errorf("failed to update fields: %w", err)
+	}
+
+	return updated, nil
+}
+
+// setFields builds the $set document for a field map. _id and createdAt are
+// immutable and rejected, updatedAt is always refreshed, and normalized and
+// encrypted fields are treated as they would be when writing the entity.
+func (uow *UnitOfWork[T]) setFields(fields bson.M) (bson.M, error) {
+	if err := uow.appendOnly("update"); err != nil {
+		return nil, err
+	}
+	set := bson.M{}
+	for k, v := range fields {
+		if k == "_id" || k == "createdAt" {
+			return nil, fmt.Errorf("%w: %s cannot be updated", uowerrors.ErrInvalidQuery, k)
+		}
+		set[k] = v
+	}
+	var zero T
+	normalizeSet(zero, set)
+	if uow.encryption != nil {
+		if err := uow.encryption.encryptFields(set); err != nil {
+			return nil, err
+		}
+	}
+	set["updatedAt"] = utcNow()
+	return set, nil
+}
+
+// UpdateIf atomically sets fields on the entity matched by identifier, but only
+// while condition also holds. applied is false when nothing matched both.
+func (uow *UnitOfWork[T]) UpdateIf(ctx context.Context, identifier identifier.IIdentifier, condition identifier.IIdentifier, fields bson.M) (T, bool, error) {
+	var zero T
+	collection := uow.getCollection()
+
+	filter := uow.excludeDeleted(bson.M{
+		"$and": bson.A{identifier.ToBSON(), condition.ToBSON()},
+	})
+
+	set, err := uow.setFields(fields)
+	if err != nil {
+		return zero, false, err
+	}
+
+	uow.track(opUpdate)
+	result := collection.FindOneAndUpdate(
+		uow.getContext(ctx),
+		filter,
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+
+	var updated T
+	if err := uow.decode(result, &updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return zero, false, nil
+		}
+		return zero, false, fmt.Errorf("failed to conditionally update: %w", err)
+	}
+
+	return updated, true, nil
+}
+
+// UpdateWithArrayFilters applies update, a document of update operators, to
+// the live entity matched by identifier and returns it after the update.
+// arrayFilters bind the identifiers used in $[<identifier>] paths, so a single
+// array element can be changed, e.g. {"$set": {"items.$[item].qty": 2}} with
+// {"item.sku": "A1"}. $set fields go through the same checks as UpdateFields.
+func (uow *UnitOfWork[T]) UpdateWithArrayFilters(ctx context.Context, identifier identifier.IIdentifier, update bson.M, arrayFilters []interface{}) (T, error) {
+	var zero T
+
+	update, err := uow.operatorUpdate(update)
+	if err != nil {
+		return zero, err
+	}
+
+	filter := identifier.ToBSON()
+	if !identifier.Has("deletedAt") {
+		filter = uow.excludeDeleted(filter)
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	if len(arrayFilters) > 0 {
+		opts.SetArrayFilters(options.ArrayFilters{Filters: arrayFilters})
+	}
+
+	uow.track(opUpdate)
+	result := uow.getCollection().FindOneAndUpdate(uow.getContext(ctx), filter, update, opts)
+
+	var updated T
+	if err := uow.decode(result, &updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return zero, fmt.Errorf("entity not found")
+		}
+		return zero, fmt.Errorf("failed to update with array filters: %w", err)
+	}
+
+	return updated, nil
+}
+
+// operatorUpdate validates an update made of operators and returns a copy
+// whose $set has passed through setFields, refreshing updatedAt
+func (uow *UnitOfWork[T]) operatorUpdate(update bson.M) (bson.M, error) {
+	if len(update) == 0 {
+		return nil, fmt.Errorf("%w: update is empty", uowerrors.ErrInvalidQuery)
+	}
+
+	fields := bson.M{}
+	result := bson.M{}
+	for op, value := range update {
+		if !strings.HasPrefix(op, "$") {
+			return nil, fmt.Errorf("%w: update key %q is not an operator", uowerrors.ErrInvalidQuery, op)
+		}
+		if op != "$set" {
+			result[op] = value
+			continue
+		}
+		set, ok := value.(bson.M)
+		if !ok {
+			return nil, fmt.Errorf("%w: $set must be a bson.M, got %T", uowerrors.ErrInvalidQuery, value)
+		}
+		fields = set
+	}
+
+	set, err := uow.setFields(fields)
+	if err != nil {
+		return nil, err
+	}
+	result["$set"] = set
+	return result, nil
+}
+
+// UpdateMany sets fields on every live entity matched by identifier in a
+// single command and returns how many matched. Each document is updated
+// atomically; use a transaction to make the whole set all-or-nothing.
+func (uow *UnitOfWork[T]) UpdateMany(ctx context.Context, identifier identifier.IIdentifier, fields bson.M) (int64, error) {
+	filter := identifier.ToBSON()
+	if !identifier.Has("deletedAt") {
+		filter = uow.excludeDeleted(filter)
+	}
+
+	set, err := uow.setFields(fields)
+	if err != nil {
+		return 0, err
+	}
+
+	uow.track(opUpdate)
+	result, err := uow.getCollection().UpdateMany(uow.getContext(ctx), filter, bson.M{"$set": set})
+	if err != nil {
+		return 0, fmt.Errorf("failed to update many: %w", err)
+	}
+
+	return result.MatchedCount, nil
+}
+
 func (uow *UnitOfWork[T]) Delete(ctx context.Context, identifier identifier.IIdentifier) error {
 	collection := uow.getCollection()
 
 	filter := identifier.ToBSON()
 
+	uow.track(opDelete)
 	result, err := collection.DeleteOne(uow.getContext(ctx), filter)
 	if err != nil {
 		return fmt.Errorf("failed to delete: %w", err)
@@ -365,28 +1347,50 @@ func (uow *UnitOfWork[T]) Delete(ctx context.Context, identifier identifier.IIde
 }
 
 func (uow *UnitOfWork[T]) SoftDelete(ctx context.Context, identifier identifier.IIdentifier) (T, error) {
+	return uow.softDeleteOne(ctx, identifier, options.After)
+}
+
+// SoftDeleteReturningBefore soft deletes like SoftDelete but returns the
+// document as it was before deletedAt was set
+func (uow *UnitOfWork[T]) SoftDeleteReturningBefore(ctx context.Context, identifier identifier.IIdentifier) (T, error) {
+	return uow.softDeleteOne(ctx, identifier, options.Before)
+}
+
+func (uow *UnitOfWork[T]) softDeleteOne(ctx context.Context, identifier identifier.IIdentifier, returnDocument options.ReturnDocument) (T, error) {
+	if err := uow.appendOnly("soft delete"); err != nil {
+		var zero T
+		return zero, err
+	}
+	if uow.trashMode {
+		return uow.trashOne(ctx, identifier, returnDocument == options.Before)
+	}
+	if !uow.softDelete {
+		return uow.HardDelete(ctx, identifier)
+	}
+
 	var zero T
 	collection := uow.getCollection()
 
-	filter := identifier.ToBSON()
-	filter["deletedAt"] = bson.M{"$exists": false}
+	filter := withNotDeleted(identifier.ToBSON())
 
+	now := utcNow()
 	update := bson.M{
 		"$set": bson.M{
-			"deletedAt": time.Now(),
-			"updatedAt": time.Now(),
+			"deletedAt": now,
+			"updatedAt": now,
 		},
 	}
 
+	uow.track(opUpdate)
 	result := collection.FindOneAndUpdate(
 		uow.getContext(ctx),
 		filter,
 		update,
-		options.FindOneAndUpdate().SetReturnDocument(options.After),
+		options.FindOneAndUpdate().SetReturnDocument(returnDocument),
 	)
 
 	var updated T
-	if err := result.Decode(&updated); err != nil {
+	if err := uow.decode(result, &updated); err != nil {
 		if err == mongo.ErrNoDocuments {
 			return zero, fmt.Errorf("entity not found")
 		}
@@ -403,7 +1407,8 @@ func (uow *UnitOfWork[T]) HardDelete(ctx context.Context, identifier identifier.
 	filter := identifier.ToBSON()
 
 	var deleted T
-	err := collection.FindOneAndDelete(uow.getContext(ctx), filter).Decode(&deleted)
+	uow.track(opDelete)
+	err := uow.decode(collection.FindOneAndDelete(uow.getContext(ctx), filter), &deleted)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return zero, fmt.Errorf("entity not found")
@@ -414,9 +1419,12 @@ func (uow *UnitOfWork[T]) HardDelete(ctx context.Context, identifier identifier.
 	return deleted, nil
 }
 
+// getContext returns the context an operation runs with: inside a
+// transaction, ctx bound to the transaction session, so the caller's deadline
+// and cancellation still apply
 func (uow *UnitOfWork[T]) getContext(ctx context.Context) context.Context {
 	if uow.inTx && uow.session != nil {
-		return uow.ctx
+		return mongo.NewSessionContext(ctx, uow.session)
 	}
 	return ctx
 }
@@ -429,28 +1437,88 @@ func (uow *UnitOfWork[T]) buildFilterFromModel(model T) bson.M {
 		v = v.Elem()
 	}
 
-	t := v.Type()
-	for i := 0; i < v.NumField(); i++ {
-		field := v.Field(i)
-		fieldType := t.Field(i)
+	for _, meta := range topLevelFieldsFor(v.Type()) {
+		field := v.FieldByIndex(meta.index)
+		if field.IsZero() {
+			continue
+		}
+
+		filter[meta.name] = field.Interface()
+	}
+
+	if uow.encryption != nil {
+		uow.encryption.encryptFilter(filter)
+	}
+
+	return filter
+}
+
+// filterField is the cached metadata for one filterable struct field
+type filterField struct {
+	index []int
+	name  string
+	// encrypt is the value of the encrypt struct tag, if any
+	encrypt string
+}
+
+// filterFieldCache maps reflect.Type to its flattened []filterField, and
+// topLevelFieldCache to its []filterField without recursion
+var filterFieldCache, topLevelFieldCache sync.Map
 
-		if !field.CanInterface() {
+// filterFieldsFor returns the fields of t with inline embedded structs
+// flattened, computing them once per type
+func filterFieldsFor(t reflect.Type) []filterField {
+	if cached, ok := filterFieldCache.Load(t); ok {
+		return cached.([]filterField)
+	}
+
+	fields, _ := filterFieldCache.LoadOrStore(t, collectFilterFields(t, nil, true))
+	return fields.([]filterField)
+}
+
+// topLevelFieldsFor returns the direct fields of t, computing them once per
+// type. An inline embedded struct is a single field named by its empty tag
+// name, as buildFilterFromModel has always treated it.
+func topLevelFieldsFor(t reflect.Type) []filterField {
+	if cached, ok := topLevelFieldCache.Load(t); ok {
+		return cached.([]filterField)
+	}
+
+	fields, _ := topLevelFieldCache.LoadOrStore(t, collectFilterFields(t, nil, false))
+	return fields.([]filterField)
+}
+
+// collectFilterFields resolves the bson name of each exported field and, when
+// flatten is set, recurses into inline embedded structs so their fields
+// appear at top level
+func collectFilterFields(t reflect.Type, parent []int, flatten bool) []filterField {
+	var fields []filterField
+	for i := 0; i < t.NumField(); i++ {
+		fieldType := t.Field(i)
+		if !fieldType.IsExported() {
 			continue
 		}
 
+		index := append(append([]int{}, parent...), i)
+
 		fieldName := fieldType.Name
+		inline := false
 		if tag := fieldType.Tag.Get("bson"); tag != "" && tag != "-" {
-			fieldName = strings.Split(tag, ",")[0]
+			parts := strings.Split(tag, ",")
+			fieldName = parts[0]
+			for _, opt := range parts[1:] {
+				inline = inline || opt == "inline"
+			}
 		}
 
-		if field.IsZero() {
+		if flatten && inline && fieldType.Type.Kind() == reflect.Struct {
+			fields = append(fields, collectFilterFields(fieldType.Type, index, true)...)
 			continue
 		}
 
-		filter[fieldName] = field.Interface()
+		fields = append(fields, filterField{index: index, name: fieldName, encrypt: fieldType.Tag.Get("encrypt")})
 	}
-
-	return filter
+	return fields
 }
 
 func (uow *UnitOfWork[T]) setEntityTimestamp(entity T, fieldName string, timestamp time.Time) {
@@ -469,6 +1537,6 @@ func (uow *UnitOfWork[T]) setEntityTimestamp(entity T, fieldName string, timesta
 	}
 
 	if field.Type() == reflect.TypeOf(time.Time{}) {
-		field.Set(reflect.ValueOf(timestamp))
+		field.Set(reflect.ValueOf(timestamp.UTC()))
 	}
 }
@@ -3,25 +3,39 @@ package mongodb
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
 )
 
 func (uow *UnitOfWork[T]) BulkInsert(ctx context.Context, entities []T) ([]T, error) {
+	return uow.BulkInsertWithOptions(ctx, entities, persistence.BulkOptions{})
+}
+
+func (uow *UnitOfWork[T]) BulkInsertWithOptions(ctx context.Context, entities []T, opts persistence.BulkOptions) ([]T, error) {
 	if len(entities) == 0 {
 		return entities, nil
 	}
+	if err := checkEntities(entities); err != nil {
+		return entities[:0], err
+	}
+	if err := uow.ensureTimeSeries(ctx); err != nil {
+		return entities[:0], err
+	}
 
 	collection := uow.getCollection()
-	now := time.Now()
+	now := utcNow()
 
 	documents := make([]interface{}, len(entities))
 	for i, entity := range entities {
@@ -33,65 +47,140 @@ func (uow *UnitOfWork[T]) BulkInsert(ctx context.Context, entities []T) ([]T, er
 			entity.SetID(primitive.NewObjectID())
 		}
 
+		normalizeFields(entity)
+		if _, err := uow.stampContentHash(entity); err != nil {
+			return entities[:0], err
+		}
+
 		documents[i] = entity
 		entities[i] = entity
 	}
 
-	_, err := collection.InsertMany(uow.getContext(ctx), documents)
+	done, err := runInBatches(ctx, len(documents), uow.batchOptions(opts), func(start, end int) error {
+		uow.track(opInsert)
+		_, err := collection.InsertMany(uow.getContext(ctx), documents[start:end])
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to bulk insert: %w", err)
+		return entities[:done], fmt.Errorf("failed to bulk insert: %w", duplicateKey(err))
 	}
 
 	return entities, nil
 }
 
-func (uow *UnitOfWork[T]) BulkUpdate(ctx context.Context, entities []T) ([]T, error) {
+// BulkUpdate writes entities by ID in one unordered batch. An entity whose
+// stored values are already identical counts as matched but not modified;
+// entities with no live document are listed in UnmatchedIDs and reported as
+// ErrEntityNotFound.
+func (uow *UnitOfWork[T]) BulkUpdate(ctx context.Context, entities []T) (persistence.BulkResult, error) {
+	result := persistence.BulkResult{Requested: len(entities)}
 	if len(entities) == 0 {
-		return entities, nil
+		return result, nil
+	}
+	if err := checkEntities(entities); err != nil {
+		return result, err
+	}
+	if err := uow.appendOnly("update"); err != nil {
+		return result, err
 	}
 
 	collection := uow.getCollection()
-	now := time.Now()
+	now := utcNow()
 
 	var models []mongo.WriteModel
 	for _, entity := range entities {
 		uow.setEntityTimestamp(entity, "updatedAt", now)
-
-		filter := bson.M{
-			"_id":       entity.GetID(),
-			"deletedAt": bson.M{"$exists": false},
+		normalizeFields(entity)
+		if _, err := uow.stampContentHash(entity); err != nil {
+			return result, err
 		}
+
+		filter := uow.excludeDeleted(bson.M{"_id": entity.GetID()})
 		update := bson.M{"$set": entity}
 
 		model := mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update)
 		models = append(models, model)
 	}
 
-	opts := options.BulkWrite().SetOrdered(false)
-	result, err := collection.BulkWrite(uow.getContext(ctx), models, opts)
+	bulkOpts := options.BulkWrite().SetOrdered(false)
+	done, err := runInBatches(ctx, len(models), uow.batchOptions(persistence.BulkOptions{}), func(start, end int) error {
+		uow.track(opUpdate)
+		res, err := collection.BulkWrite(uow.getContext(ctx), models[start:end], bulkOpts)
+		addBulkWriteResult(&result, res)
+		return err
+	})
+	result.Processed = done
+	if err != nil {
+		return result, fmt.Errorf("failed to bulk update: %w", err)
+	}
+
+	if result.Unmatched() == 0 {
+		return result, nil
+	}
+	result.UnmatchedIDs, err = uow.missingIDs(ctx, entities)
 	if err != nil {
-		return nil, fmt.Errorf("failed to bulk update: %w", err)
+		return result, err
 	}
+	return result, fmt.Errorf("%w: matched %d out of %d entities", uowerrors.ErrEntityNotFound, result.Matched, len(entities))
+}
 
-	if result.ModifiedCount != int64(len(entities)) {
-		return entities, fmt.Errorf("not all entities were updated: modified %d out of %d", result.ModifiedCount, len(entities))
+// missingIDs returns the IDs of entities that have no live document
+func (uow *UnitOfWork[T]) missingIDs(ctx context.Context, entities []T) ([]primitive.ObjectID, error) {
+	ids := make([]primitive.ObjectID, len(entities))
+	for i, entity := range entities {
+		ids[i] = entity.GetID()
 	}
 
-	return entities, nil
+	uow.track(opFind)
+	found, err := uow.getCollection().Distinct(uow.getContext(ctx), "_id", uow.excludeDeleted(bson.M{"_id": bson.M{"$in": ids}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find unmatched entities: %w", err)
+	}
+	live := make(map[primitive.ObjectID]bool, len(found))
+	for _, id := range found {
+		if oid, ok := id.(primitive.ObjectID); ok {
+			live[oid] = true
+		}
+	}
+
+	var missing []primitive.ObjectID
+	for _, id := range ids {
+		if !live[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
+func (uow *UnitOfWork[T]) BulkSoftDelete(ctx context.Context, identifiers []identifier.IIdentifier) (persistence.BulkResult, error) {
+	return uow.BulkSoftDeleteWithOptions(ctx, identifiers, persistence.BulkOptions{})
 }
 
-func (uow *UnitOfWork[T]) BulkSoftDelete(ctx context.Context, identifiers []identifier.IIdentifier) error {
+func (uow *UnitOfWork[T]) BulkSoftDeleteWithOptions(ctx context.Context, identifiers []identifier.IIdentifier, opts persistence.BulkOptions) (persistence.BulkResult, error) {
+	result := persistence.BulkResult{Requested: len(identifiers)}
 	if len(identifiers) == 0 {
-		return nil
+		return result, nil
+	}
+	if err := uow.appendOnly("soft delete"); err != nil {
+		return result, err
+	}
+	if uow.trashMode {
+		return uow.bulkTrash(ctx, identifiers)
 	}
 
 	collection := uow.getCollection()
-	now := time.Now()
+	now := utcNow()
 
+	kind := opUpdate
 	var models []mongo.WriteModel
 	for _, id := range identifiers {
-		filter := id.ToBSON()
-		filter["deletedAt"] = bson.M{"$exists": false}
+		if !uow.softDelete {
+			kind = opDelete
+			models = append(models, mongo.NewDeleteOneModel().SetFilter(id.ToBSON()))
+			continue
+		}
+
+		filter := withNotDeleted(id.ToBSON())
 
 		update := bson.M{
 			"$set": bson.M{
@@ -104,18 +193,25 @@ func (uow *UnitOfWork[T]) BulkSoftDelete(ctx context.Context, identifiers []iden
 		models = append(models, model)
 	}
 
-	opts := options.BulkWrite().SetOrdered(false)
-	_, err := collection.BulkWrite(uow.getContext(ctx), models, opts)
+	bulkOpts := options.BulkWrite().SetOrdered(false)
+	done, err := runInBatches(ctx, len(models), uow.batchOptions(opts), func(start, end int) error {
+		uow.track(kind)
+		res, err := collection.BulkWrite(uow.getContext(ctx), models[start:end], bulkOpts)
+		addBulkWriteResult(&result, res)
+		return err
+	})
+	result.Processed = done
 	if err != nil {
-		return fmt.Errorf("failed to bulk soft delete: %w", err)
+		return result, fmt.Errorf("failed to bulk soft delete: %w", err)
 	}
 
-	return nil
+	return result, nil
 }
 
-func (uow *UnitOfWork[T]) BulkHardDelete(ctx context.Context, identifiers []identifier.IIdentifier) error {
+func (uow *UnitOfWork[T]) BulkHardDelete(ctx context.Context, identifiers []identifier.IIdentifier) (persistence.BulkResult, error) {
+	result := persistence.BulkResult{Requested: len(identifiers)}
 	if len(identifiers) == 0 {
-		return nil
+		return result, nil
 	}
 
 	collection := uow.getCollection()
@@ -127,28 +223,102 @@ func (uow *UnitOfWork[T]) BulkHardDelete(ctx context.Context, identifiers []iden
 		models = append(models, model)
 	}
 
-	opts := options.BulkWrite().SetOrdered(false)
-	_, err := collection.BulkWrite(uow.getContext(ctx), models, opts)
+	bulkOpts := options.BulkWrite().SetOrdered(false)
+	done, err := runInBatches(ctx, len(models), uow.batchOptions(persistence.BulkOptions{}), func(start, end int) error {
+		uow.track(opDelete)
+		res, err := collection.BulkWrite(uow.getContext(ctx), models[start:end], bulkOpts)
+		addBulkWriteResult(&result, res)
+		return err
+	})
+	result.Processed = done
 	if err != nil {
-		return fmt.Errorf("failed to bulk hard delete: %w", err)
+		return result, fmt.Errorf("failed to bulk hard delete: %w", err)
 	}
 
-	return nil
+	return result, nil
+}
+
+// addBulkWriteResult accumulates driver counts into result. The driver may
+// return a partial result alongside an error, so nil is tolerated.
+func addBulkWriteResult(result *persistence.BulkResult, res *mongo.BulkWriteResult) {
+	if res == nil {
+		return
+	}
+	result.Matched += res.MatchedCount
+	result.Modified += res.ModifiedCount
+	result.Deleted += res.DeletedCount
+}
+
+// batchOptions returns opts with the configured batch size filled in when
+// opts leaves it unset
+func (uow *UnitOfWork[T]) batchOptions(opts persistence.BulkOptions) persistence.BulkOptions {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = uow.bulkBatchSize
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBulkBatchSize
+	}
+	return opts
+}
+
+// runInBatches calls write for consecutive [start, end) windows of total items.
+// The context is checked before each batch, so a cancellation stops the run
+// between batches. It returns the number of items written by completed batches.
+func runInBatches(ctx context.Context, total int, opts persistence.BulkOptions, write func(start, end int) error) (int, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 || batchSize > total {
+		batchSize = total
+	}
+
+	done := 0
+	for done < total {
+		if err := ctx.Err(); err != nil {
+			return done, fmt.Errorf("cancelled after %d of %d: %w", done, total, err)
+		}
+
+		end := min(done+batchSize, total)
+		if err := write(done, end); err != nil {
+			return done, err
+		}
+		done = end
+
+		if opts.Progress != nil {
+			opts.Progress(done, total)
+		}
+	}
+
+	return done, nil
 }
 
 func (uow *UnitOfWork[T]) GetTrashed(ctx context.Context) ([]T, error) {
-	collection := uow.getCollection()
+	return uow.findTrashed(ctx, deletedCondition())
+}
 
-	filter := bson.M{"deletedAt": bson.M{"$exists": true}}
+// GetTrashedInRange returns entities soft-deleted in the half-open range
+// [start, end).
+func (uow *UnitOfWork[T]) GetTrashedInRange(ctx context.Context, start, end time.Time) ([]T, error) {
+	return uow.findTrashed(ctx, deletedAtRange(start, end))
+}
+
+func deletedAtRange(start, end time.Time) bson.M {
+	return bson.M{"$gte": start.UTC(), "$lt": end.UTC()}
+}
 
-	cursor, err := collection.Find(uow.getContext(ctx), filter)
+func (uow *UnitOfWork[T]) findTrashed(ctx context.Context, deletedAt bson.M) ([]T, error) {
+	collection := uow.trashedCollection()
+
+	filter := bson.M{"deletedAt": deletedAt}
+
+	uow.track(opFind)
+	queryCtx := uow.getContext(ctx)
+	cursor, err := collection.Find(queryCtx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get trashed: %w", err)
 	}
-	defer cursor.Close(ctx)
+	defer cursor.Close(queryCtx)
 
 	var results []T
-	if err := cursor.All(ctx, &results); err != nil {
+	if err := uow.decodeAll(queryCtx, cursor, &results); err != nil {
 		return nil, fmt.Errorf("failed to decode trashed results: %w", err)
 	}
 
@@ -156,9 +326,15 @@ func (uow *UnitOfWork[T]) GetTrashed(ctx context.Context) ([]T, error) {
 }
 
 func (uow *UnitOfWork[T]) GetTrashedWithPagination(ctx context.Context, query domain.QueryParams[T]) ([]T, uint, error) {
-	collection := uow.getCollection()
+	return uow.findTrashedPage(ctx, deletedCondition(), query)
+}
+
+func (uow *UnitOfWork[T]) GetTrashedInRangeWithPagination(ctx context.Context, start, end time.Time, query domain.QueryParams[T]) ([]T, uint, error) {
+	return uow.findTrashedPage(ctx, deletedAtRange(start, end), query)
+}
 
-	filter := bson.M{"deletedAt": bson.M{"$exists": true}}
+func (uow *UnitOfWork[T]) findTrashedPage(ctx context.Context, deletedAt bson.M, query domain.QueryParams[T]) ([]T, uint, error) {
+	filter := bson.M{"deletedAt": deletedAt}
 	if !isZeroValue(query.Filter) {
 		filterBSON := uow.buildFilterFromModel(query.Filter)
 		for k, v := range filterBSON {
@@ -168,57 +344,50 @@ func (uow *UnitOfWork[T]) GetTrashedWithPagination(ctx context.Context, query do
 		}
 	}
 
-	total, err := collection.CountDocuments(uow.getContext(ctx), filter)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count trashed documents: %w", err)
-	}
-
-	opts := options.Find()
-	if query.Limit > 0 {
-		opts.SetLimit(int64(query.Limit))
-	}
-	if query.Offset > 0 {
-		opts.SetSkip(int64(query.Offset))
-	}
+	return uow.findPage(ctx, uow.trashedCollection(), filter, query)
+}
 
-	if query.Sort != nil && len(query.Sort) > 0 {
-		sort := bson.D{}
-		for field, direction := range query.Sort {
-			if direction == domain.SortAsc {
-				sort = append(sort, bson.E{Key: field, Value: 1})
-			} else {
-				sort = append(sort, bson.E{Key: field, Value: -1})
-			}
+// PurgeTrashedOlderThan permanently deletes entities soft-deleted before
+// cutoff and returns how many were removed. With dryRun it only counts them,
+// so scheduled cleanup can preview its effect.
+func (uow *UnitOfWork[T]) PurgeTrashedOlderThan(ctx context.Context, cutoff time.Time, dryRun bool) (int64, error) {
+	collection := uow.trashedCollection()
+	filter := bson.M{"deletedAt": bson.M{"$lt": cutoff.UTC()}}
+
+	if dryRun {
+		uow.track(opCount)
+		count, err := collection.CountDocuments(uow.getContext(ctx), filter)
+		if err != nil {
+			return 0, fmt.Errorf("failed to count trashed: %w", err)
 		}
-		opts.SetSort(sort)
+		return count, nil
 	}
 
-	cursor, err := collection.Find(uow.getContext(ctx), filter, opts)
+	uow.track(opDelete)
+	result, err := collection.DeleteMany(uow.getContext(ctx), filter)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to find trashed with pagination: %w", err)
-	}
-	defer cursor.Close(ctx)
-
-	var results []T
-	if err := cursor.All(ctx, &results); err != nil {
-		return nil, 0, fmt.Errorf("failed to decode trashed results: %w", err)
+		return 0, fmt.Errorf("failed to purge trashed: %w", err)
 	}
-
-	return results, uint(total), nil
+	return result.DeletedCount, nil
 }
 
 func (uow *UnitOfWork[T]) Restore(ctx context.Context, identifier identifier.IIdentifier) (T, error) {
+	if uow.trashMode {
+		return uow.restoreFromTrash(ctx, identifier)
+	}
+
 	var zero T
 	collection := uow.getCollection()
 
 	filter := identifier.ToBSON()
-	filter["deletedAt"] = bson.M{"$exists": true}
+	filter["deletedAt"] = deletedCondition()
 
 	update := bson.M{
 		"$unset": bson.M{"deletedAt": ""},
-		"$set":   bson.M{"updatedAt": time.Now()},
+		"$set":   bson.M{"updatedAt": utcNow()},
 	}
 
+	uow.track(opUpdate)
 	result := collection.FindOneAndUpdate(
 		uow.getContext(ctx),
 		filter,
@@ -227,7 +396,7 @@ func (uow *UnitOfWork[T]) Restore(ctx context.Context, identifier identifier.IId
 	)
 
 	var restored T
-	if err := result.Decode(&restored); err != nil {
+	if err := uow.decode(result, &restored); err != nil {
 		if err == mongo.ErrNoDocuments {
 			return zero, fmt.Errorf("entity not found in trash")
 		}
@@ -237,23 +406,220 @@ func (uow *UnitOfWork[T]) Restore(ctx context.Context, identifier identifier.IId
 	return restored, nil
 }
 
-func (uow *UnitOfWork[T]) RestoreAll(ctx context.Context) error {
+// SoftDeleteMany soft deletes every live entity matched by identifier in a
+// single command and returns how many were deleted. An empty identifier is
+// rejected so a missing predicate cannot trash the whole collection.
+func (uow *UnitOfWork[T]) SoftDeleteMany(ctx context.Context, identifier identifier.IIdentifier) (int64, error) {
+	filter, err := predicateFilter(identifier)
+	if err != nil {
+		return 0, err
+	}
+	if err := uow.appendOnly("soft delete"); err != nil {
+		return 0, err
+	}
+
+	if uow.trashMode {
+		count, err := uow.moveManyToTrash(ctx, filter)
+		if err != nil {
+			return 0, fmt.Errorf("failed to soft delete many: %w", err)
+		}
+		return count, nil
+	}
+
+	collection := uow.getCollection()
+	if !uow.softDelete {
+		uow.track(opDelete)
+		result, err := collection.DeleteMany(uow.getContext(ctx), filter)
+		if err != nil {
+			return 0, fmt.Errorf("failed to soft delete many: %w", err)
+		}
+		return result.DeletedCount, nil
+	}
+
+	withNotDeleted(filter)
+	now := utcNow()
+	update := bson.M{
+		"$set": bson.M{
+			"deletedAt": now,
+			"updatedAt": now,
+		},
+	}
+
+	uow.track(opUpdate)
+	result, err := collection.UpdateMany(uow.getContext(ctx), filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to soft delete many: %w", err)
+	}
+	return result.ModifiedCount, nil
+}
+
+// RestoreMany restores every trashed entity matched by identifier and returns
+// how many were restored. Like SoftDeleteMany it rejects an empty identifier;
+// use RestoreAll to restore the whole collection.
+func (uow *UnitOfWork[T]) RestoreMany(ctx context.Context, identifier identifier.IIdentifier) (int64, error) {
+	filter, err := predicateFilter(identifier)
+	if err != nil {
+		return 0, err
+	}
+
+	if uow.trashMode {
+		count, err := uow.restoreManyFromTrash(ctx, filter)
+		if err != nil {
+			return 0, fmt.Errorf("failed to restore many: %w", err)
+		}
+		return count, nil
+	}
+	if !uow.softDelete {
+		return 0, nil
+	}
+
+	filter["deletedAt"] = deletedCondition()
+	update := bson.M{
+		"$unset": bson.M{"deletedAt": ""},
+		"$set":   bson.M{"updatedAt": utcNow()},
+	}
+
+	uow.track(opUpdate)
+	result, err := uow.getCollection().UpdateMany(uow.getContext(ctx), filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to restore many: %w", err)
+	}
+	return result.ModifiedCount, nil
+}
+
+// BulkRestore restores the trashed entity matched by each identifier in one
+// unordered batch. Identifiers matching no trashed entity are skipped; an
+// empty identifier is rejected as it would restore an arbitrary one.
+func (uow *UnitOfWork[T]) BulkRestore(ctx context.Context, identifiers []identifier.IIdentifier) error {
+	if len(identifiers) == 0 {
+		return nil
+	}
+	filters := make([]bson.M, len(identifiers))
+	for i, id := range identifiers {
+		filter, err := predicateFilter(id)
+		if err != nil {
+			return fmt.Errorf("identifier at index %d: %w", i, err)
+		}
+		filters[i] = filter
+	}
+
+	if uow.trashMode {
+		matchAny := make(bson.A, len(filters))
+		for i, filter := range filters {
+			matchAny[i] = filter
+		}
+		if _, err := uow.restoreManyFromTrash(ctx, bson.M{"$or": matchAny}); err != nil {
+			return fmt.Errorf("failed to bulk restore: %w", err)
+		}
+		return nil
+	}
+	if !uow.softDelete {
+		return nil
+	}
+
+	update := bson.M{
+		"$unset": bson.M{"deletedAt": ""},
+		"$set":   bson.M{"updatedAt": utcNow()},
+	}
+	models := make([]mongo.WriteModel, len(filters))
+	for i, filter := range filters {
+		filter["deletedAt"] = deletedCondition()
+		models[i] = mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update)
+	}
+
+	uow.track(opUpdate)
+	if _, err := uow.getCollection().BulkWrite(uow.getContext(ctx), models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("failed to bulk restore: %w", err)
+	}
+	return nil
+}
+
+// predicateFilter returns the filter of a multi-document predicate, refusing
+// one that would match every document
+func predicateFilter(identifier identifier.IIdentifier) (bson.M, error) {
+	if identifier == nil {
+		return nil, fmt.Errorf("%w: predicate is required", uowerrors.ErrInvalidQuery)
+	}
+	filter := identifier.ToBSON()
+	if len(filter) == 0 {
+		return nil, fmt.Errorf("%w: empty predicate would match every document", uowerrors.ErrInvalidQuery)
+	}
+	return filter, nil
+}
+
+// RestoreAll restores every trashed document in the collection. It refuses to
+// run unless confirm is true.
+func (uow *UnitOfWork[T]) RestoreAll(ctx context.Context, confirm bool) error {
+	if !confirm {
+		return fmt.Errorf("%w: RestoreAll on %s", uowerrors.ErrConfirmationRequired, uow.collectionName)
+	}
+
+	if uow.trashMode {
+		restored, err := uow.restoreAllFromTrash(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to restore all: %w", err)
+		}
+		uow.logCollectionWide(ctx, "RestoreAll", restored)
+		return nil
+	}
+
 	collection := uow.getCollection()
 
-	filter := bson.M{"deletedAt": bson.M{"$exists": true}}
+	filter := bson.M{"deletedAt": deletedCondition()}
 	update := bson.M{
 		"$unset": bson.M{"deletedAt": ""},
-		"$set":   bson.M{"updatedAt": time.Now()},
+		"$set":   bson.M{"updatedAt": utcNow()},
 	}
 
-	_, err := collection.UpdateMany(uow.getContext(ctx), filter, update)
+	uow.track(opUpdate)
+	result, err := collection.UpdateMany(uow.getContext(ctx), filter, update)
 	if err != nil {
 		return fmt.Errorf("failed to restore all: %w", err)
 	}
 
+	uow.logCollectionWide(ctx, "RestoreAll", result.ModifiedCount)
 	return nil
 }
 
+// DeleteAll permanently removes every document in the collection, trashed
+// ones included. It refuses to run unless confirm is true.
+func (uow *UnitOfWork[T]) DeleteAll(ctx context.Context, confirm bool) error {
+	if !confirm {
+		return fmt.Errorf("%w: DeleteAll on %s", uowerrors.ErrConfirmationRequired, uow.collectionName)
+	}
+
+	uow.track(opDelete)
+	result, err := uow.getCollection().DeleteMany(uow.getContext(ctx), bson.M{})
+	if err != nil {
+		return fmt.Errorf("failed to delete all: %w", err)
+	}
+	deleted := result.DeletedCount
+
+	if uow.trashMode {
+		uow.track(opDelete)
+		trashed, err := uow.getTrashCollection().DeleteMany(uow.getContext(ctx), bson.M{})
+		if err != nil {
+			return fmt.Errorf("failed to delete all trashed: %w", err)
+		}
+		deleted += trashed.DeletedCount
+	}
+
+	uow.logCollectionWide(ctx, "DeleteAll", deleted)
+	return nil
+}
+
+func (uow *UnitOfWork[T]) logCollectionWide(ctx context.Context, op string, affected int64) {
+	if uow.logger == nil {
+		return
+	}
+	uow.logger.WarnContext(ctx, "collection-wide operation",
+		slog.String("op", op),
+		slog.String("database", uow.database.Name()),
+		slog.String("collection", uow.collectionName),
+		slog.Int64("affected", affected),
+	)
+}
+
 func (uow *UnitOfWork[T]) GetRepository(entityType string) interface{} {
 	uow.mu.RLock()
 	defer uow.mu.RUnlock()
@@ -267,16 +633,90 @@ func (uow *UnitOfWork[T]) RegisterRepository(entityType string, repo interface{}
 }
 
 func (uow *UnitOfWork[T]) WithContext(ctx context.Context) persistence.IUnitOfWork[T] {
-	newUow := &UnitOfWork[T]{
+	newUow := uow.view()
+	newUow.ctx = ctx
+	return newUow
+}
+
+// ForDatabase returns a view of the unit of work scoped to another database on
+// the same client, e.g. for database-per-tenant setups. The view shares the
+// session and stats but has its own repository registry. Closing the view
+// never disconnects the client; only a unit of work from NewUnitOfWork, which
+// dialed the client itself, does so when closed.
+func (uow *UnitOfWork[T]) ForDatabase(name string) persistence.IUnitOfWork[T] {
+	newUow := uow.view()
+	newUow.database = uow.client.Database(name)
+	newUow.repositories = make(map[string]interface{})
+	return newUow
+}
+
+// WithReadConcern returns a view whose reads use rc. With
+// readconcern.Snapshot() each find or aggregation reads from its own point in
+// time (MongoDB 5.0+); use SnapshotContext to make several reads share one.
+// Snapshot reads are not allowed inside a transaction.
+func (uow *UnitOfWork[T]) WithReadConcern(rc *readconcern.ReadConcern) persistence.IUnitOfWork[T] {
+	newUow := uow.view()
+	newUow.database = uow.client.Database(uow.database.Name(), options.Database().SetReadConcern(rc))
+	return newUow
+}
+
+// WithReadPreference returns a view whose reads are routed by rp, e.g.
+// readpref.SecondaryPreferred() to keep reporting queries off the primary.
+// Reads inside a transaction always go to the primary.
+func (uow *UnitOfWork[T]) WithReadPreference(rp *readpref.ReadPref) persistence.IUnitOfWork[T] {
+	newUow := uow.view()
+	newUow.database = uow.client.Database(uow.database.Name(), options.Database().
+		SetReadConcern(uow.database.ReadConcern()).
+		SetReadPreference(rp))
+	return newUow
+}
+
+// SnapshotContext starts a snapshot session and returns a context bound to it.
+// Reads issued with that context, across collections, all observe the data as
+// of the first read. Call end when done; the context must not be used for
+// writes or transactions.
+func (uow *UnitOfWork[T]) SnapshotContext(ctx context.Context) (snapshotCtx context.Context, end func(), err error) {
+	session, err := uow.client.StartSession(options.Session().SetSnapshot(true))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to start snapshot session: %w", err)
+	}
+	return mongo.NewSessionContext(ctx, session), func() { session.EndSession(context.Background()) }, nil
+}
+
+// view copies the unit of work so WithContext and friends can adjust one
+// aspect while sharing the client, session and settings
+func (uow *UnitOfWork[T]) view() *UnitOfWork[T] {
+	return &UnitOfWork[T]{
 		client:         uow.client,
 		database:       uow.database,
 		session:        uow.session,
-		ctx:            ctx,
+		ctx:            uow.ctx,
 		repositories:   uow.repositories,
 		inTx:           uow.inTx,
+		inFallbackTx:   uow.inFallbackTx,
 		collectionName: uow.collectionName,
+		softDelete:     uow.softDelete,
+		trashMode:      uow.trashMode,
+		slugStrategy:   uow.slugStrategy,
+		slugAttempts:   uow.slugAttempts,
+		slugIndex:      uow.slugIndex,
+		references:     uow.references,
+		strictDecoding: uow.strictDecoding,
+		strictSort:     uow.strictSort,
+		txFallback:     uow.txFallback,
+		logger:         uow.logger,
+		auditSink:      uow.auditSink,
+		txOptions:      uow.txOptions,
+		encryption:     uow.encryption,
+		registry:       uow.registry,
+		stats:          uow.stats,
+		timeSeries:     uow.timeSeries,
+		bulkBatchSize:  uow.bulkBatchSize,
 	}
-	return newUow
+}
+
+func (uow *UnitOfWork[T]) CollectionName() string {
+	return uow.collectionName
 }
 
 func (uow *UnitOfWork[T]) GetContext() context.Context {
@@ -287,9 +727,28 @@ func (uow *UnitOfWork[T]) IsInTransaction() bool {
 	return uow.inTx
 }
 
+// Session returns the driver session of the active transaction and whether
+// one exists. It is meant for advanced control such as causal consistency or
+// manual retries; committing, aborting or ending the session directly leaves
+// the unit of work believing the transaction is still open.
+func (uow *UnitOfWork[T]) Session() (mongo.Session, bool) {
+	uow.mu.RLock()
+	defer uow.mu.RUnlock()
+
+	if !uow.inTx || uow.session == nil {
+		return nil, false
+	}
+	return uow.session, true
+}
+
+// Close rolls back an open transaction and disconnects the client when the
+// unit of work dialed it itself; shared clients stay connected
 func (uow *UnitOfWork[T]) Close(ctx context.Context) error {
 	if uow.inTx {
 		uow.RollbackTransaction(ctx)
 	}
+	if !uow.ownsClient {
+		return nil
+	}
 	return uow.client.Disconnect(ctx)
 }
@@ -0,0 +1,326 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+)
+
+type progressCall struct {
+	done, total int
+}
+
+func TestRunInBatches(t *testing.T) {
+	var windows [][2]int
+	var progress []progressCall
+
+	done, err := runInBatches(context.Background(), 5, persistence.BulkOptions{
+		BatchSize: 2,
+		Progress:  func(done, total int) { progress = append(progress, progressCall{done, total}) },
+	}, func(start, end int) error {
+		windows = append(windows, [2]int{start, end})
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 5, done)
+	assert.Equal(t, [][2]int{{0, 2}, {2, 4}, {4, 5}}, windows)
+	assert.Equal(t, []progressCall{{2, 5}, {4, 5}, {5, 5}}, progress)
+}
+
+func TestRunInBatches_SingleBatchByDefault(t *testing.T) {
+	var windows [][2]int
+
+	done, err := runInBatches(context.Background(), 3, persistence.BulkOptions{}, func(start, end int) error {
+		windows = append(windows, [2]int{start, end})
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, done)
+	assert.Equal(t, [][2]int{{0, 3}}, windows)
+}
+
+func TestRunInBatches_CancelAfterFirstBatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var progress []progressCall
+	batches := 0
+
+	done, err := runInBatches(ctx, 5, persistence.BulkOptions{
+		BatchSize: 2,
+		Progress: func(done, total int) {
+			progress = append(progress, progressCall{done, total})
+			cancel()
+		},
+	}, func(start, end int) error {
+		batches++
+		return nil
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 2, done)
+	assert.Equal(t, 1, batches)
+	assert.Equal(t, []progressCall{{2, 5}}, progress)
+}
+
+func TestRunInBatches_WriteError(t *testing.T) {
+	writeErr := errors.New("write failed")
+
+	done, err := runInBatches(context.Background(), 4, persistence.BulkOptions{BatchSize: 2}, func(start, end int) error {
+		if start > 0 {
+			return writeErr
+		}
+		return nil
+	})
+
+	assert.ErrorIs(t, err, writeErr)
+	assert.Equal(t, 2, done)
+}
+
+func TestUnitOfWork_BulkInsertWithOptions_CancelledContext(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, &Config{EnableStats: true})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	inserted, err := uow.BulkInsertWithOptions(ctx, []*TestUser{{}, {}}, persistence.BulkOptions{BatchSize: 1})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, inserted)
+	assert.Zero(t, uow.Stats().Total())
+}
+
+func TestUnitOfWork_BatchOptions(t *testing.T) {
+	assert.Equal(t, 1000, NewConfig().BulkBatchSize)
+
+	config := NewConfig()
+	config.BulkBatchSize = 250
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	require.NoError(t, err)
+	defer client.Disconnect(context.Background())
+
+	users := newUnitOfWork[*TestUser](config, client)
+	assert.Equal(t, 250, users.batchOptions(persistence.BulkOptions{}).BatchSize)
+	assert.Equal(t, 10, users.batchOptions(persistence.BulkOptions{BatchSize: 10}).BatchSize, "explicit options win")
+	assert.Equal(t, 250, users.view().batchOptions(persistence.BulkOptions{}).BatchSize)
+
+	config.BulkBatchSize = 0
+	users = newUnitOfWork[*TestUser](config, client)
+	assert.Equal(t, defaultBulkBatchSize, users.batchOptions(persistence.BulkOptions{}).BatchSize)
+}
+
+func TestUnitOfWork_BulkInsert_Batches_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	uow.stats = &operationCounters{}
+	ctx := context.Background()
+
+	users := make([]*TestUser, 2500)
+	for i := range users {
+		users[i] = &TestUser{Email: fmt.Sprintf("user%d@example.com", i)}
+	}
+
+	inserted, err := uow.BulkInsert(ctx, users)
+	require.NoError(t, err)
+	assert.Len(t, inserted, 2500)
+	assert.Equal(t, int64(3), uow.Stats().Inserts, "2500 documents go in batches of 1000")
+
+	count, err := uow.Count(ctx, identifier.New().Exists("email", true))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2500), count)
+
+	for _, user := range users {
+		user.Age = 30
+	}
+	result, err := uow.BulkUpdate(ctx, users)
+	require.NoError(t, err)
+	assert.Equal(t, 2500, result.Processed)
+	assert.Equal(t, int64(2500), result.Matched)
+	assert.Equal(t, int64(3), uow.Stats().Updates)
+}
+
+func TestUnitOfWork_BulkInsertWithOptions_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	users := []*TestUser{{Email: "a"}, {Email: "b"}, {Email: "c"}, {Email: "d"}, {Email: "e"}}
+	var progress []progressCall
+
+	inserted, err := uow.BulkInsertWithOptions(ctx, users, persistence.BulkOptions{
+		BatchSize: 2,
+		Progress: func(done, total int) {
+			progress = append(progress, progressCall{done, total})
+			cancel()
+		},
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, inserted, 2)
+	assert.Equal(t, []progressCall{{2, 5}}, progress)
+
+	count, err := uow.getCollection().CountDocuments(context.Background(), bson.M{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestUnitOfWork_BulkSoftDeleteWithOptions_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	users, err := uow.BulkInsert(ctx, []*TestUser{{Email: "a"}, {Email: "b"}, {Email: "c"}})
+	require.NoError(t, err)
+
+	ids := make([]identifier.IIdentifier, len(users))
+	for i, user := range users {
+		ids[i] = identifier.New().Equal("_id", user.GetID())
+	}
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var progress []progressCall
+
+	result, err := uow.BulkSoftDeleteWithOptions(cancelCtx, ids, persistence.BulkOptions{
+		BatchSize: 1,
+		Progress: func(done, total int) {
+			progress = append(progress, progressCall{done, total})
+			cancel()
+		},
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, result.Processed)
+	assert.Equal(t, int64(1), result.Modified)
+	assert.Equal(t, []progressCall{{1, 3}}, progress)
+
+	trashed, err := uow.GetTrashed(ctx)
+	require.NoError(t, err)
+	assert.Len(t, trashed, 1)
+}
+
+func TestBulkResult_Unmatched(t *testing.T) {
+	assert.Equal(t, int64(2), persistence.BulkResult{Processed: 5, Matched: 3, Modified: 3}.Unmatched())
+	assert.Equal(t, int64(1), persistence.BulkResult{Processed: 4, Deleted: 3}.Unmatched())
+}
+
+func TestBulkResult_Affected(t *testing.T) {
+	assert.Equal(t, int64(3), persistence.BulkResult{Processed: 5, Matched: 4, Modified: 3}.Affected())
+	assert.Equal(t, int64(2), persistence.BulkResult{Processed: 4, Deleted: 2}.Affected())
+}
+
+func TestUnitOfWork_BulkUpdate_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	users, err := uow.BulkInsert(ctx, []*TestUser{{Email: "a", Age: 1}, {Email: "b", Age: 2}, {Email: "c", Age: 3}})
+	require.NoError(t, err)
+	_, err = uow.SoftDelete(ctx, identifier.New().Equal("_id", users[2].GetID()))
+	require.NoError(t, err)
+
+	changed, unchanged, trashed := users[0], users[1], users[2]
+	changed.Age = 10
+	missing := &TestUser{Email: "missing"}
+	missing.SetID(primitive.NewObjectID())
+
+	result, err := uow.BulkUpdate(ctx, []*TestUser{changed, unchanged, trashed, missing})
+	assert.ErrorIs(t, err, uowerrors.ErrEntityNotFound)
+	assert.Equal(t, 4, result.Requested)
+	assert.Equal(t, 4, result.Processed)
+	assert.Equal(t, int64(2), result.Matched, "an unchanged entity still matches")
+	assert.Equal(t, int64(2), result.Unmatched())
+	assert.Equal(t, []primitive.ObjectID{trashed.GetID(), missing.GetID()}, result.UnmatchedIDs)
+
+	stored, err := uow.FindOneById(ctx, changed.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, 10, stored.Age, "matched entities are written despite the error")
+
+	result, err = uow.BulkUpdate(ctx, []*TestUser{changed, unchanged})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result.Matched)
+	assert.Empty(t, result.UnmatchedIDs)
+}
+
+func TestUnitOfWork_BulkRestore_RejectsEmptyIdentifier(t *testing.T) {
+	users := newOfflineUnitOfWork[*TestUser](t, nil)
+
+	err := users.BulkRestore(context.Background(), []identifier.IIdentifier{identifier.New().Equal("email", "a"), identifier.New()})
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQuery)
+	assert.ErrorContains(t, err, "index 1")
+	assert.Zero(t, users.Stats().Total())
+
+	assert.NoError(t, users.BulkRestore(context.Background(), nil))
+}
+
+func TestUnitOfWork_BulkRestore_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	users, err := uow.BulkInsert(ctx, []*TestUser{{Email: "a"}, {Email: "b"}, {Email: "c"}})
+	require.NoError(t, err)
+	ids := make([]identifier.IIdentifier, len(users))
+	for i, user := range users {
+		ids[i] = identifier.New().Equal("_id", user.GetID())
+	}
+	_, err = uow.BulkSoftDelete(ctx, ids)
+	require.NoError(t, err)
+
+	require.NoError(t, uow.BulkRestore(ctx, ids[:2]))
+
+	live, err := uow.FindAll(ctx)
+	require.NoError(t, err)
+	emails := make([]string, len(live))
+	for i, user := range live {
+		emails[i] = user.Email
+	}
+	assert.ElementsMatch(t, []string{"a", "b"}, emails)
+
+	trashed, err := uow.GetTrashed(ctx)
+	require.NoError(t, err)
+	require.Len(t, trashed, 1)
+	assert.Equal(t, "c", trashed[0].Email)
+}
+
+func TestUnitOfWork_BulkDeleteResults_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	users, err := uow.BulkInsert(ctx, []*TestUser{{Email: "a"}, {Email: "b"}, {Email: "c"}})
+	require.NoError(t, err)
+
+	_, err = uow.SoftDelete(ctx, identifier.New().Equal("_id", users[0].GetID()))
+	require.NoError(t, err)
+
+	ids := []identifier.IIdentifier{
+		identifier.New().Equal("_id", users[0].GetID()),
+		identifier.New().Equal("_id", users[1].GetID()),
+		identifier.New().Equal("_id", users[2].GetID()),
+		identifier.New().Equal("email", "missing"),
+	}
+
+	softResult, err := uow.BulkSoftDelete(ctx, ids)
+	require.NoError(t, err)
+	assert.Equal(t, 4, softResult.Requested)
+	assert.Equal(t, 4, softResult.Processed)
+	assert.Equal(t, int64(2), softResult.Matched)
+	assert.Equal(t, int64(2), softResult.Modified)
+	assert.Equal(t, int64(2), softResult.Unmatched())
+	assert.Equal(t, int64(2), softResult.Affected(), "already-trashed and missing identifiers are not counted")
+
+	hardResult, err := uow.BulkHardDelete(ctx, ids)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), hardResult.Deleted)
+	assert.Equal(t, int64(3), hardResult.Affected())
+	assert.Equal(t, int64(1), hardResult.Unmatched())
+}
@@ -0,0 +1,103 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+)
+
+func TestUnitOfWork_ForDatabase(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, &Config{EnableStats: true})
+	uow.RegisterRepository("users", struct{}{})
+
+	view, ok := uow.ForDatabase("tenant_b").(*UnitOfWork[*TestUser])
+	require.True(t, ok)
+
+	assert.Same(t, uow.client, view.client)
+	assert.Same(t, uow.stats, view.stats)
+	assert.Equal(t, "tenant_b", view.database.Name())
+	assert.Equal(t, "offline", uow.database.Name())
+	assert.Equal(t, uow.collectionName, view.collectionName)
+	assert.Nil(t, view.GetRepository("users"))
+}
+
+func TestUnitOfWork_ForDatabase_Integration(t *testing.T) {
+	tenantA := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	tenantB := tenantA.ForDatabase("uow_test_" + primitive.NewObjectID().Hex())
+	t.Cleanup(func() {
+		_ = tenantB.(*UnitOfWork[*TestUser]).database.Drop(context.Background())
+	})
+
+	alice, err := tenantA.Insert(ctx, &TestUser{Email: "alice@a.example.com"})
+	require.NoError(t, err)
+	bob, err := tenantB.Insert(ctx, &TestUser{Email: "bob@b.example.com"})
+	require.NoError(t, err)
+
+	usersA, err := tenantA.FindAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, usersA, 1)
+	assert.Equal(t, "alice@a.example.com", usersA[0].Email)
+
+	usersB, err := tenantB.FindAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, usersB, 1)
+	assert.Equal(t, "bob@b.example.com", usersB[0].Email)
+
+	_, found, err := tenantA.TryFindOne(ctx, identifier.New().Equal("_id", bob.GetID()))
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	_, found, err = tenantB.TryFindOneById(ctx, alice.GetID())
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestUnitOfWork_WithReadConcern(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+
+	view, ok := uow.WithReadConcern(readconcern.Snapshot()).(*UnitOfWork[*TestUser])
+	require.True(t, ok)
+
+	assert.Equal(t, "snapshot", view.database.ReadConcern().Level)
+	assert.Equal(t, uow.database.Name(), view.database.Name())
+	assert.Empty(t, uow.database.ReadConcern().Level)
+}
+
+func TestUnitOfWork_SnapshotContext_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	for _, email := range []string{"a@example.com", "b@example.com"} {
+		_, err := uow.Insert(ctx, &TestUser{Email: email})
+		require.NoError(t, err)
+	}
+
+	snapshotCtx, end, err := uow.SnapshotContext(ctx)
+	require.NoError(t, err)
+	defer end()
+
+	users, err := uow.FindAll(snapshotCtx)
+	if err != nil {
+		t.Skipf("Snapshot reads require a replica set on MongoDB 5.0+: %v", err)
+	}
+	assert.Len(t, users, 2)
+
+	_, err = uow.Insert(ctx, &TestUser{Email: "c@example.com"})
+	require.NoError(t, err)
+
+	users, err = uow.FindAll(snapshotCtx)
+	require.NoError(t, err)
+	assert.Len(t, users, 2, "snapshot must not observe the concurrent insert")
+
+	users, err = uow.FindAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, users, 3)
+}
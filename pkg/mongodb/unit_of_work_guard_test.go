@@ -0,0 +1,108 @@
+package mongodb
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+)
+
+func TestUnitOfWork_CollectionWideOperationsRequireConfirmation(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, &Config{EnableStats: true})
+	ctx := context.Background()
+
+	err := uow.RestoreAll(ctx, false)
+	assert.ErrorIs(t, err, uowerrors.ErrConfirmationRequired)
+
+	err = uow.DeleteAll(ctx, false)
+	assert.ErrorIs(t, err, uowerrors.ErrConfirmationRequired)
+
+	assert.Zero(t, uow.Stats().Total())
+}
+
+func TestFactory_WithLogger(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+
+	factory, err := NewFactory[*TestUser](NewConfig(), WithLogger(logger))
+	require.NoError(t, err)
+
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+	factory.apply(uow)
+
+	require.NotNil(t, uow.logger)
+	assert.Equal(t, requestIDHandler{logger.Handler()}, uow.logger.Handler())
+}
+
+func TestUnitOfWork_CollectionWideOperations_Integration(t *testing.T) {
+	var logs bytes.Buffer
+	uow := newIntegrationUnitOfWork[*TestUser](t, WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	ctx := context.Background()
+
+	for _, email := range []string{"a@example.com", "b@example.com"} {
+		user, err := uow.Insert(ctx, &TestUser{Email: email})
+		require.NoError(t, err)
+		_, err = uow.SoftDelete(ctx, identifier.New().Equal("_id", user.GetID()))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, uow.RestoreAll(ctx, true))
+
+	users, err := uow.FindAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.Contains(t, logs.String(), "op=RestoreAll")
+	assert.Contains(t, logs.String(), "affected=2")
+
+	require.NoError(t, uow.DeleteAll(ctx, true))
+
+	count, err := uow.getCollection().CountDocuments(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.Contains(t, logs.String(), "op=DeleteAll")
+}
+
+func TestUnitOfWork_NilEntitiesRejected(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, &Config{EnableStats: true})
+	ctx := context.Background()
+	byEmail := identifier.New().Equal("email", "nil@example.com")
+
+	assert.NotPanics(t, func() {
+		_, err := uow.Insert(ctx, nil)
+		assert.ErrorIs(t, err, uowerrors.ErrNilEntity)
+
+		_, err = uow.Update(ctx, byEmail, nil)
+		assert.ErrorIs(t, err, uowerrors.ErrNilEntity)
+
+		_, err = uow.UpdateReturningBefore(ctx, byEmail, nil)
+		assert.ErrorIs(t, err, uowerrors.ErrNilEntity)
+
+		_, err = uow.Upsert(ctx, byEmail, nil)
+		assert.ErrorIs(t, err, uowerrors.ErrNilEntity)
+	})
+	assert.Zero(t, uow.Stats().Total())
+}
+
+func TestUnitOfWork_NilBulkElementsRejected(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, &Config{EnableStats: true})
+	ctx := context.Background()
+	valid := &TestUser{Email: "valid@example.com"}
+
+	assert.NotPanics(t, func() {
+		inserted, err := uow.BulkInsert(ctx, []*TestUser{valid, nil})
+		assert.ErrorIs(t, err, uowerrors.ErrNilEntity)
+		assert.ErrorContains(t, err, "index 1")
+		assert.Empty(t, inserted)
+
+		_, err = uow.BulkUpdate(ctx, []*TestUser{nil, valid})
+		assert.ErrorIs(t, err, uowerrors.ErrNilEntity)
+		assert.ErrorContains(t, err, "index 0")
+	})
+	assert.True(t, valid.GetID().IsZero(), "valid elements are left untouched")
+	assert.Zero(t, uow.Stats().Total())
+}
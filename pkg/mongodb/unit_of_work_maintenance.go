@@ -0,0 +1,258 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+)
+
+// BackfillTimestamps sets createdAt and updatedAt on documents that lack them,
+// deriving the value from the generation time embedded in their ObjectID.
+// Soft-deleted documents are included. It returns the number of documents
+// modified.
+func (uow *UnitOfWork[T]) BackfillTimestamps(ctx context.Context) (int64, error) {
+	collection := uow.getCollection()
+
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"createdAt": bson.M{"$exists": false}},
+			bson.M{"updatedAt": bson.M{"$exists": false}},
+		},
+	}
+
+	createdAt := bson.M{"$ifNull": bson.A{"$createdAt", bson.M{"$toDate": "$_id"}}}
+	update := bson.A{
+		bson.M{"$set": bson.M{
+			"createdAt": createdAt,
+			"updatedAt": bson.M{"$ifNull": bson.A{"$updatedAt", createdAt}},
+		}},
+	}
+
+	uow.track(opUpdate)
+	result, err := collection.UpdateMany(uow.getContext(ctx), filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to backfill timestamps: %w", err)
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// RenameField moves the value of field from to field to on every document
+// that has it, soft-deleted ones included, and returns the number of
+// documents modified. An existing value at to is overwritten.
+func (uow *UnitOfWork[T]) RenameField(ctx context.Context, from, to string) (int64, error) {
+	if from == "" || to == "" || from == to {
+		return 0, fmt.Errorf("%w: cannot rename field %q to %q", uowerrors.ErrInvalidQuery, from, to)
+	}
+	if from == "_id" || to == "_id" {
+		return 0, fmt.Errorf("%w: cannot rename _id", uowerrors.ErrInvalidQuery)
+	}
+
+	collection := uow.getCollection()
+
+	filter := bson.M{from: bson.M{"$exists": true}}
+	update := bson.M{"$rename": bson.M{from: to}}
+
+	uow.track(opUpdate)
+	result, err := collection.UpdateMany(uow.getContext(ctx), filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to rename field: %w", err)
+	}
+
+	return result.ModifiedCount, nil
+}
+
+const defaultMigrateBatch = 500
+
+// MigrateDocuments passes every document matching filter, soft-deleted ones
+// included, through transform and writes the changes back with bulk updates
+// of batchSize documents (500 when zero or negative). transform receives a
+// copy of the stored document, which it may edit in place, and returns its new
+// form: keys it adds or changes are set, keys it drops are unset, and a nil
+// result leaves the document untouched. It returns the number of documents
+// modified. A failing transform or write stops the migration; batches already
+// written are kept.
+func (uow *UnitOfWork[T]) MigrateDocuments(ctx context.Context, filter identifier.IIdentifier, transform func(bson.M) (bson.M, error), batchSize int) (int64, error) {
+	if transform == nil {
+		return 0, fmt.Errorf("%w: transform is required", uowerrors.ErrInvalidQuery)
+	}
+	if batchSize <= 0 {
+		batchSize = defaultMigrateBatch
+	}
+	query := bson.M{}
+	if filter != nil {
+		query = filter.ToBSON()
+	}
+
+	ctx = uow.getContext(ctx)
+	collection := uow.getCollection()
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetBatchSize(int32(batchSize))
+
+	uow.track(opFind)
+	cursor, err := collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find documents to migrate: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var modified int64
+	models := make([]mongo.WriteModel, 0, batchSize)
+	flush := func() error {
+		if len(models) == 0 {
+			return nil
+		}
+		uow.track(opUpdate)
+		result, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+		if result != nil {
+			modified += result.ModifiedCount
+		}
+		models = models[:0]
+		if err != nil {
+			return fmt.Errorf("failed to write migrated documents: %w", err)
+		}
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		id, update, err := migrateDocument(cursor.Current, transform)
+		if err != nil {
+			return modified, err
+		}
+		if len(update) == 0 {
+			continue
+		}
+
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": id}).SetUpdate(update))
+		if len(models) == batchSize {
+			if err := flush(); err != nil {
+				return modified, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return modified, fmt.Errorf("failed to read documents to migrate: %w", err)
+	}
+	if err := flush(); err != nil {
+		return modified, err
+	}
+
+	return modified, nil
+}
+
+// migrateDocument runs transform on its own copy of doc, so changes made to the
+// map in place still show up against the stored original, and returns the
+// document's _id with the resulting update
+func migrateDocument(doc bson.Raw, transform func(bson.M) (bson.M, error)) (interface{}, bson.M, error) {
+	var original, working bson.M
+	if err := bson.Unmarshal(doc, &original); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode document: %w", err)
+	}
+	if err := bson.Unmarshal(doc, &working); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode document: %w", err)
+	}
+	id := original["_id"]
+
+	migrated, err := transform(working)
+	if err != nil {
+		return id, nil, fmt.Errorf("failed to migrate document %v: %w", id, err)
+	}
+	if migrated == nil {
+		return id, nil, nil
+	}
+	update, err := migrationUpdate(original, migrated)
+	if err != nil {
+		return id, nil, fmt.Errorf("failed to migrate document %v: %w", id, err)
+	}
+	return id, update, nil
+}
+
+// migrationUpdate builds the update turning before into after: $set for added
+// or changed keys and $unset for removed ones. Changing _id is rejected.
+func migrationUpdate(before, after bson.M) (bson.M, error) {
+	if id, ok := after["_id"]; ok && !reflect.DeepEqual(id, before["_id"]) {
+		return nil, fmt.Errorf("%w: transform cannot change _id", uowerrors.ErrInvalidQuery)
+	}
+
+	set := bson.M{}
+	for key, value := range after {
+		if key == "_id" {
+			continue
+		}
+		if old, ok := before[key]; !ok || !reflect.DeepEqual(old, value) {
+			set[key] = value
+		}
+	}
+	unset := bson.M{}
+	for key := range before {
+		if _, ok := after[key]; !ok && key != "_id" {
+			unset[key] = ""
+		}
+	}
+
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	return update, nil
+}
+
+// DuplicateGroup is a value of a field shared by more than one document
+type DuplicateGroup struct {
+	Value interface{}          `bson:"_id" json:"value"`
+	Count int64                `bson:"count" json:"count"`
+	IDs   []primitive.ObjectID `bson:"ids" json:"ids"`
+}
+
+// duplicatesPipeline groups documents by field and keeps the values held by
+// more than one of them, most repeated first
+func duplicatesPipeline(field string) bson.A {
+	return bson.A{
+		bson.M{"$match": bson.M{field: bson.M{"$exists": true, "$ne": nil}}},
+		bson.M{"$group": bson.M{
+			"_id":   "$" + field,
+			"count": bson.M{"$sum": 1},
+			"ids":   bson.M{"$push": "$_id"},
+		}},
+		bson.M{"$match": bson.M{"count": bson.M{"$gt": 1}}},
+		bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+	}
+}
+
+// VerifyUnique reports the values of field held by more than one document, so
+// a unique index can be added safely once it returns none. Soft-deleted
+// documents are included because the index covers them too. Documents
+// missing the field or holding null are skipped; a non-sparse unique index
+// also rejects more than one of those.
+func (uow *UnitOfWork[T]) VerifyUnique(ctx context.Context, field string) ([]DuplicateGroup, error) {
+	if field == "" {
+		return nil, fmt.Errorf("%w: field is required", uowerrors.ErrInvalidQuery)
+	}
+
+	uow.track(opFind)
+	queryCtx := uow.getContext(ctx)
+	cursor, err := uow.getCollection().Aggregate(queryCtx, duplicatesPipeline(field), options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify unique %s: %w", field, err)
+	}
+	defer cursor.Close(queryCtx)
+
+	groups := []DuplicateGroup{}
+	if err := cursor.All(queryCtx, &groups); err != nil {
+		return nil, fmt.Errorf("failed to decode duplicates: %w", err)
+	}
+	return groups, nil
+}
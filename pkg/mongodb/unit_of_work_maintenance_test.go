@@ -0,0 +1,240 @@
+package mongodb
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+)
+
+func TestUnitOfWork_BackfillTimestamps(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	legacyID := primitive.NewObjectIDFromTimestamp(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	partialID := primitive.NewObjectIDFromTimestamp(time.Date(2021, 6, 7, 8, 9, 10, 0, time.UTC))
+	partialCreated := time.Date(2021, 6, 7, 0, 0, 0, 0, time.UTC)
+	currentID := primitive.NewObjectID()
+	currentTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := uow.getCollection().InsertMany(ctx, []interface{}{
+		bson.M{"_id": legacyID, "email": "legacy@example.com"},
+		bson.M{"_id": partialID, "email": "partial@example.com", "createdAt": partialCreated},
+		bson.M{"_id": currentID, "email": "current@example.com", "createdAt": currentTime, "updatedAt": currentTime},
+	})
+	require.NoError(t, err)
+
+	modified, err := uow.BackfillTimestamps(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), modified)
+
+	legacy, err := uow.FindOneById(ctx, legacyID)
+	require.NoError(t, err)
+	assert.True(t, legacyID.Timestamp().Equal(legacy.CreatedAt))
+	assert.True(t, legacyID.Timestamp().Equal(legacy.UpdatedAt))
+
+	partial, err := uow.FindOneById(ctx, partialID)
+	require.NoError(t, err)
+	assert.True(t, partialCreated.Equal(partial.CreatedAt))
+	assert.True(t, partialCreated.Equal(partial.UpdatedAt))
+
+	current, err := uow.FindOneById(ctx, currentID)
+	require.NoError(t, err)
+	assert.True(t, currentTime.Equal(current.CreatedAt))
+
+	modified, err = uow.BackfillTimestamps(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, modified)
+}
+
+func TestUnitOfWork_RenameField_Invalid(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+	ctx := context.Background()
+
+	for _, tc := range []struct{ from, to string }{
+		{"", "available"},
+		{"inStock", ""},
+		{"inStock", "inStock"},
+		{"_id", "id"},
+	} {
+		_, err := uow.RenameField(ctx, tc.from, tc.to)
+		assert.ErrorIs(t, err, uowerrors.ErrInvalidQuery, "%q -> %q", tc.from, tc.to)
+	}
+}
+
+func TestUnitOfWork_RenameField(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	legacyID := primitive.NewObjectID()
+	migratedID := primitive.NewObjectID()
+	_, err := uow.getCollection().InsertMany(ctx, []interface{}{
+		bson.M{"_id": legacyID, "email": "legacy@example.com", "isActive": true},
+		bson.M{"_id": migratedID, "email": "migrated@example.com", "active": true},
+	})
+	require.NoError(t, err)
+
+	modified, err := uow.RenameField(ctx, "isActive", "active")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), modified)
+
+	var legacy bson.M
+	require.NoError(t, uow.getCollection().FindOne(ctx, bson.M{"_id": legacyID}).Decode(&legacy))
+	assert.NotContains(t, legacy, "isActive")
+	assert.Equal(t, true, legacy["active"])
+
+	user, err := uow.FindOneById(ctx, legacyID)
+	require.NoError(t, err)
+	assert.True(t, user.Active)
+
+	modified, err = uow.RenameField(ctx, "isActive", "active")
+	require.NoError(t, err)
+	assert.Zero(t, modified)
+}
+
+func TestDuplicatesPipeline(t *testing.T) {
+	pipeline := duplicatesPipeline("email")
+
+	require.Len(t, pipeline, 4)
+	assert.Equal(t, bson.M{"$match": bson.M{"email": bson.M{"$exists": true, "$ne": nil}}}, pipeline[0])
+	assert.Equal(t, bson.M{"$group": bson.M{
+		"_id":   "$email",
+		"count": bson.M{"$sum": 1},
+		"ids":   bson.M{"$push": "$_id"},
+	}}, pipeline[1])
+	assert.Equal(t, bson.M{"$match": bson.M{"count": bson.M{"$gt": 1}}}, pipeline[2])
+}
+
+func TestUnitOfWork_VerifyUnique_Invalid(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+
+	_, err := uow.VerifyUnique(context.Background(), "")
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQuery)
+}
+
+func TestUnitOfWork_VerifyUnique(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	groups, err := uow.VerifyUnique(ctx, "email")
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+
+	first, err := uow.Insert(ctx, &TestUser{Email: "dup@example.com"})
+	require.NoError(t, err)
+	second, err := uow.Insert(ctx, &TestUser{Email: "dup@example.com"})
+	require.NoError(t, err)
+	trashed, err := uow.Insert(ctx, &TestUser{Email: "dup@example.com"})
+	require.NoError(t, err)
+	_, err = uow.SoftDelete(ctx, identifier.New().Equal("_id", trashed.GetID()))
+	require.NoError(t, err)
+	_, err = uow.Insert(ctx, &TestUser{Email: "unique@example.com"})
+	require.NoError(t, err)
+
+	groups, err = uow.VerifyUnique(ctx, "email")
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "dup@example.com", groups[0].Value)
+	assert.Equal(t, int64(3), groups[0].Count, "soft-deleted documents count towards a unique index")
+	assert.ElementsMatch(t, []primitive.ObjectID{first.GetID(), second.GetID(), trashed.GetID()}, groups[0].IDs)
+}
+
+func TestUnitOfWork_MigrateDocuments_NilTransform(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+
+	_, err := uow.MigrateDocuments(context.Background(), nil, nil, 0)
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQuery)
+}
+
+func TestMigrationUpdate(t *testing.T) {
+	id := primitive.NewObjectID()
+	before := bson.M{"_id": id, "fullName": "Ada Lovelace", "age": int32(36), "active": true}
+
+	update, err := migrationUpdate(before, bson.M{"_id": id, "firstName": "Ada", "age": int32(36), "active": false})
+	require.NoError(t, err)
+	assert.Equal(t, bson.M{
+		"$set":   bson.M{"firstName": "Ada", "active": false},
+		"$unset": bson.M{"fullName": ""},
+	}, update)
+
+	update, err = migrationUpdate(before, bson.M{"fullName": "Ada Lovelace", "age": int32(36), "active": true})
+	require.NoError(t, err)
+	assert.Empty(t, update)
+
+	_, err = migrationUpdate(before, bson.M{"_id": primitive.NewObjectID()})
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQuery)
+}
+
+func TestMigrateDocument_InPlaceTransform(t *testing.T) {
+	id := primitive.NewObjectID()
+	doc, err := bson.Marshal(bson.M{"_id": id, "fullName": "Ada Lovelace"})
+	require.NoError(t, err)
+
+	inPlace := func(doc bson.M) (bson.M, error) {
+		first, last, _ := strings.Cut(doc["fullName"].(string), " ")
+		doc["firstName"] = first
+		doc["lastName"] = last
+		delete(doc, "fullName")
+		return doc, nil
+	}
+
+	gotID, update, err := migrateDocument(doc, inPlace)
+	require.NoError(t, err)
+	assert.Equal(t, id, gotID)
+	assert.Equal(t, bson.M{
+		"$set":   bson.M{"firstName": "Ada", "lastName": "Lovelace"},
+		"$unset": bson.M{"fullName": ""},
+	}, update)
+
+	_, update, err = migrateDocument(doc, func(bson.M) (bson.M, error) { return nil, nil })
+	require.NoError(t, err)
+	assert.Empty(t, update)
+
+	_, _, err = migrateDocument(doc, func(bson.M) (bson.M, error) { return nil, assert.AnError })
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestUnitOfWork_MigrateDocuments(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	docs := []interface{}{
+		bson.M{"email": "ada@example.com", "fullName": "Ada Lovelace", "active": true},
+		bson.M{"email": "alan@example.com", "fullName": "Alan Turing", "active": true},
+		bson.M{"email": "grace@example.com", "fullName": "Grace Hopper", "active": true},
+		bson.M{"email": "inactive@example.com", "fullName": "Kept As Is", "active": false},
+	}
+	_, err := uow.getCollection().InsertMany(ctx, docs)
+	require.NoError(t, err)
+
+	split := func(doc bson.M) (bson.M, error) {
+		first, last, _ := strings.Cut(doc["fullName"].(string), " ")
+		doc["firstName"] = first
+		doc["lastName"] = last
+		delete(doc, "fullName")
+		return doc, nil
+	}
+
+	modified, err := uow.MigrateDocuments(ctx, identifier.New().Equal("active", true), split, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), modified)
+
+	var alan bson.M
+	require.NoError(t, uow.getCollection().FindOne(ctx, bson.M{"email": "alan@example.com"}).Decode(&alan))
+	assert.Equal(t, "Alan", alan["firstName"])
+	assert.Equal(t, "Turing", alan["lastName"])
+	assert.NotContains(t, alan, "fullName")
+
+	var kept bson.M
+	require.NoError(t, uow.getCollection().FindOne(ctx, bson.M{"email": "inactive@example.com"}).Decode(&kept))
+	assert.Equal(t, "Kept As Is", kept["fullName"])
+	assert.NotContains(t, kept, "firstName")
+}
@@ -0,0 +1,105 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+)
+
+func TestRawFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   bson.M
+		expected bson.M
+	}{
+		{
+			name:   "adds soft-delete condition",
+			filter: bson.M{"email": "a@example.com"},
+			expected: bson.M{
+				"email":     "a@example.com",
+				"deletedAt": nil,
+			},
+		},
+		{
+			name:     "keeps top-level deletedAt",
+			filter:   bson.M{"deletedAt": bson.M{"$exists": true}},
+			expected: bson.M{"deletedAt": bson.M{"$exists": true}},
+		},
+		{
+			name: "keeps deletedAt nested in $or",
+			filter: bson.M{"$or": bson.A{
+				bson.M{"deletedAt": nil},
+				bson.M{"age": bson.M{"$gt": 18}},
+			}},
+			expected: bson.M{"$or": bson.A{
+				bson.M{"deletedAt": nil},
+				bson.M{"age": bson.M{"$gt": 18}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, rawFilter(tt.filter))
+		})
+	}
+}
+
+func TestRawFilter_DoesNotMutateInput(t *testing.T) {
+	filter := bson.M{"email": "a@example.com"}
+
+	_ = rawFilter(filter)
+
+	assert.Equal(t, bson.M{"email": "a@example.com"}, filter)
+}
+
+func TestUnitOfWork_FindRaw_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	_, err := uow.BulkInsert(ctx, []*TestUser{
+		{Email: "young@example.com", Age: 15},
+		{Email: "old@example.com", Age: 70},
+		{Email: "middle@example.com", Age: 40},
+		{Email: "trashed@example.com", Age: 80},
+	})
+	require.NoError(t, err)
+
+	_, err = uow.SoftDelete(ctx, identifier.New().Equal("email", "trashed@example.com"))
+	require.NoError(t, err)
+
+	orFilter := bson.M{"$or": bson.A{
+		bson.M{"age": bson.M{"$lt": 18}},
+		bson.M{"age": bson.M{"$gt": 65}},
+	}}
+
+	users, err := uow.FindAllRaw(ctx, orFilter, options.Find().SetSort(bson.M{"age": 1}))
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, "young@example.com", users[0].Email)
+	assert.Equal(t, "old@example.com", users[1].Email)
+
+	withTrashed := bson.M{"$or": bson.A{
+		bson.M{"age": bson.M{"$gt": 65}},
+		bson.M{"deletedAt": bson.M{"$exists": true}},
+	}}
+	users, err = uow.FindAllRaw(ctx, withTrashed)
+	require.NoError(t, err)
+	assert.Len(t, users, 2)
+
+	user, err := uow.FindOneRaw(ctx, bson.M{"$or": bson.A{
+		bson.M{"email": "middle@example.com"},
+		bson.M{"email": "trashed@example.com"},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "middle@example.com", user.Email)
+
+	_, err = uow.FindOneRaw(ctx, bson.M{"email": "trashed@example.com"})
+	assert.EqualError(t, err, "entity not found")
+}
@@ -0,0 +1,325 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+)
+
+type LedgerEntry struct {
+	domain.BaseEntity `bson:",inline"`
+	Amount            int64 `bson:"amount" json:"amount"`
+}
+
+func (LedgerEntry) SoftDeletable() bool { return false }
+
+func TestSupportsSoftDelete(t *testing.T) {
+	assert.True(t, supportsSoftDelete((*TestUser)(nil)))
+	assert.False(t, supportsSoftDelete((*LedgerEntry)(nil)))
+	assert.False(t, supportsSoftDelete(LedgerEntry{}))
+}
+
+func TestUnitOfWork_ExcludeDeleted(t *testing.T) {
+	users := newOfflineUnitOfWork[*TestUser](t, nil)
+	ledger := newOfflineUnitOfWork[*LedgerEntry](t, nil)
+
+	assert.Equal(t, bson.M{"amount": 1, "deletedAt": nil}, users.excludeDeleted(bson.M{"amount": 1}))
+	assert.Equal(t, bson.M{"amount": 1}, ledger.excludeDeleted(bson.M{"amount": 1}))
+}
+
+func TestUnitOfWork_SoftDeleteDisabled_HardDeletes(t *testing.T) {
+	ledger := newOfflineUnitOfWork[*LedgerEntry](t, &Config{EnableStats: true})
+	ctx := context.Background()
+	id := identifier.New().Equal("amount", 10)
+
+	_, _ = ledger.SoftDelete(ctx, id)
+	_, _ = ledger.BulkSoftDeleteWithOptions(ctx, []identifier.IIdentifier{id}, persistence.BulkOptions{})
+
+	stats := ledger.Stats()
+	assert.Equal(t, int64(2), stats.Deletes)
+	assert.Zero(t, stats.Updates)
+}
+
+func TestDeletedAtRange(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60))
+	end := start.AddDate(0, 1, 0)
+
+	assert.Equal(t, bson.M{
+		"$gte": time.Date(2023, 12, 31, 22, 0, 0, 0, time.UTC),
+		"$lt":  time.Date(2024, 1, 31, 22, 0, 0, 0, time.UTC),
+	}, deletedAtRange(start, end))
+}
+
+func TestUnitOfWork_GetTrashedInRange_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 12, 0, 0, 0, time.UTC) }
+	jan, mar1, mar5, mar10 := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), day(1), day(5), day(10)
+
+	_, err := uow.getCollection().InsertMany(ctx, []interface{}{
+		bson.M{"email": "live@example.com"},
+		bson.M{"email": "jan@example.com", "deletedAt": jan},
+		bson.M{"email": "mar1@example.com", "deletedAt": mar1},
+		bson.M{"email": "mar5@example.com", "deletedAt": mar5},
+		bson.M{"email": "mar10@example.com", "deletedAt": mar10},
+	})
+	require.NoError(t, err)
+
+	trashed, err := uow.GetTrashedInRange(ctx, mar1, mar10)
+	require.NoError(t, err)
+	emails := make([]string, len(trashed))
+	for i, user := range trashed {
+		emails[i] = user.Email
+	}
+	assert.ElementsMatch(t, []string{"mar1@example.com", "mar5@example.com"}, emails)
+
+	page, total, err := uow.GetTrashedInRangeWithPagination(ctx, jan, day(31), domain.QueryParams[*TestUser]{
+		Limit: 2,
+		Sort:  domain.SortMap{"deletedAt": domain.SortDesc},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint(4), total)
+	require.Len(t, page, 2)
+	assert.Equal(t, "mar10@example.com", page[0].Email)
+	assert.Equal(t, "mar5@example.com", page[1].Email)
+}
+
+func TestUnitOfWork_PurgeTrashedOlderThan_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	cutoff := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	_, err := uow.getCollection().InsertMany(ctx, []interface{}{
+		bson.M{"email": "live@example.com"},
+		bson.M{"email": "jan@example.com", "deletedAt": cutoff.AddDate(0, -2, 0)},
+		bson.M{"email": "feb@example.com", "deletedAt": cutoff.Add(-time.Hour)},
+		bson.M{"email": "mar@example.com", "deletedAt": cutoff},
+	})
+	require.NoError(t, err)
+
+	preview, err := uow.PurgeTrashedOlderThan(ctx, cutoff, true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), preview)
+
+	trashed, err := uow.GetTrashed(ctx)
+	require.NoError(t, err)
+	assert.Len(t, trashed, 3, "a dry run deletes nothing")
+
+	purged, err := uow.PurgeTrashedOlderThan(ctx, cutoff, false)
+	require.NoError(t, err)
+	assert.Equal(t, preview, purged)
+
+	all, err := uow.FindAllWithTrashed(ctx)
+	require.NoError(t, err)
+	emails := make([]string, len(all))
+	for i, user := range all {
+		emails[i] = user.Email
+	}
+	assert.ElementsMatch(t, []string{"live@example.com", "mar@example.com"}, emails)
+}
+
+func TestUnitOfWork_PurgeTrashedOlderThan_Unreachable(t *testing.T) {
+	users := newOfflineUnitOfWork[*TestUser](t, nil)
+	ctx := context.Background()
+
+	_, err := users.PurgeTrashedOlderThan(ctx, time.Now(), true)
+	assert.ErrorContains(t, err, "failed to count trashed")
+	_, err = users.PurgeTrashedOlderThan(ctx, time.Now(), false)
+	assert.ErrorContains(t, err, "failed to purge trashed")
+}
+
+func TestUnitOfWork_SoftDeleteReturningBefore_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	created, err := uow.Insert(ctx, &TestUser{Email: "before@example.com"})
+	require.NoError(t, err)
+	byID := identifier.New().Equal("_id", created.GetID())
+
+	before, err := uow.SoftDeleteReturningBefore(ctx, byID)
+	require.NoError(t, err)
+	assert.Equal(t, "before@example.com", before.Email)
+	assert.Nil(t, before.DeletedAt)
+
+	_, found, err := uow.TryFindOneById(ctx, created.GetID())
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestUnitOfWork_WithTrashedReads_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	kept, err := uow.Insert(ctx, &TestUser{Email: "kept@example.com"})
+	require.NoError(t, err)
+	trashed, err := uow.Insert(ctx, &TestUser{Email: "trashed@example.com"})
+	require.NoError(t, err)
+	_, err = uow.SoftDelete(ctx, identifier.New().Equal("_id", trashed.GetID()))
+	require.NoError(t, err)
+
+	_, err = uow.FindOneById(ctx, trashed.GetID())
+	assert.Error(t, err)
+
+	found, err := uow.FindOneByIdWithTrashed(ctx, trashed.GetID())
+	require.NoError(t, err)
+	assert.True(t, found.IsDeleted())
+	require.NotNil(t, found.DeletedAt)
+
+	found, err = uow.FindOneByIdWithTrashed(ctx, kept.GetID())
+	require.NoError(t, err)
+	assert.False(t, found.IsDeleted())
+
+	all, err := uow.FindAllWithTrashed(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+
+	deleted := map[string]bool{}
+	for _, user := range all {
+		deleted[user.Email] = user.IsDeleted()
+	}
+	assert.Equal(t, map[string]bool{"kept@example.com": false, "trashed@example.com": true}, deleted)
+}
+
+func TestUnitOfWork_TrashScope(t *testing.T) {
+	users := newOfflineUnitOfWork[*TestUser](t, nil)
+
+	tests := []struct {
+		mode     domain.TrashMode
+		expected bson.M
+	}{
+		{"", bson.M{"deletedAt": nil}},
+		{domain.LiveOnly, bson.M{"deletedAt": nil}},
+		{domain.TrashedOnly, bson.M{"deletedAt": bson.M{"$ne": nil}}},
+		{domain.All, bson.M{}},
+	}
+	for _, tt := range tests {
+		collection, filter, err := users.trashScope(tt.mode)
+		require.NoError(t, err)
+		assert.Equal(t, "testusers", collection.Name())
+		assert.Equal(t, tt.expected, filter, "mode %q", tt.mode)
+	}
+
+	_, _, err := users.trashScope("deleted")
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQueryParams)
+
+	users.trashMode = true
+	collection, filter, err := users.trashScope(domain.TrashedOnly)
+	require.NoError(t, err)
+	assert.Equal(t, "testusers_trash", collection.Name())
+	assert.Empty(t, filter)
+	_, _, err = users.trashScope(domain.All)
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQueryParams)
+}
+
+func TestFindOptionsTrashMode(t *testing.T) {
+	tests := []struct {
+		opts     domain.FindOptions
+		expected domain.TrashMode
+	}{
+		{domain.FindOptions{}, domain.LiveOnly},
+		{domain.FindOptions{IncludeTrashed: true}, domain.All},
+		{domain.FindOptions{OnlyTrashed: true}, domain.TrashedOnly},
+	}
+	for _, tt := range tests {
+		mode, err := findOptionsTrashMode(tt.opts)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, mode, "%+v", tt.opts)
+	}
+
+	_, err := findOptionsTrashMode(domain.FindOptions{IncludeTrashed: true, OnlyTrashed: true})
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQueryParams)
+}
+
+func TestUnitOfWork_FindAllWithOptions_Conflict(t *testing.T) {
+	users := newOfflineUnitOfWork[*TestUser](t, nil)
+	ctx := context.Background()
+
+	_, _, err := users.FindAllWithOptions(ctx, domain.QueryParams[*TestUser]{}, domain.FindOptions{IncludeTrashed: true, OnlyTrashed: true})
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQueryParams)
+
+	_, _, err = users.FindAllWithOptions(ctx, domain.QueryParams[*TestUser]{Trash: domain.LiveOnly}, domain.FindOptions{OnlyTrashed: true})
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQueryParams)
+}
+
+func TestUnitOfWork_FindAllWithOptions_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com"} {
+		_, err := uow.Insert(ctx, &TestUser{Email: email})
+		require.NoError(t, err)
+	}
+	_, err := uow.SoftDeleteMany(ctx, identifier.New().In("email", []interface{}{"c@example.com", "d@example.com"}))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		opts     domain.FindOptions
+		expected []string
+		total    uint
+	}{
+		{"live", domain.FindOptions{}, []string{"a@example.com", "b@example.com"}, 2},
+		{"include trashed", domain.FindOptions{IncludeTrashed: true}, []string{"a@example.com", "b@example.com", "c@example.com"}, 4},
+		{"only trashed", domain.FindOptions{OnlyTrashed: true}, []string{"c@example.com", "d@example.com"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := domain.QueryParams[*TestUser]{
+				Sort:  domain.SortMap{"email": domain.SortAsc},
+				Limit: 3,
+			}
+			users, total, err := uow.FindAllWithOptions(ctx, query, tt.opts)
+			require.NoError(t, err)
+
+			emails := make([]string, len(users))
+			for i, user := range users {
+				emails[i] = user.Email
+			}
+			assert.Equal(t, tt.expected, emails)
+			assert.Equal(t, tt.total, total)
+		})
+	}
+}
+
+func TestUnitOfWork_FindAllWithPagination_TrashMode_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	for _, email := range []string{"live@example.com", "trashed@example.com"} {
+		_, err := uow.Insert(ctx, &TestUser{Email: email})
+		require.NoError(t, err)
+	}
+	_, err := uow.SoftDelete(ctx, identifier.New().Equal("email", "trashed@example.com"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		mode     domain.TrashMode
+		expected map[string]bool
+	}{
+		{"", map[string]bool{"live@example.com": false}},
+		{domain.LiveOnly, map[string]bool{"live@example.com": false}},
+		{domain.TrashedOnly, map[string]bool{"trashed@example.com": true}},
+		{domain.All, map[string]bool{"live@example.com": false, "trashed@example.com": true}},
+	}
+	for _, tt := range tests {
+		users, total, err := uow.FindAllWithPagination(ctx, domain.QueryParams[*TestUser]{Trash: tt.mode})
+		require.NoError(t, err)
+		assert.Equal(t, uint(len(tt.expected)), total)
+
+		deleted := map[string]bool{}
+		for _, user := range users {
+			deleted[user.Email] = user.IsDeleted()
+		}
+		assert.Equal(t, tt.expected, deleted, "mode %q", tt.mode)
+	}
+}
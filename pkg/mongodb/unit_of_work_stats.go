@@ -0,0 +1,56 @@
+package mongodb
+
+import (
+	"sync/atomic"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+)
+
+type operationKind int
+
+const (
+	opFind operationKind = iota
+	opCount
+	opInsert
+	opUpdate
+	opDelete
+	opKindCount
+)
+
+// operationCounters holds the per-UoW counters. A nil pointer means stats
+// collection is disabled and tracking is a single nil check.
+type operationCounters struct {
+	counts [opKindCount]atomic.Int64
+}
+
+func (uow *UnitOfWork[T]) track(kind operationKind) {
+	if uow.stats == nil {
+		return
+	}
+	uow.stats.counts[kind].Add(1)
+}
+
+// Stats returns a snapshot of the operations issued through this unit of work.
+// It returns zero values when stats collection is disabled.
+func (uow *UnitOfWork[T]) Stats() persistence.OperationStats {
+	if uow.stats == nil {
+		return persistence.OperationStats{}
+	}
+	return persistence.OperationStats{
+		Finds:   uow.stats.counts[opFind].Load(),
+		Counts:  uow.stats.counts[opCount].Load(),
+		Inserts: uow.stats.counts[opInsert].Load(),
+		Updates: uow.stats.counts[opUpdate].Load(),
+		Deletes: uow.stats.counts[opDelete].Load(),
+	}
+}
+
+// ResetStats sets all operation counters back to zero.
+func (uow *UnitOfWork[T]) ResetStats() {
+	if uow.stats == nil {
+		return
+	}
+	for i := range uow.stats.counts {
+		uow.stats.counts[i].Store(0)
+	}
+}
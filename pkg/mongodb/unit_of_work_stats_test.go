@@ -0,0 +1,62 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+)
+
+func TestUnitOfWork_StatsDisabled(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, &Config{})
+	ctx := context.Background()
+
+	_, _ = uow.FindAll(ctx)
+	_, _ = uow.Insert(ctx, &TestUser{})
+
+	assert.Equal(t, persistence.OperationStats{}, uow.Stats())
+	uow.ResetStats()
+}
+
+func TestUnitOfWork_StatsCountOperations(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, &Config{EnableStats: true})
+	ctx := context.Background()
+	id := identifier.New().Equal("_id", primitive.NewObjectID())
+
+	_, _ = uow.FindAll(ctx)
+	_, _ = uow.FindOneById(ctx, primitive.NewObjectID())
+	_, _ = uow.FindOneByIdentifier(ctx, id)
+	_, _, _ = uow.FindAllWithPagination(ctx, domain.QueryParams[*TestUser]{})
+	_, _ = uow.Insert(ctx, &TestUser{})
+	_, _ = uow.BulkInsert(ctx, []*TestUser{{}, {}})
+	_, _ = uow.Update(ctx, id, &TestUser{})
+	_, _ = uow.SoftDelete(ctx, id)
+	_, _ = uow.Restore(ctx, id)
+	_ = uow.Delete(ctx, id)
+	_, _ = uow.HardDelete(ctx, id)
+
+	stats := uow.Stats()
+	assert.Equal(t, int64(3), stats.Finds)
+	assert.Equal(t, int64(1), stats.Counts)
+	assert.Equal(t, int64(2), stats.Inserts)
+	assert.Equal(t, int64(3), stats.Updates)
+	assert.Equal(t, int64(2), stats.Deletes)
+	assert.Equal(t, int64(11), stats.Total())
+
+	uow.ResetStats()
+	assert.Equal(t, persistence.OperationStats{}, uow.Stats())
+}
+
+func TestUnitOfWork_StatsSharedWithContext(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, &Config{EnableStats: true})
+
+	scoped := uow.WithContext(context.Background())
+	_, _ = scoped.FindAll(context.Background())
+
+	assert.Equal(t, int64(1), uow.Stats().Finds)
+}
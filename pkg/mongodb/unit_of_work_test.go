@@ -1,15 +1,22 @@
 package mongodb
 
 import (
+	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
 )
 
 type TestUser struct {
@@ -19,6 +26,75 @@ type TestUser struct {
 	Active            bool   `bson:"active" json:"active"`
 }
 
+// newOfflineUnitOfWork builds a unit of work whose client points at an
+// unreachable server, so every operation fails fast without MongoDB.
+func newOfflineUnitOfWork[T persistence.ModelConstraint](t *testing.T, config *Config) *UnitOfWork[T] {
+	t.Helper()
+
+	clientOptions := options.Client().
+		ApplyURI("mongodb://127.0.0.1:1").
+		SetServerSelectionTimeout(50 * time.Millisecond)
+
+	client, err := mongo.Connect(context.Background(), clientOptions)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Disconnect(context.Background()) })
+
+	var stats *operationCounters
+	if config != nil && config.EnableStats {
+		stats = &operationCounters{}
+	}
+
+	var zero T
+	return &UnitOfWork[T]{
+		client:         client,
+		database:       client.Database("offline"),
+		ctx:            context.Background(),
+		repositories:   make(map[string]interface{}),
+		collectionName: getCollectionName(zero),
+		softDelete:     supportsSoftDelete(zero),
+		slugAttempts:   defaultSlugAttempts,
+		slugIndex:      newSlugIndexes(),
+		stats:          stats,
+	}
+}
+
+// integrationUnavailable remembers a failed connection attempt so the
+// remaining integration tests skip without waiting on the timeout again.
+var integrationUnavailable error
+
+// newIntegrationUnitOfWork connects to a local MongoDB using a throwaway
+// database, skipping the test when no server is reachable. Options are applied
+// as a Factory would apply them.
+func newIntegrationUnitOfWork[T persistence.ModelConstraint](t *testing.T, opts ...FactoryOption) *UnitOfWork[T] {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+	if integrationUnavailable != nil {
+		t.Skipf("Integration test requires MongoDB instance: %v", integrationUnavailable)
+	}
+
+	config := NewConfig()
+	config.Database = "uow_test_" + primitive.NewObjectID().Hex()
+	config.Timeout = 2 * time.Second
+
+	factory, err := NewFactory[T](config, opts...)
+	require.NoError(t, err)
+
+	uow, err := factory.newUnitOfWork()
+	if err != nil {
+		integrationUnavailable = err
+		t.Skipf("Integration test requires MongoDB instance: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx := context.Background()
+		_ = uow.database.Drop(ctx)
+		_ = factory.Close(ctx)
+	})
+
+	return uow
+}
+
 func TestConfig_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -173,6 +249,8 @@ func TestNewConfig(t *testing.T) {
 	assert.Equal(t, 30*time.Second, config.MaxIdleTime)
 	assert.Equal(t, 10*time.Second, config.Timeout)
 	assert.False(t, config.SSL)
+	assert.Equal(t, 5*time.Second, config.ServerSelectionTimeout)
+	assert.Equal(t, 10*time.Second, config.ConnectTimeout)
 }
 
 func TestFactory_Create(t *testing.T) {
@@ -232,6 +310,51 @@ func TestUnitOfWork_GetCollectionName(t *testing.T) {
 	assert.Equal(t, "testusers", name)
 }
 
+func TestCollectionName(t *testing.T) {
+	factory, err := NewFactory[*TestUser](NewConfig())
+	require.NoError(t, err)
+
+	assert.Equal(t, "testusers", newOfflineUnitOfWork[*TestUser](t, nil).CollectionName())
+	assert.Equal(t, "testusers", NewBaseRepository[*TestUser](factory).CollectionName())
+
+	assert.Equal(t, "testposts", newOfflineUnitOfWork[*TestPost](t, nil).CollectionName())
+	assert.Equal(t, "testtaggedproducts", newOfflineUnitOfWork[*TestTaggedProduct](t, nil).CollectionName())
+}
+
+// TestCategory picks its own collection name instead of "testcategorys"
+type TestCategory struct {
+	domain.BaseEntity `bson:",inline"`
+}
+
+func (*TestCategory) CollectionName() string { return "categories" }
+
+func TestCollectionName_Override(t *testing.T) {
+	assert.Equal(t, "categories", getCollectionName(TestCategory{}))
+	assert.Equal(t, "categories", getCollectionName((*TestCategory)(nil)), "the method is not called on the nil zero value")
+	assert.Equal(t, "categories", newOfflineUnitOfWork[*TestCategory](t, nil).CollectionName())
+
+	factory, err := NewFactory[*TestCategory](NewConfig())
+	require.NoError(t, err)
+	assert.Equal(t, "categories", NewBaseRepository[*TestCategory](factory).CollectionName())
+}
+
+func TestFactory_WithCollectionName(t *testing.T) {
+	factory, err := NewFactory[*TestUser](unreachableConfig(), WithCollectionName("people"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "people", NewBaseRepository[*TestUser](factory).CollectionName())
+	assert.Equal(t, "people", factory.Create().CollectionName(), "a disconnected unit of work keeps the name")
+
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+	factory.apply(uow)
+	assert.Equal(t, "people", uow.CollectionName())
+	assert.Equal(t, "people", uow.view().CollectionName())
+
+	categories, err := NewFactory[*TestCategory](NewConfig(), WithCollectionName("taxonomy"))
+	require.NoError(t, err)
+	assert.Equal(t, "taxonomy", NewBaseRepository[*TestCategory](categories).CollectionName(), "the factory option wins over the entity")
+}
+
 func TestBaseEntity_Methods(t *testing.T) {
 	entity := &domain.BaseEntity{}
 
@@ -276,3 +399,754 @@ func TestQueryParams_Validate(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 1000, query.Limit)
 }
+
+func TestQueryParams_ValidateWithMax(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    int
+		max      int
+		expected int
+	}{
+		{"ui list caps at 100", 500, 100, 100},
+		{"export allows 10000", 5000, 10000, 5000},
+		{"export caps at 10000", 20000, 10000, 10000},
+		{"within cap unchanged", 50, 100, 50},
+		{"negative limit defaults", -1, 100, 10},
+		{"zero max keeps default cap", 2000, 0, domain.DefaultMaxLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := &domain.QueryParams[*TestUser]{Limit: tt.limit}
+			assert.NoError(t, query.ValidateWithMax(tt.max))
+			assert.Equal(t, tt.expected, query.Limit)
+		})
+	}
+}
+
+func TestUnitOfWork_TryFindOne_Error(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+	ctx := context.Background()
+
+	user, found, err := uow.TryFindOneById(ctx, primitive.NewObjectID())
+	assert.Error(t, err)
+	assert.False(t, found)
+	assert.Nil(t, user)
+
+	user, found, err = uow.TryFindOne(ctx, identifier.New().Equal("email", "missing@example.com"))
+	assert.Error(t, err)
+	assert.False(t, found)
+	assert.Nil(t, user)
+}
+
+func TestUnitOfWork_TryFindOne_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	inserted, err := uow.Insert(ctx, &TestUser{Email: "found@example.com"})
+	require.NoError(t, err)
+
+	user, found, err := uow.TryFindOneById(ctx, inserted.GetID())
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "found@example.com", user.Email)
+
+	user, found, err = uow.TryFindOne(ctx, identifier.New().Equal("email", "found@example.com"))
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, inserted.GetID(), user.GetID())
+
+	user, found, err = uow.TryFindOneById(ctx, primitive.NewObjectID())
+	require.NoError(t, err)
+	assert.False(t, found)
+	assert.Nil(t, user)
+
+	user, found, err = uow.TryFindOne(ctx, identifier.New().Equal("email", "missing@example.com"))
+	require.NoError(t, err)
+	assert.False(t, found)
+	assert.Nil(t, user)
+}
+
+func TestUnitOfWork_SetEntityTimestamp_UTC(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+	local := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("UTC+3", 3*60*60))
+
+	user := &TestUser{}
+	uow.setEntityTimestamp(user, "createdAt", local)
+
+	assert.Equal(t, time.UTC, user.CreatedAt.Location())
+	assert.True(t, local.Equal(user.CreatedAt))
+}
+
+func TestUnitOfWork_TimestampsStoredAsUTC_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	inserted, err := uow.Insert(ctx, &TestUser{Email: "utc@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, inserted.CreatedAt.Location())
+	assert.Equal(t, time.UTC, inserted.UpdatedAt.Location())
+
+	found, err := uow.FindOneById(ctx, inserted.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, found.CreatedAt.Location())
+	assert.Equal(t, inserted.CreatedAt.Truncate(time.Millisecond), found.CreatedAt)
+
+	deleted, err := uow.SoftDelete(ctx, identifier.New().Equal("_id", inserted.GetID()))
+	require.NoError(t, err)
+	require.NotNil(t, deleted.DeletedAt)
+	assert.Equal(t, time.UTC, deleted.DeletedAt.Location())
+}
+
+func TestUnitOfWork_UpdateIf_Error(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+
+	user, applied, err := uow.UpdateIf(context.Background(),
+		identifier.New().Equal("_id", primitive.NewObjectID()),
+		identifier.New().Equal("active", true),
+		bson.M{"age": 30},
+	)
+
+	assert.Error(t, err)
+	assert.False(t, applied)
+	assert.Nil(t, user)
+}
+
+func TestUnitOfWork_UpdateIf_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	inserted, err := uow.Insert(ctx, &TestUser{Email: "paid@example.com", Age: 20, Active: true})
+	require.NoError(t, err)
+	byID := identifier.New().Equal("_id", inserted.GetID())
+
+	updated, applied, err := uow.UpdateIf(ctx, byID, identifier.New().Equal("active", true), bson.M{"age": 21})
+	require.NoError(t, err)
+	assert.True(t, applied)
+	assert.Equal(t, 21, updated.Age)
+	assert.True(t, updated.Active)
+
+	updated, applied, err = uow.UpdateIf(ctx, byID, identifier.New().Equal("active", false), bson.M{"age": 99})
+	require.NoError(t, err)
+	assert.False(t, applied)
+	assert.Nil(t, updated)
+
+	current, err := uow.FindOneById(ctx, inserted.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, 21, current.Age)
+
+	_, applied, err = uow.UpdateIf(ctx, byID, identifier.New().Equal("email", "paid@example.com"), bson.M{"email": "other@example.com"})
+	require.NoError(t, err)
+	assert.True(t, applied)
+}
+
+func TestUnitOfWork_UpdateFields_Immutable(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, &Config{EnableStats: true})
+	byID := identifier.New().Equal("_id", primitive.NewObjectID())
+
+	for _, field := range []string{"_id", "createdAt"} {
+		_, err := uow.UpdateFields(context.Background(), byID, bson.M{field: "x", "age": 1})
+		assert.ErrorIs(t, err, uowerrors.ErrInvalidQuery, field)
+	}
+	_, err := uow.UpdateMany(context.Background(), byID, bson.M{"createdAt": time.Now()})
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQuery)
+	assert.Zero(t, uow.Stats().Total(), "rejected updates must not reach the database")
+}
+
+func TestUnitOfWork_SetFields(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+	fields := bson.M{"age": 0}
+
+	set, err := uow.setFields(fields)
+	require.NoError(t, err)
+	assert.Equal(t, 0, set["age"])
+	assert.IsType(t, time.Time{}, set["updatedAt"])
+	assert.NotContains(t, fields, "updatedAt", "the caller's map is not modified")
+}
+
+func TestUnitOfWork_UpdateFields_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	inserted, err := uow.Insert(ctx, &TestUser{Email: "partial@example.com", Age: 40, Active: true})
+	require.NoError(t, err)
+	byID := identifier.New().Equal("_id", inserted.GetID())
+
+	updated, err := uow.UpdateFields(ctx, byID, bson.M{"email": "changed@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "changed@example.com", updated.Email)
+	assert.Equal(t, 40, updated.Age, "fields left out of the map are untouched")
+	assert.True(t, updated.Active)
+	assert.Equal(t, inserted.CreatedAt.Truncate(time.Millisecond), updated.CreatedAt)
+	assert.False(t, updated.UpdatedAt.Before(inserted.UpdatedAt.Truncate(time.Millisecond)))
+
+	updated, err = uow.UpdateFields(ctx, byID, bson.M{"active": false})
+	require.NoError(t, err)
+	assert.False(t, updated.Active, "zero values in the map are applied")
+	assert.Equal(t, 40, updated.Age)
+
+	_, err = uow.UpdateFields(ctx, identifier.New().Equal("_id", primitive.NewObjectID()), bson.M{"age": 1})
+	assert.Error(t, err)
+}
+
+func TestUnitOfWork_Count_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	users, err := uow.BulkInsert(ctx, []*TestUser{
+		{Email: "a@example.com", Age: 20, Active: true},
+		{Email: "b@example.com", Age: 30, Active: false},
+		{Email: "c@example.com", Age: 40, Active: true},
+	})
+	require.NoError(t, err)
+	_, err = uow.SoftDelete(ctx, identifier.New().Equal("_id", users[0].GetID()))
+	require.NoError(t, err)
+
+	live, err := uow.Count(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), live, "soft-deleted documents are excluded")
+
+	active, err := uow.Count(ctx, identifier.New().Equal("active", true))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), active)
+
+	trashed, err := uow.Count(ctx, identifier.New().IsNotNull("deletedAt"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), trashed, "filtering on deletedAt reaches soft-deleted documents")
+
+	none, err := uow.Count(ctx, identifier.New().GreaterThan("age", 100))
+	require.NoError(t, err)
+	assert.Zero(t, none)
+}
+
+func TestUnitOfWork_Exists_NilIdentifier(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+
+	assert.NotPanics(t, func() {
+		_, err := uow.Exists(context.Background(), nil)
+		assert.ErrorContains(t, err, "failed to check existence")
+	})
+}
+
+func TestUnitOfWork_Exists_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	user, err := uow.Insert(ctx, &TestUser{Email: "exists@example.com", Age: 30})
+	require.NoError(t, err)
+	byEmail := identifier.New().Equal("email", "exists@example.com")
+
+	exists, err := uow.Exists(ctx, byEmail)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = uow.Exists(ctx, nil)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = uow.Exists(ctx, identifier.New().Equal("email", "missing@example.com"))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = uow.SoftDelete(ctx, identifier.New().Equal("_id", user.GetID()))
+	require.NoError(t, err)
+
+	exists, err = uow.Exists(ctx, byEmail)
+	require.NoError(t, err)
+	assert.False(t, exists, "soft-deleted documents do not exist")
+
+	exists, err = uow.Exists(ctx, nil)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	exists, err = uow.Exists(ctx, identifier.New().Equal("email", "exists@example.com").IsNotNull("deletedAt"))
+	require.NoError(t, err)
+	assert.True(t, exists, "filtering on deletedAt reaches soft-deleted documents")
+}
+
+func TestUnitOfWork_Distinct_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*persistence.Product](t)
+	ctx := context.Background()
+
+	products, err := uow.BulkInsert(ctx, []*persistence.Product{
+		{BaseEntity: domain.BaseEntity{Name: "Laptop"}, Category: "electronics", InStock: true},
+		{BaseEntity: domain.BaseEntity{Name: "Phone"}, Category: "electronics", InStock: false},
+		{BaseEntity: domain.BaseEntity{Name: "Desk"}, Category: "furniture", InStock: true},
+		{BaseEntity: domain.BaseEntity{Name: "Hammer"}, Category: "tools", InStock: true},
+	})
+	require.NoError(t, err)
+	_, err = uow.SoftDelete(ctx, identifier.New().Equal("_id", products[3].GetID()))
+	require.NoError(t, err)
+
+	categories, err := uow.Distinct(ctx, "category", nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []interface{}{"electronics", "furniture"}, categories, "values are unique and soft-deleted documents are skipped")
+
+	categories, err = uow.Distinct(ctx, "category", identifier.New().Equal("inStock", false))
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"electronics"}, categories)
+
+	categories, err = uow.Distinct(ctx, "category", identifier.New().IsNotNull("deletedAt"))
+	require.NoError(t, err)
+	assert.Empty(t, categories, "soft-deleted documents are excluded even when the filter asks for them")
+}
+
+func TestUnitOfWork_DistinctWithCounts_InvalidField(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, &Config{EnableStats: true})
+
+	for _, field := range []string{"", "$email"} {
+		_, err := uow.DistinctWithCounts(context.Background(), field, nil)
+		assert.ErrorIs(t, err, uowerrors.ErrInvalidQuery)
+	}
+	assert.Zero(t, uow.Stats().Total())
+}
+
+func TestValueCountsPipeline(t *testing.T) {
+	pipeline := valueCountsPipeline("category", bson.M{"deletedAt": nil})
+
+	require.Len(t, pipeline, 5)
+	assert.Equal(t, bson.D{{Key: "$match", Value: bson.M{"deletedAt": nil}}}, pipeline[0])
+	assert.Equal(t, bson.D{{Key: "$unwind", Value: "$category"}}, pipeline[1])
+	assert.Equal(t, "$group", pipeline[2][0].Key)
+}
+
+func TestUnitOfWork_DistinctWithCounts_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*persistence.Product](t)
+	ctx := context.Background()
+
+	products, err := uow.BulkInsert(ctx, []*persistence.Product{
+		{BaseEntity: domain.BaseEntity{Name: "Laptop"}, Category: "electronics", InStock: true},
+		{BaseEntity: domain.BaseEntity{Name: "Phone"}, Category: "electronics", InStock: false},
+		{BaseEntity: domain.BaseEntity{Name: "Tablet"}, Category: "electronics", InStock: true},
+		{BaseEntity: domain.BaseEntity{Name: "Desk"}, Category: "furniture", InStock: true},
+		{BaseEntity: domain.BaseEntity{Name: "Chair"}, Category: "furniture", InStock: true},
+		{BaseEntity: domain.BaseEntity{Name: "Lamp"}, Category: "lighting", InStock: false},
+		{BaseEntity: domain.BaseEntity{Name: "Hammer"}, Category: "tools", InStock: true},
+	})
+	require.NoError(t, err)
+	_, err = uow.SoftDelete(ctx, identifier.New().Equal("_id", products[6].GetID()))
+	require.NoError(t, err)
+
+	counts, err := uow.DistinctWithCounts(ctx, "category", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []persistence.ValueCount{
+		{Value: "electronics", Count: 3},
+		{Value: "furniture", Count: 2},
+		{Value: "lighting", Count: 1},
+	}, counts)
+
+	counts, err = uow.DistinctWithCounts(ctx, "category", identifier.New().Equal("inStock", true))
+	require.NoError(t, err)
+	assert.Equal(t, []persistence.ValueCount{
+		{Value: "electronics", Count: 2},
+		{Value: "furniture", Count: 2},
+	}, counts)
+
+	counts, err = uow.DistinctWithCounts(ctx, "category", identifier.New().Equal("category", "garden"))
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+}
+
+func TestUnitOfWork_LivePipeline(t *testing.T) {
+	group := bson.D{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$active"}}}}
+
+	users := newOfflineUnitOfWork[*TestUser](t, nil)
+	assert.Equal(t, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"deletedAt": nil}}},
+		group,
+	}, users.livePipeline(mongo.Pipeline{group}))
+
+	users.trashMode = true
+	assert.Equal(t, mongo.Pipeline{group}, users.livePipeline(mongo.Pipeline{group}), "trash mode keeps deleted documents out of the collection")
+}
+
+func TestUnitOfWork_Aggregate_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	users, err := uow.BulkInsert(ctx, []*TestUser{
+		{Email: "a@example.com", Age: 20, Active: true},
+		{Email: "b@example.com", Age: 30, Active: true},
+		{Email: "c@example.com", Age: 40, Active: false},
+		{Email: "d@example.com", Age: 90, Active: false},
+	})
+	require.NoError(t, err)
+	_, err = uow.SoftDelete(ctx, identifier.New().Equal("_id", users[3].GetID()))
+	require.NoError(t, err)
+
+	var groups []struct {
+		Active     bool    `bson:"_id"`
+		Count      int     `bson:"count"`
+		AverageAge float64 `bson:"averageAge"`
+	}
+	err = uow.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$active"},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "averageAge", Value: bson.D{{Key: "$avg", Value: "$age"}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}, &groups)
+	require.NoError(t, err)
+
+	require.Len(t, groups, 2)
+	assert.False(t, groups[0].Active)
+	assert.Equal(t, 1, groups[0].Count, "soft-deleted documents are not aggregated")
+	assert.Equal(t, 40.0, groups[0].AverageAge)
+	assert.Equal(t, 2, groups[1].Count)
+	assert.Equal(t, 25.0, groups[1].AverageAge)
+}
+
+func TestUnitOfWork_FindOneByHexId(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, &Config{EnableStats: true})
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		hexID string
+	}{
+		{name: "empty", hexID: ""},
+		{name: "too short", hexID: "507f1f77bcf86cd79943901"},
+		{name: "too long", hexID: "507f1f77bcf86cd7994390111"},
+		{name: "non-hex", hexID: "507f1f77bcf86cd79943901z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := uow.FindOneByHexId(ctx, tt.hexID)
+			assert.ErrorIs(t, err, uowerrors.ErrInvalidID)
+			assert.Nil(t, user)
+		})
+	}
+	assert.Zero(t, uow.Stats().Total(), "malformed IDs must not reach the database")
+
+	t.Run("valid", func(t *testing.T) {
+		_, err := uow.FindOneByHexId(ctx, "507f1f77bcf86cd799439011")
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, uowerrors.ErrInvalidID)
+		assert.Equal(t, int64(1), uow.Stats().Finds)
+	})
+}
+
+func TestUnitOfWork_FindOneByHexId_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	created, err := uow.Insert(ctx, &TestUser{Email: "hex@example.com"})
+	require.NoError(t, err)
+
+	found, err := uow.FindOneByHexId(ctx, created.GetID().Hex())
+	require.NoError(t, err)
+	assert.Equal(t, "hex@example.com", found.Email)
+}
+
+func TestUnitOfWork_UpdateReturningBefore_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	created, err := uow.Insert(ctx, &TestUser{Email: "old@example.com", Age: 30})
+	require.NoError(t, err)
+	byID := identifier.New().Equal("_id", created.GetID())
+
+	created.Email = "new@example.com"
+	created.Age = 31
+
+	before, err := uow.UpdateReturningBefore(ctx, byID, created)
+	require.NoError(t, err)
+	assert.Equal(t, "old@example.com", before.Email)
+	assert.Equal(t, 30, before.Age)
+
+	after, err := uow.FindOneById(ctx, created.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, "new@example.com", after.Email)
+	assert.Equal(t, 31, after.Age)
+}
+
+func TestUnitOfWork_UpdateModified_Error(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+
+	_, modified, err := uow.UpdateModified(context.Background(), identifier.New().Equal("email", "a@example.com"), &TestUser{})
+	assert.Error(t, err)
+	assert.False(t, modified)
+}
+
+func TestUnitOfWork_UpdateModified_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	created, err := uow.Insert(ctx, &TestUser{Email: "same@example.com", Age: 30})
+	require.NoError(t, err)
+	byID := identifier.New().Equal("_id", created.GetID())
+
+	updated, modified, err := uow.UpdateModified(ctx, byID, &TestUser{Email: "same@example.com", Age: 30})
+	require.NoError(t, err)
+	assert.False(t, modified, "identical values are a no-op")
+	assert.Equal(t, "same@example.com", updated.Email)
+
+	updated, modified, err = uow.UpdateModified(ctx, byID, &TestUser{Email: "same@example.com", Age: 31})
+	require.NoError(t, err)
+	assert.True(t, modified)
+	assert.Equal(t, 31, updated.Age)
+
+	_, modified, err = uow.UpdateModified(ctx, identifier.New().Equal("email", "missing@example.com"), &TestUser{Age: 1})
+	assert.Error(t, err)
+	assert.False(t, modified)
+}
+
+func TestFactory_WithStrictSort(t *testing.T) {
+	factory, err := NewFactory[*TestUser](NewConfig(), WithStrictSort())
+	require.NoError(t, err)
+
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+	factory.apply(uow)
+	assert.True(t, uow.strictSort)
+	assert.True(t, uow.view().strictSort)
+}
+
+func TestUnitOfWork_StrictSort(t *testing.T) {
+	ctx := context.Background()
+	unsorted := domain.QueryParams[*TestUser]{Limit: 10}
+
+	strict := newOfflineUnitOfWork[*TestUser](t, nil)
+	strict.strictSort = true
+
+	_, _, err := strict.FindAllWithPagination(ctx, unsorted)
+	assert.ErrorIs(t, err, uowerrors.ErrSortRequired)
+	_, _, err = strict.GetTrashedWithPagination(ctx, unsorted)
+	assert.ErrorIs(t, err, uowerrors.ErrSortRequired)
+
+	for _, sorted := range []domain.QueryParams[*TestUser]{
+		{Sort: domain.SortMap{"email": domain.SortAsc}},
+		{ArraySort: []domain.ArraySort{{Field: "tags", Direction: domain.SortAsc, Mode: domain.ArraySortMin}}},
+	} {
+		_, _, err = strict.FindAllWithPagination(ctx, sorted)
+		assert.Error(t, err, "offline unit of work cannot reach the server")
+		assert.NotErrorIs(t, err, uowerrors.ErrSortRequired)
+	}
+
+	lenient := newOfflineUnitOfWork[*TestUser](t, nil)
+	_, _, err = lenient.FindAllWithPagination(ctx, unsorted)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, uowerrors.ErrSortRequired)
+}
+
+func TestUnitOfWork_CursorsInTransaction_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	// More than the default first batch of 101, so reads need getMore
+	users := make([]*TestUser, 150)
+	for i := range users {
+		users[i] = &TestUser{Email: fmt.Sprintf("user%03d@example.com", i)}
+	}
+	_, err := uow.BulkInsert(ctx, users)
+	require.NoError(t, err)
+	ids := make([]identifier.IIdentifier, 120)
+	for i := range ids {
+		ids[i] = identifier.New().Equal("_id", users[i].GetID())
+	}
+	_, err = uow.BulkSoftDelete(ctx, ids)
+	require.NoError(t, err)
+
+	if err := uow.BeginTransaction(ctx); err != nil {
+		t.Skipf("Transactions require a replica set: %v", err)
+	}
+	defer uow.RollbackTransaction(ctx)
+
+	live, err := uow.FindAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, live, 30)
+
+	trashed, err := uow.GetTrashed(ctx)
+	require.NoError(t, err)
+	assert.Len(t, trashed, 120)
+
+	page, total, err := uow.FindAllWithPagination(ctx, domain.QueryParams[*TestUser]{Trash: domain.All})
+	require.NoError(t, err)
+	assert.Len(t, page, 150)
+	assert.Equal(t, uint(150), total)
+
+	trashedPage, total, err := uow.GetTrashedWithPagination(ctx, domain.QueryParams[*TestUser]{})
+	require.NoError(t, err)
+	assert.Len(t, trashedPage, 120)
+	assert.Equal(t, uint(120), total)
+
+	require.NoError(t, uow.CommitTransaction(ctx))
+}
+
+func TestUnitOfWork_UsesEstimatedCount(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+
+	tests := []struct {
+		name  string
+		query domain.QueryParams[*TestUser]
+		want  bool
+	}{
+		{"exact by default", domain.QueryParams[*TestUser]{}, false},
+		{"exact", domain.QueryParams[*TestUser]{Count: domain.CountExact}, false},
+		{"estimated live only", domain.QueryParams[*TestUser]{Count: domain.CountEstimated}, false},
+		{"estimated across trash", domain.QueryParams[*TestUser]{Count: domain.CountEstimated, Trash: domain.All}, true},
+		{"estimated with filter", domain.QueryParams[*TestUser]{Count: domain.CountEstimated, Filter: &TestUser{Email: "a@example.com"}}, false},
+		{"estimated trashed only", domain.QueryParams[*TestUser]{Count: domain.CountEstimated, Trash: domain.TrashedOnly}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, filter, err := uow.pageScope(tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, uow.usesEstimatedCount(tt.query, filter))
+		})
+	}
+
+	estimated := domain.QueryParams[*TestUser]{Count: domain.CountEstimated}
+	for _, mode := range []struct {
+		name   string
+		adjust func(*UnitOfWork[*TestUser])
+	}{
+		{"soft delete disabled", func(uow *UnitOfWork[*TestUser]) { uow.softDelete = false }},
+		{"trash collection", func(uow *UnitOfWork[*TestUser]) { uow.trashMode = true }},
+	} {
+		t.Run(mode.name, func(t *testing.T) {
+			scoped := uow.view()
+			mode.adjust(scoped)
+			_, filter, err := scoped.pageScope(estimated)
+			require.NoError(t, err)
+			assert.True(t, scoped.usesEstimatedCount(estimated, filter), "no filter is left to apply")
+		})
+	}
+
+	uow.inTx = true
+	defer func() { uow.inTx = false }()
+	assert.False(t, uow.usesEstimatedCount(estimated, bson.M{}))
+}
+
+func TestUnitOfWork_UnknownCountModeRejected(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, &Config{EnableStats: true})
+
+	_, _, err := uow.FindAllWithPagination(context.Background(), domain.QueryParams[*TestUser]{Count: "approximate"})
+	assert.ErrorIs(t, err, uowerrors.ErrInvalidQueryParams)
+	assert.Zero(t, uow.Stats().Total())
+}
+
+func TestUnitOfWork_EstimatedCount_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	_, err := uow.BulkInsert(ctx, []*TestUser{
+		{Email: "a@example.com", Active: true},
+		{Email: "b@example.com", Active: true},
+		{Email: "c@example.com"},
+	})
+	require.NoError(t, err)
+	_, err = uow.SoftDelete(ctx, identifier.New().Equal("email", "c@example.com"))
+	require.NoError(t, err)
+
+	_, total, err := uow.FindAllWithPagination(ctx, domain.QueryParams[*TestUser]{Count: domain.CountEstimated, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), total, "soft-deleted documents are not counted")
+
+	_, total, err = uow.FindAllWithPagination(ctx, domain.QueryParams[*TestUser]{Count: domain.CountEstimated, Trash: domain.All, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), total)
+
+	_, total, err = uow.FindAllWithPagination(ctx, domain.QueryParams[*TestUser]{
+		Count:  domain.CountEstimated,
+		Filter: &TestUser{Email: "a@example.com"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), total)
+}
+
+func TestAlignByID(t *testing.T) {
+	a, b, c := &TestUser{Email: "a"}, &TestUser{Email: "b"}, &TestUser{Email: "c"}
+	for _, user := range []*TestUser{a, b, c} {
+		user.SetID(primitive.NewObjectID())
+	}
+	missing := primitive.NewObjectID()
+
+	aligned := alignByID([]primitive.ObjectID{c.GetID(), missing, a.GetID(), c.GetID()}, []*TestUser{a, b, c})
+	assert.Equal(t, []*TestUser{c, nil, a, c}, aligned)
+
+	assert.Empty(t, alignByID(nil, []*TestUser{a}))
+}
+
+func TestUnitOfWork_FindByIdsOrdered_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	users, err := uow.BulkInsert(ctx, []*TestUser{{Email: "a"}, {Email: "b"}, {Email: "c"}})
+	require.NoError(t, err)
+	_, err = uow.SoftDelete(ctx, identifier.New().Equal("_id", users[1].GetID()))
+	require.NoError(t, err)
+	missing := primitive.NewObjectID()
+
+	found, err := uow.FindByIdsOrdered(ctx, []primitive.ObjectID{users[2].GetID(), missing, users[1].GetID(), users[0].GetID()})
+	require.NoError(t, err)
+	require.Len(t, found, 4)
+	assert.Equal(t, "c", found[0].Email)
+	assert.Nil(t, found[1], "missing IDs leave a nil")
+	assert.Nil(t, found[2], "soft-deleted entities are absent")
+	assert.Equal(t, "a", found[3].Email)
+}
+
+type TestLineItem struct {
+	SKU string `bson:"sku" json:"sku"`
+	Qty int    `bson:"qty" json:"qty"`
+}
+
+type TestOrder struct {
+	domain.BaseEntity `bson:",inline"`
+	Items             []TestLineItem `bson:"items" json:"items"`
+}
+
+func TestUnitOfWork_OperatorUpdate(t *testing.T) {
+	orders := newOfflineUnitOfWork[*TestOrder](t, nil)
+
+	update, err := orders.operatorUpdate(bson.M{
+		"$set": bson.M{"items.$[item].qty": 2},
+		"$inc": bson.M{"items.$[other].qty": 1},
+	})
+	require.NoError(t, err)
+	set := update["$set"].(bson.M)
+	assert.Equal(t, 2, set["items.$[item].qty"])
+	assert.Contains(t, set, "updatedAt")
+	assert.Equal(t, bson.M{"items.$[other].qty": 1}, update["$inc"])
+
+	update, err = orders.operatorUpdate(bson.M{"$pull": bson.M{"items": bson.M{"qty": 0}}})
+	require.NoError(t, err)
+	assert.Contains(t, update["$set"], "updatedAt", "updatedAt is refreshed without an explicit $set")
+
+	for _, invalid := range []bson.M{
+		nil,
+		{"items.0.qty": 2},
+		{"$set": bson.D{{Key: "qty", Value: 2}}},
+		{"$set": bson.M{"createdAt": time.Now()}},
+	} {
+		_, err := orders.operatorUpdate(invalid)
+		assert.ErrorIs(t, err, uowerrors.ErrInvalidQuery, "%v", invalid)
+	}
+}
+
+func TestUnitOfWork_UpdateWithArrayFilters_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestOrder](t)
+	ctx := context.Background()
+
+	order, err := uow.Insert(ctx, &TestOrder{Items: []TestLineItem{{SKU: "A1", Qty: 1}, {SKU: "B2", Qty: 5}, {SKU: "C3", Qty: 7}}})
+	require.NoError(t, err)
+
+	updated, err := uow.UpdateWithArrayFilters(ctx,
+		identifier.New().Equal("_id", order.GetID()),
+		bson.M{"$set": bson.M{"items.$[item].qty": 3}},
+		[]interface{}{bson.M{"item.sku": "B2"}},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []TestLineItem{{SKU: "A1", Qty: 1}, {SKU: "B2", Qty: 3}, {SKU: "C3", Qty: 7}}, updated.Items)
+	assert.False(t, updated.UpdatedAt.Before(order.UpdatedAt))
+
+	_, err = uow.UpdateWithArrayFilters(ctx,
+		identifier.New().Equal("_id", primitive.NewObjectID()),
+		bson.M{"$set": bson.M{"items.$[item].qty": 3}},
+		[]interface{}{bson.M{"item.sku": "B2"}},
+	)
+	assert.ErrorContains(t, err, "entity not found")
+}
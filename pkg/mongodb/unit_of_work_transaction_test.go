@@ -0,0 +1,318 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+)
+
+func TestTransactionOptions(t *testing.T) {
+	assert.Nil(t, transactionOptions(NewConfig()).MaxCommitTime)
+
+	config := NewConfig()
+	config.MaxCommitTime = 5 * time.Second
+	opts := transactionOptions(config)
+	require.NotNil(t, opts.MaxCommitTime)
+	assert.Equal(t, 5*time.Second, *opts.MaxCommitTime)
+}
+
+func TestIsTransactionExpired(t *testing.T) {
+	assert.True(t, isTransactionExpired(mongo.CommandError{Code: 50, Name: "MaxTimeMSExpired"}))
+	assert.True(t, isTransactionExpired(fmt.Errorf("commit: %w", mongo.CommandError{Code: 290})))
+	assert.False(t, isTransactionExpired(mongo.CommandError{Code: 251, Name: "NoSuchTransaction"}), "an aborted transaction has not necessarily expired")
+	assert.False(t, isTransactionExpired(mongo.CommandError{Code: 11000}))
+	assert.False(t, isTransactionExpired(fmt.Errorf("boom")))
+	assert.False(t, isTransactionExpired(nil))
+}
+
+func TestErrTransactionExpired_IsTransaction(t *testing.T) {
+	err := fmt.Errorf("%w: %w", uowerrors.ErrTransactionExpired, mongo.CommandError{Code: 50, Name: "MaxTimeMSExpired"})
+	assert.True(t, uowerrors.IsTransaction(err))
+
+	var commandErr mongo.CommandError
+	assert.ErrorAs(t, err, &commandErr, "the driver error stays reachable")
+}
+
+func TestUnitOfWork_TransactionExpired_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+	admin := uow.client.Database("admin")
+
+	config := NewConfig()
+	config.MaxCommitTime = 500 * time.Millisecond
+	uow.txOptions = transactionOptions(config)
+
+	setLifetime := func(seconds int) error {
+		return admin.RunCommand(ctx, bson.D{
+			{Key: "setParameter", Value: 1},
+			{Key: "transactionLifetimeLimitSeconds", Value: seconds},
+		}).Err()
+	}
+	if err := setLifetime(1); err != nil {
+		t.Skipf("Lowering the transaction lifetime requires admin rights: %v", err)
+	}
+	defer func() { _ = setLifetime(60) }()
+
+	if err := uow.BeginTransaction(ctx); err != nil {
+		t.Skipf("Transactions require a replica set: %v", err)
+	}
+	_, err := uow.Insert(ctx, &TestUser{Email: "expired@example.com"})
+	require.NoError(t, err)
+
+	time.Sleep(3 * time.Second)
+
+	err = uow.CommitTransaction(ctx)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, uowerrors.ErrTransactionExpired)
+	uow.RollbackTransaction(ctx)
+}
+
+func TestHelloSupportsTransactions(t *testing.T) {
+	assert.True(t, helloSupportsTransactions(bson.M{"isWritablePrimary": true, "setName": "rs0"}))
+	assert.True(t, helloSupportsTransactions(bson.M{"isWritablePrimary": true, "msg": "isdbgrid"}))
+	assert.False(t, helloSupportsTransactions(bson.M{"isWritablePrimary": true}))
+}
+
+func TestIsTransactionsUnsupported(t *testing.T) {
+	standalone := mongo.CommandError{
+		Code:    20,
+		Name:    "IllegalOperation",
+		Message: "Transaction numbers are only allowed on a replica set member or mongos",
+	}
+	assert.True(t, isTransactionsUnsupported(standalone))
+	assert.ErrorIs(t, transactionsUnsupported(fmt.Errorf("insert: %w", standalone)), uowerrors.ErrTransactionsUnsupported)
+
+	other := mongo.CommandError{Code: 20, Message: "cannot drop a view"}
+	assert.False(t, isTransactionsUnsupported(other))
+	assert.Equal(t, error(other), transactionsUnsupported(other))
+	assert.True(t, uowerrors.IsTransaction(uowerrors.ErrTransactionsUnsupported))
+}
+
+func TestFactory_WithTransactionFallback(t *testing.T) {
+	factory, err := NewFactory[*TestUser](NewConfig(), WithTransactionFallback())
+	require.NoError(t, err)
+
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+	factory.apply(uow)
+	assert.True(t, uow.txFallback)
+	assert.True(t, uow.view().txFallback)
+}
+
+// standaloneUnitOfWork skips unless the integration server is a standalone,
+// which is the only deployment where transactions are unavailable
+func standaloneUnitOfWork(t *testing.T, opts ...FactoryOption) *UnitOfWork[*TestUser] {
+	t.Helper()
+	uow := newIntegrationUnitOfWork[*TestUser](t, opts...)
+	supported, err := uow.transactionsSupported(context.Background())
+	require.NoError(t, err)
+	if supported {
+		t.Skip("Requires a standalone MongoDB server")
+	}
+	return uow
+}
+
+func TestUnitOfWork_TransactionsUnsupported_Integration(t *testing.T) {
+	uow := standaloneUnitOfWork(t)
+
+	err := uow.BeginTransaction(context.Background())
+	assert.ErrorIs(t, err, uowerrors.ErrTransactionsUnsupported)
+	assert.False(t, uow.inTx)
+}
+
+func TestUnitOfWork_TransactionFallback_Integration(t *testing.T) {
+	uow := standaloneUnitOfWork(t, WithTransactionFallback())
+	ctx := context.Background()
+
+	require.NoError(t, uow.BeginTransaction(ctx))
+	assert.False(t, uow.inTx)
+	assert.Error(t, uow.BeginTransaction(ctx), "a fallback transaction is still open")
+
+	_, err := uow.Insert(ctx, &TestUser{Email: "fallback@example.com"})
+	require.NoError(t, err)
+	require.NoError(t, uow.CommitTransaction(ctx))
+
+	users, err := uow.FindAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, users, 1)
+
+	require.NoError(t, uow.BeginTransaction(ctx))
+	_, err = uow.Insert(ctx, &TestUser{Email: "kept@example.com"})
+	require.NoError(t, err)
+	uow.RollbackTransaction(ctx)
+
+	users, err = uow.FindAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, users, 2, "writes made without a transaction cannot be rolled back")
+}
+
+func TestUnitOfWork_Session(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+
+	session, ok := uow.Session()
+	assert.False(t, ok)
+	assert.Nil(t, session)
+
+	started, err := uow.client.StartSession()
+	require.NoError(t, err)
+	defer started.EndSession(context.Background())
+	uow.session = started
+	uow.inTx = true
+
+	session, ok = uow.Session()
+	assert.True(t, ok)
+	assert.Same(t, started, session)
+
+	uow.inTx = false
+	uow.inFallbackTx = true
+	session, ok = uow.Session()
+	assert.False(t, ok, "a fallback transaction has no session")
+	assert.Nil(t, session)
+}
+
+func TestUnitOfWork_Session_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t)
+	ctx := context.Background()
+
+	if err := uow.BeginTransaction(ctx); err != nil {
+		t.Skipf("Transactions require a replica set: %v", err)
+	}
+	session, ok := uow.Session()
+	require.True(t, ok)
+	assert.NotNil(t, session.ID())
+
+	require.NoError(t, uow.CommitTransaction(ctx))
+	session, ok = uow.Session()
+	assert.False(t, ok)
+	assert.Nil(t, session)
+}
+
+// Transactions start lazily, so an attempt that touches no collection runs
+// against the offline client and exercises the driver retry loop.
+func TestUnitOfWork_RunInTransaction_RetriesTransientError(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+
+	attempts := 0
+	err := uow.RunInTransaction(context.Background(), func(ctx context.Context) error {
+		attempts++
+		if _, ok := ctx.(mongo.SessionContext); !ok {
+			t.Error("fn should receive the session context")
+		}
+		if attempts == 1 {
+			return mongo.CommandError{Code: 112, Name: "WriteConflict", Labels: []string{"TransientTransactionError"}}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+}
+
+// commitsSent counts the commitTransaction commands the mock client received
+func commitsSent(mt *mtest.T) int {
+	commits := 0
+	for _, started := range mt.GetAllStartedEvents() {
+		if started.CommandName == "commitTransaction" {
+			commits++
+		}
+	}
+	return commits
+}
+
+// The mock deployment answers each command with the next queued response, so
+// a commit can fail with a transient label without a server.
+func TestUnitOfWork_RunInTransaction_RetriesCommit(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("unknown commit result retries the commit", func(mt *mtest.T) {
+		uow := newUnitOfWork[*TestUser](NewConfig(), mt.Client)
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateCommandErrorResponse(mtest.CommandError{
+				Code: 91, Name: "ShutdownInProgress", Message: "shutting down",
+				Labels: []string{"UnknownTransactionCommitResult"},
+			}),
+			mtest.CreateSuccessResponse(),
+		)
+
+		attempts := 0
+		err := uow.RunInTransaction(context.Background(), func(ctx context.Context) error {
+			attempts++
+			_, err := uow.Insert(ctx, &TestUser{Email: "a@example.com"})
+			return err
+		})
+		require.NoError(mt, err)
+		assert.Equal(mt, 1, attempts)
+		assert.Equal(mt, 2, commitsSent(mt))
+	})
+
+	mt.Run("transient commit error retries the transaction", func(mt *mtest.T) {
+		uow := newUnitOfWork[*TestUser](NewConfig(), mt.Client)
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateCommandErrorResponse(mtest.CommandError{
+				Code: 112, Name: "WriteConflict", Message: "write conflict",
+				Labels: []string{"TransientTransactionError"},
+			}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateSuccessResponse(),
+		)
+
+		attempts := 0
+		err := uow.RunInTransaction(context.Background(), func(ctx context.Context) error {
+			attempts++
+			_, err := uow.Insert(ctx, &TestUser{Email: "a@example.com"})
+			return err
+		})
+		require.NoError(mt, err)
+		assert.Equal(mt, 2, attempts)
+		assert.Equal(mt, 2, commitsSent(mt))
+	})
+}
+
+func TestUnitOfWork_RunInTransaction_ReturnsPermanentError(t *testing.T) {
+	uow := newOfflineUnitOfWork[*TestUser](t, nil)
+	errBoom := fmt.Errorf("boom")
+
+	attempts := 0
+	err := uow.RunInTransaction(context.Background(), func(context.Context) error {
+		attempts++
+		return errBoom
+	})
+	assert.ErrorIs(t, err, errBoom)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestUnitOfWork_TransactionHonorsCallerCancellation(t *testing.T) {
+	client, err := mongo.Connect(context.Background(), options.Client().
+		ApplyURI("mongodb://127.0.0.1:1").
+		SetServerSelectionTimeout(5*time.Second))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Disconnect(context.Background()) })
+
+	uow := newUnitOfWork[*TestUser](NewConfig(), client)
+	session, err := client.StartSession()
+	require.NoError(t, err)
+	defer session.EndSession(context.Background())
+	require.NoError(t, session.StartTransaction())
+	uow.session = session
+	uow.ctx = mongo.NewSessionContext(context.Background(), session)
+	uow.inTx = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err = uow.FindAll(ctx)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,312 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+)
+
+// trashSuffix names the collection trashed documents are moved to
+const trashSuffix = "_trash"
+
+// WithTrashCollection makes soft deletes move documents to a separate
+// "<collection>_trash" collection instead of setting deletedAt in place, so
+// reads on the main collection need no soft-delete filter. Moves run in a
+// transaction, which requires a replica set or sharded cluster.
+func WithTrashCollection() FactoryOption {
+	return func(s *factorySettings) {
+		s.trashMode = true
+	}
+}
+
+// filtersDeleted reports whether reads must exclude soft-deleted documents
+// from the main collection
+func (uow *UnitOfWork[T]) filtersDeleted() bool {
+	return uow.softDelete && !uow.trashMode
+}
+
+func (uow *UnitOfWork[T]) getTrashCollection() *mongo.Collection {
+	return uow.database.Collection(uow.collectionName + trashSuffix)
+}
+
+// trashedCollection is where soft-deleted documents live in the current mode
+func (uow *UnitOfWork[T]) trashedCollection() *mongo.Collection {
+	if uow.trashMode {
+		return uow.getTrashCollection()
+	}
+	return uow.getCollection()
+}
+
+// inTransaction runs fn inside the open transaction, or in a new one
+func (uow *UnitOfWork[T]) inTransaction(ctx context.Context, fn func(txCtx context.Context) error) error {
+	if uow.inTx && uow.session != nil {
+		return fn(uow.getContext(ctx))
+	}
+	if uow.inFallbackTx {
+		return fn(ctx)
+	}
+	if uow.txFallback {
+		supported, err := uow.transactionsSupported(ctx)
+		if err != nil {
+			return err
+		}
+		if !supported {
+			return fn(ctx)
+		}
+	}
+
+	session, err := uow.client.StartSession()
+	if err != nil {
+		return fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	}, uow.txOptions)
+	if isTransactionExpired(err) {
+		return fmt.Errorf("%w: %w", uowerrors.ErrTransactionExpired, err)
+	}
+	return transactionsUnsupported(err)
+}
+
+// moveToTrash deletes the document matching filter from the main collection
+// and inserts it, stamped with deletedAt, into the trash collection. It returns
+// the document before and after stamping.
+func (uow *UnitOfWork[T]) moveToTrash(txCtx context.Context, filter bson.M, now time.Time) (before, after bson.Raw, err error) {
+	uow.track(opDelete)
+	before, err = uow.getCollection().FindOneAndDelete(txCtx, filter).Raw()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stamp, err := bson.Marshal(bson.M{"deletedAt": now, "updatedAt": now})
+	if err != nil {
+		return nil, nil, err
+	}
+	after, err = overlayDocument(before, stamp)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	uow.track(opInsert)
+	if _, err := uow.getTrashCollection().InsertOne(txCtx, after); err != nil {
+		return nil, nil, err
+	}
+	return before, after, nil
+}
+
+// moveManyToTrash moves every document matching filter from the main
+// collection to the trash collection, stamped with deletedAt
+func (uow *UnitOfWork[T]) moveManyToTrash(ctx context.Context, filter bson.M) (int64, error) {
+	var count int64
+
+	err := uow.inTransaction(ctx, func(txCtx context.Context) error {
+		collection := uow.getCollection()
+
+		uow.track(opFind)
+		cursor, err := collection.Find(txCtx, filter)
+		if err != nil {
+			return err
+		}
+		var live []bson.Raw
+		if err := cursor.All(txCtx, &live); err != nil {
+			return err
+		}
+		if len(live) == 0 {
+			count = 0
+			return nil
+		}
+
+		now := utcNow()
+		stamp, err := bson.Marshal(bson.M{"deletedAt": now, "updatedAt": now})
+		if err != nil {
+			return err
+		}
+		documents := make([]interface{}, len(live))
+		ids := make(bson.A, len(live))
+		for i, doc := range live {
+			if documents[i], err = overlayDocument(doc, stamp); err != nil {
+				return err
+			}
+			ids[i] = doc.Lookup("_id")
+		}
+
+		uow.track(opInsert)
+		if _, err := uow.getTrashCollection().InsertMany(txCtx, documents); err != nil {
+			return err
+		}
+		uow.track(opDelete)
+		if _, err := collection.DeleteMany(txCtx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+			return err
+		}
+		count = int64(len(live))
+		return nil
+	})
+	return count, err
+}
+
+// restoreDocument strips deletedAt from a trashed document and stamps
+// updatedAt
+func restoreDocument(trashed bson.Raw, now time.Time) (bson.Raw, error) {
+	elements, err := trashed.Elements()
+	if err != nil {
+		return nil, err
+	}
+
+	restored := bson.D{}
+	for _, element := range elements {
+		switch element.Key() {
+		case "deletedAt":
+		case "updatedAt":
+			restored = append(restored, bson.E{Key: "updatedAt", Value: now})
+		default:
+			restored = append(restored, bson.E{Key: element.Key(), Value: element.Value()})
+		}
+	}
+	return bson.Marshal(restored)
+}
+
+func (uow *UnitOfWork[T]) trashOne(ctx context.Context, identifier identifier.IIdentifier, returnBefore bool) (T, error) {
+	var zero T
+	var document bson.Raw
+
+	err := uow.inTransaction(ctx, func(txCtx context.Context) error {
+		before, after, err := uow.moveToTrash(txCtx, identifier.ToBSON(), utcNow())
+		document = after
+		if returnBefore {
+			document = before
+		}
+		return err
+	})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return zero, fmt.Errorf("entity not found")
+		}
+		return zero, fmt.Errorf("failed to soft delete: %w", err)
+	}
+
+	var entity T
+	if err := uow.unmarshal(document, &entity); err != nil {
+		return zero, fmt.Errorf("failed to decode trashed entity: %w", err)
+	}
+	return entity, nil
+}
+
+func (uow *UnitOfWork[T]) bulkTrash(ctx context.Context, identifiers []identifier.IIdentifier) (persistence.BulkResult, error) {
+	result := persistence.BulkResult{Requested: len(identifiers)}
+	now := utcNow()
+
+	var moved int64
+	err := uow.inTransaction(ctx, func(txCtx context.Context) error {
+		moved = 0
+		for _, id := range identifiers {
+			_, _, err := uow.moveToTrash(txCtx, id.ToBSON(), now)
+			if err == mongo.ErrNoDocuments {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			moved++
+		}
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to bulk soft delete: %w", err)
+	}
+
+	result.Processed = len(identifiers)
+	result.Matched = moved
+	result.Modified = moved
+	return result, nil
+}
+
+func (uow *UnitOfWork[T]) restoreFromTrash(ctx context.Context, identifier identifier.IIdentifier) (T, error) {
+	var zero T
+	var restored bson.Raw
+
+	err := uow.inTransaction(ctx, func(txCtx context.Context) error {
+		uow.track(opDelete)
+		trashed, err := uow.getTrashCollection().FindOneAndDelete(txCtx, identifier.ToBSON()).Raw()
+		if err != nil {
+			return err
+		}
+		if restored, err = restoreDocument(trashed, utcNow()); err != nil {
+			return err
+		}
+
+		uow.track(opInsert)
+		_, err = uow.getCollection().InsertOne(txCtx, restored)
+		return err
+	})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return zero, fmt.Errorf("entity not found in trash")
+		}
+		return zero, fmt.Errorf("failed to restore: %w", err)
+	}
+
+	var entity T
+	if err := uow.unmarshal(restored, &entity); err != nil {
+		return zero, fmt.Errorf("failed to decode restored entity: %w", err)
+	}
+	return entity, nil
+}
+
+func (uow *UnitOfWork[T]) restoreAllFromTrash(ctx context.Context) (int64, error) {
+	return uow.restoreManyFromTrash(ctx, bson.M{})
+}
+
+// restoreManyFromTrash moves every trashed document matching filter back to
+// the main collection
+func (uow *UnitOfWork[T]) restoreManyFromTrash(ctx context.Context, filter bson.M) (int64, error) {
+	var count int64
+
+	err := uow.inTransaction(ctx, func(txCtx context.Context) error {
+		trash := uow.getTrashCollection()
+
+		uow.track(opFind)
+		cursor, err := trash.Find(txCtx, filter)
+		if err != nil {
+			return err
+		}
+		var trashed []bson.Raw
+		if err := cursor.All(txCtx, &trashed); err != nil {
+			return err
+		}
+		if len(trashed) == 0 {
+			count = 0
+			return nil
+		}
+
+		now := utcNow()
+		documents := make([]interface{}, len(trashed))
+		ids := make(bson.A, len(trashed))
+		for i, doc := range trashed {
+			if documents[i], err = restoreDocument(doc, now); err != nil {
+				return err
+			}
+			ids[i] = doc.Lookup("_id")
+		}
+
+		uow.track(opInsert)
+		if _, err := uow.getCollection().InsertMany(txCtx, documents); err != nil {
+			return err
+		}
+		uow.track(opDelete)
+		if _, err := trash.DeleteMany(txCtx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+			return err
+		}
+		count = int64(len(trashed))
+		return nil
+	})
+	return count, err
+}
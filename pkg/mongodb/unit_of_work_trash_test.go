@@ -0,0 +1,122 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+)
+
+func TestFactory_WithTrashCollection(t *testing.T) {
+	factory, err := NewFactory[*TestUser](NewConfig(), WithTrashCollection())
+	require.NoError(t, err)
+
+	users := newOfflineUnitOfWork[*TestUser](t, nil)
+	factory.apply(users)
+	assert.True(t, users.trashMode)
+	assert.Equal(t, "testusers_trash", users.getTrashCollection().Name())
+	assert.Equal(t, bson.M{"email": "a"}, users.excludeDeleted(bson.M{"email": "a"}))
+	assert.True(t, users.view().trashMode)
+
+	ledger := newOfflineUnitOfWork[*LedgerEntry](t, nil)
+	ledgerFactory, err := NewFactory[*LedgerEntry](NewConfig(), WithTrashCollection())
+	require.NoError(t, err)
+	ledgerFactory.apply(ledger)
+	assert.False(t, ledger.trashMode, "entities without soft delete keep hard deleting")
+}
+
+func TestRestoreDocument(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	trashed, err := bson.Marshal(bson.D{
+		{Key: "email", Value: "a@example.com"},
+		{Key: "deletedAt", Value: now.Add(-time.Hour)},
+		{Key: "updatedAt", Value: now.Add(-time.Hour)},
+	})
+	require.NoError(t, err)
+
+	restored, err := restoreDocument(trashed, now)
+	require.NoError(t, err)
+
+	var doc bson.M
+	require.NoError(t, bson.Unmarshal(restored, &doc))
+	assert.NotContains(t, doc, "deletedAt")
+	assert.Equal(t, "a@example.com", doc["email"])
+	assert.Equal(t, now, doc["updatedAt"].(primitive.DateTime).Time().UTC())
+}
+
+func TestUnitOfWork_TrashCollection_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t, WithTrashCollection())
+	ctx := context.Background()
+
+	kept, err := uow.Insert(ctx, &TestUser{Email: "kept@example.com"})
+	require.NoError(t, err)
+	trashed, err := uow.Insert(ctx, &TestUser{Email: "trashed@example.com"})
+	require.NoError(t, err)
+	byID := identifier.New().Equal("_id", trashed.GetID())
+
+	deleted, err := uow.SoftDelete(ctx, byID)
+	if err != nil {
+		t.Skipf("Trash collection mode requires transactions on a replica set: %v", err)
+	}
+	assert.True(t, deleted.IsDeleted())
+
+	hot, err := uow.getCollection().CountDocuments(ctx, bson.M{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), hot)
+
+	live, err := uow.FindAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, live, 1)
+	assert.Equal(t, kept.GetID(), live[0].GetID())
+
+	inTrash, err := uow.GetTrashed(ctx)
+	require.NoError(t, err)
+	require.Len(t, inTrash, 1)
+	assert.Equal(t, "trashed@example.com", inTrash[0].Email)
+
+	found, err := uow.FindOneByIdWithTrashed(ctx, trashed.GetID())
+	require.NoError(t, err)
+	assert.True(t, found.IsDeleted())
+
+	all, err := uow.FindAllWithTrashed(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	restored, err := uow.Restore(ctx, byID)
+	require.NoError(t, err)
+	assert.False(t, restored.IsDeleted())
+
+	inTrash, err = uow.GetTrashed(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, inTrash)
+
+	found, err = uow.FindOneById(ctx, trashed.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, "trashed@example.com", found.Email)
+}
+
+func TestUnitOfWork_TrashCollection_BulkSoftDeleteCount_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*TestUser](t, WithTrashCollection())
+	ctx := context.Background()
+
+	users, err := uow.BulkInsert(ctx, []*TestUser{{Email: "a"}, {Email: "b"}, {Email: "c"}})
+	require.NoError(t, err)
+	ids := []identifier.IIdentifier{identifier.New().Equal("email", "missing")}
+	for _, user := range users {
+		ids = append(ids, identifier.New().Equal("_id", user.GetID()))
+	}
+
+	if _, err := uow.SoftDelete(ctx, ids[1]); err != nil {
+		t.Skipf("Trash collection mode requires transactions on a replica set: %v", err)
+	}
+
+	result, err := uow.BulkSoftDelete(ctx, ids)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result.Affected(), "only live matches are moved to the trash")
+}
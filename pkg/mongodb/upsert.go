@@ -0,0 +1,186 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+)
+
+// Upsert updates the entity matched by identifier with the fields of entity,
+// or inserts it when none matches. _id, createdAt and the fields declared
+// through domain.ImmutableFields go through $setOnInsert, so an update never
+// overwrites them. A soft-deleted match is revived rather than duplicated,
+// since deletedAt is unset unless entity carries one.
+func (uow *UnitOfWork[T]) Upsert(ctx context.Context, identifier identifier.IIdentifier, entity T) (T, error) {
+	if err := checkEntity(entity); err != nil {
+		return entity, err
+	}
+	if err := uow.appendOnly("upsert"); err != nil {
+		return entity, err
+	}
+
+	now := utcNow()
+	uow.setEntityTimestamp(entity, "createdAt", now)
+	uow.setEntityTimestamp(entity, "updatedAt", now)
+	if entity.GetID().IsZero() {
+		entity.SetID(primitive.NewObjectID())
+	}
+
+	normalizeFields(entity)
+	if _, err := uow.stampContentHash(entity); err != nil {
+		return entity, err
+	}
+
+	update, err := uow.upsertUpdate(entity)
+	if err != nil {
+		return entity, fmt.Errorf("failed to upsert: %w", err)
+	}
+
+	uow.track(opUpdate)
+	result := uow.getCollection().FindOneAndUpdate(
+		uow.getContext(ctx),
+		identifier.ToBSON(),
+		update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	)
+
+	var upserted T
+	if err := uow.decode(result, &upserted); err != nil {
+		return entity, fmt.Errorf("failed to upsert: %w", duplicateKey(err))
+	}
+
+	return upserted, nil
+}
+
+// BulkUpsert writes entities by _id in one unordered batch, updating the
+// documents that exist and inserting the rest, with the same $setOnInsert
+// split as Upsert. Soft-deleted documents are revived, as with Upsert. Entities without an ID are given one. The entities are
+// returned as written, so createdAt on those that already existed holds the
+// attempted insert time rather than the stored one.
+func (uow *UnitOfWork[T]) BulkUpsert(ctx context.Context, entities []T) ([]T, error) {
+	if _, err := uow.bulkUpsert(ctx, entities); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (uow *UnitOfWork[T]) bulkUpsert(ctx context.Context, entities []T) (*mongo.BulkWriteResult, error) {
+	if len(entities) == 0 {
+		return &mongo.BulkWriteResult{}, nil
+	}
+	if err := checkEntities(entities); err != nil {
+		return nil, err
+	}
+	if err := uow.appendOnly("upsert"); err != nil {
+		return nil, err
+	}
+
+	now := utcNow()
+	models := make([]mongo.WriteModel, len(entities))
+	for i, entity := range entities {
+		uow.setEntityTimestamp(entity, "createdAt", now)
+		uow.setEntityTimestamp(entity, "updatedAt", now)
+		if entity.GetID().IsZero() {
+			entity.SetID(primitive.NewObjectID())
+		}
+
+		normalizeFields(entity)
+		if _, err := uow.stampContentHash(entity); err != nil {
+			return nil, err
+		}
+
+		update, err := uow.upsertUpdate(entity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to bulk upsert: %w", err)
+		}
+		models[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": entity.GetID()}).
+			SetUpdate(update).
+			SetUpsert(true)
+	}
+
+	result := &mongo.BulkWriteResult{UpsertedIDs: map[int64]interface{}{}}
+	collection := uow.getCollection()
+	bulkOpts := options.BulkWrite().SetOrdered(false)
+	_, err := runInBatches(ctx, len(models), uow.batchOptions(persistence.BulkOptions{}), func(start, end int) error {
+		uow.track(opUpdate)
+		res, err := collection.BulkWrite(uow.getContext(ctx), models[start:end], bulkOpts)
+		if res != nil {
+			result.MatchedCount += res.MatchedCount
+			result.ModifiedCount += res.ModifiedCount
+			result.UpsertedCount += res.UpsertedCount
+			for index, id := range res.UpsertedIDs {
+				result.UpsertedIDs[int64(start)+index] = id
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to bulk upsert: %w", duplicateKey(err))
+	}
+
+	for index, id := range result.UpsertedIDs {
+		if oid, ok := id.(primitive.ObjectID); ok {
+			entities[index].SetID(oid)
+		}
+	}
+	return result, nil
+}
+
+// upsertUpdate encodes entity with the client registry and splits its fields
+// between $set and $setOnInsert, encrypting tagged fields. deletedAt is unset
+// when the entity has none, reviving a soft-deleted match.
+func (uow *UnitOfWork[T]) upsertUpdate(entity T) (bson.M, error) {
+	raw, err := uow.marshalPlain(entity)
+	if err != nil {
+		return nil, err
+	}
+	if uow.encryption != nil {
+		if raw, err = uow.encryption.encryptDocument(raw); err != nil {
+			return nil, err
+		}
+	}
+	elements, err := bson.Raw(raw).Elements()
+	if err != nil {
+		return nil, err
+	}
+
+	insertOnly := append([]string{"_id", "createdAt"}, immutableFields(entity)...)
+
+	set, setOnInsert := bson.D{}, bson.D{}
+	deleted := false
+	for _, element := range elements {
+		deleted = deleted || element.Key() == "deletedAt"
+		field := bson.E{Key: element.Key(), Value: element.Value()}
+		if slices.Contains(insertOnly, element.Key()) {
+			setOnInsert = append(setOnInsert, field)
+		} else {
+			set = append(set, field)
+		}
+	}
+
+	update := bson.M{"$set": set}
+	if len(setOnInsert) > 0 {
+		update["$setOnInsert"] = setOnInsert
+	}
+	if !deleted {
+		update["$unset"] = bson.M{"deletedAt": ""}
+	}
+	return update, nil
+}
+
+func immutableFields(model interface{}) []string {
+	if immutable, ok := modelInstance(model).(domain.ImmutableFields); ok {
+		return immutable.ImmutableFields()
+	}
+	return nil
+}
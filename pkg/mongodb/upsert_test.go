@@ -0,0 +1,199 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+)
+
+type Setting struct {
+	domain.BaseEntity `bson:",inline"`
+	Key               string `bson:"key" json:"key"`
+	Value             string `bson:"value" json:"value"`
+	CreatedBy         string `bson:"createdBy" json:"createdBy"`
+}
+
+func (Setting) ImmutableFields() []string { return []string{"createdBy"} }
+
+func TestImmutableFields(t *testing.T) {
+	assert.Equal(t, []string{"createdBy"}, immutableFields((*Setting)(nil)))
+	assert.Nil(t, immutableFields((*TestUser)(nil)))
+}
+
+func TestUpsertUpdate(t *testing.T) {
+	setting := &Setting{Key: "theme", Value: "dark", CreatedBy: "alice"}
+	setting.CreatedAt = utcNow()
+	setting.UpdatedAt = setting.CreatedAt
+
+	uow := newOfflineUnitOfWork[*Setting](t, nil)
+	update, err := uow.upsertUpdate(setting)
+	require.NoError(t, err)
+
+	keys := func(doc interface{}) []string {
+		var names []string
+		for _, e := range doc.(bson.D) {
+			names = append(names, e.Key)
+		}
+		return names
+	}
+	assert.ElementsMatch(t, []string{"createdAt", "createdBy"}, keys(update["$setOnInsert"]))
+	assert.ElementsMatch(t, []string{"updatedAt", "key", "value"}, keys(update["$set"]))
+	assert.Equal(t, bson.M{"deletedAt": ""}, update["$unset"], "a soft-deleted match is revived")
+
+	deletedAt := utcNow()
+	setting.DeletedAt = &deletedAt
+	update, err = uow.upsertUpdate(setting)
+	require.NoError(t, err)
+	assert.Contains(t, keys(update["$set"]), "deletedAt")
+	assert.NotContains(t, update, "$unset")
+}
+
+func TestUnitOfWork_Upsert_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*Setting](t)
+	ctx := context.Background()
+	byKey := identifier.New().Equal("key", "theme")
+
+	inserted, err := uow.Upsert(ctx, byKey, &Setting{Key: "theme", Value: "dark", CreatedBy: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", inserted.CreatedBy)
+	assert.Equal(t, "dark", inserted.Value)
+
+	updated, err := uow.Upsert(ctx, byKey, &Setting{Key: "theme", Value: "light", CreatedBy: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, inserted.GetID(), updated.GetID())
+	assert.Equal(t, "light", updated.Value)
+	assert.Equal(t, "alice", updated.CreatedBy, "immutable fields keep their inserted value")
+	assert.Equal(t, inserted.CreatedAt, updated.CreatedAt)
+	assert.True(t, updated.UpdatedAt.After(inserted.UpdatedAt) || updated.UpdatedAt.Equal(inserted.UpdatedAt))
+
+	all, err := uow.FindAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+}
+
+func TestUnitOfWork_BulkUpsert_Guards(t *testing.T) {
+	uow := newOfflineUnitOfWork[*Setting](t, nil)
+	ctx := context.Background()
+
+	upserted, err := uow.BulkUpsert(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, upserted)
+
+	_, err = uow.BulkUpsert(ctx, []*Setting{{Key: "theme"}, nil})
+	assert.ErrorIs(t, err, uowerrors.ErrNilEntity)
+	assert.Zero(t, uow.Stats().Total())
+}
+
+func TestUnitOfWork_BulkUpsert_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*Setting](t)
+	ctx := context.Background()
+
+	existing, err := uow.Insert(ctx, &Setting{Key: "theme", Value: "dark", CreatedBy: "alice"})
+	require.NoError(t, err)
+	createdAt := existing.CreatedAt
+
+	presetID := primitive.NewObjectID()
+	preset := &Setting{Key: "locale", Value: "en", CreatedBy: "bob"}
+	preset.SetID(presetID)
+	fresh := &Setting{Key: "timezone", Value: "UTC", CreatedBy: "bob"}
+
+	update := &Setting{Key: "theme", Value: "light", CreatedBy: "bob"}
+	update.SetID(existing.GetID())
+
+	result, err := uow.bulkUpsert(ctx, []*Setting{update, preset, fresh})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.MatchedCount)
+	assert.Equal(t, int64(1), result.ModifiedCount)
+	assert.Equal(t, int64(2), result.UpsertedCount)
+	assert.Equal(t, map[int64]interface{}{1: presetID, 2: fresh.GetID()}, result.UpsertedIDs)
+	assert.False(t, fresh.GetID().IsZero(), "entities without an ID are given one")
+
+	stored, err := uow.FindOneById(ctx, existing.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, "light", stored.Value)
+	assert.Equal(t, "alice", stored.CreatedBy, "immutable fields keep their inserted value")
+	assert.Equal(t, createdAt, stored.CreatedAt)
+
+	all, err := uow.FindAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	again, err := uow.BulkUpsert(ctx, []*Setting{fresh})
+	require.NoError(t, err)
+	assert.Equal(t, []*Setting{fresh}, again)
+
+	live, err := uow.Count(ctx, identifier.New().IsNull("deletedAt"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), live, "upserted documents carry no deletedAt field")
+}
+
+func TestUnitOfWork_Upsert_RevivesSoftDeleted_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*Setting](t)
+	ctx := context.Background()
+	byKey := identifier.New().Equal("key", "theme")
+
+	existing, err := uow.Insert(ctx, &Setting{Key: "theme", Value: "dark", CreatedBy: "alice"})
+	require.NoError(t, err)
+	_, err = uow.SoftDelete(ctx, byKey)
+	require.NoError(t, err)
+
+	revived, err := uow.Upsert(ctx, byKey, &Setting{Key: "theme", Value: "light", CreatedBy: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, existing.GetID(), revived.GetID())
+	assert.Nil(t, revived.DeletedAt)
+	assert.Equal(t, "alice", revived.CreatedBy)
+
+	_, err = uow.SoftDelete(ctx, byKey)
+	require.NoError(t, err)
+	update := &Setting{Key: "theme", Value: "blue"}
+	update.SetID(existing.GetID())
+	result, err := uow.bulkUpsert(ctx, []*Setting{update})
+	require.NoError(t, err, "a soft-deleted _id is updated, not inserted again")
+	assert.Equal(t, int64(1), result.MatchedCount)
+	assert.Zero(t, result.UpsertedCount)
+
+	all, err := uow.FindAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, "blue", all[0].Value)
+}
+
+func TestBaseRepository_Upsert_Integration(t *testing.T) {
+	uow := newIntegrationUnitOfWork[*Setting](t)
+	ctx := context.Background()
+
+	config := NewConfig()
+	config.Database = uow.database.Name()
+	factory, err := NewFactory[*Setting](config)
+	require.NoError(t, err)
+	defer factory.Close(ctx)
+	repo := NewBaseRepository[*Setting](factory)
+	byKey := identifier.New().Equal("key", "locale")
+
+	created, err := repo.Upsert(ctx, byKey, &Setting{Key: "locale", Value: "en"})
+	require.NoError(t, err)
+	assert.False(t, created.GetID().IsZero())
+	assert.False(t, created.CreatedAt.IsZero())
+
+	stale := &Setting{Key: "locale", Value: "fr"}
+	stale.CreatedAt = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	updated, err := repo.Upsert(ctx, byKey, stale)
+	require.NoError(t, err)
+	assert.Equal(t, created.GetID(), updated.GetID())
+	assert.Equal(t, "fr", updated.Value)
+	assert.Equal(t, created.CreatedAt, updated.CreatedAt, "the update branch never overwrites createdAt")
+	assert.False(t, updated.UpdatedAt.Before(created.UpdatedAt))
+
+	count, err := repo.Count(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
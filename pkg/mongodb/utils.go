@@ -1,9 +1,23 @@
 package mongodb
 
 import (
+	"fmt"
 	"reflect"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
 )
 
+// utcNow returns the current time in UTC. All timestamps written by the unit
+// of work go through it so in-memory entities match what BSON stores, and the
+// driver decodes dates back as UTC.
+func utcNow() time.Time {
+	return time.Now().UTC()
+}
+
 // isZeroValue checks if a value is zero/nil
 func isZeroValue(v interface{}) bool {
 	if v == nil {
@@ -18,3 +32,59 @@ func isZeroValue(v interface{}) bool {
 		return rv.IsZero()
 	}
 }
+
+// checkEntity returns ErrNilEntity for a nil entity, which would otherwise
+// panic on the first method call
+func checkEntity(entity interface{}) error {
+	if entity == nil {
+		return uowerrors.ErrNilEntity
+	}
+	if rv := reflect.ValueOf(entity); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return uowerrors.ErrNilEntity
+	}
+	return nil
+}
+
+// checkEntities runs checkEntity on every element, so a batch is rejected
+// before any of it is modified or written
+func checkEntities[T any](entities []T) error {
+	for i, entity := range entities {
+		if err := checkEntity(entity); err != nil {
+			return fmt.Errorf("%w at index %d", err, i)
+		}
+	}
+	return nil
+}
+
+// supportsSoftDelete reports whether the model type keeps the default
+// soft-delete behaviour, i.e. it does not opt out through domain.SoftDeletable.
+func supportsSoftDelete(model interface{}) bool {
+	if sd, ok := modelInstance(model).(domain.SoftDeletable); ok {
+		return sd.SoftDeletable()
+	}
+	return true
+}
+
+// modelInstance returns a pointer to a new zero value of the model's type, so
+// optional interfaces can be checked even when model is a nil pointer. It
+// returns nil for a nil interface.
+func modelInstance(model interface{}) interface{} {
+	t := reflect.TypeOf(model)
+	if t == nil {
+		return nil
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return reflect.New(t).Interface()
+}
+
+// parseHexID converts a hex string into an ObjectID, returning ErrInvalidID for
+// malformed input
+func parseHexID(hexID string) (primitive.ObjectID, error) {
+	id, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("%w: %q is not a 24-character hex string", uowerrors.ErrInvalidID, hexID)
+	}
+	return id, nil
+}
@@ -2,10 +2,16 @@ package persistence
 
 import (
 	"context"
+	"time"
 
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 // ModelConstraint defines the constraint for model types
@@ -19,41 +25,195 @@ type IUnitOfWork[T ModelConstraint] interface {
 	BeginTransaction(ctx context.Context) error
 	CommitTransaction(ctx context.Context) error
 	RollbackTransaction(ctx context.Context)
+	RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error
 
 	// Queries
 	FindAll(ctx context.Context) ([]T, error)
+	FindAllWithTrashed(ctx context.Context) ([]T, error)
 	FindAllWithPagination(ctx context.Context, query domain.QueryParams[T]) ([]T, uint, error)
+	FindAllWithOptions(ctx context.Context, query domain.QueryParams[T], opts domain.FindOptions) ([]T, uint, error)
+	Count(ctx context.Context, identifier identifier.IIdentifier) (int64, error)
+	Exists(ctx context.Context, identifier identifier.IIdentifier) (bool, error)
+	Distinct(ctx context.Context, field string, filter identifier.IIdentifier) ([]interface{}, error)
+	DistinctWithCounts(ctx context.Context, field string, filter identifier.IIdentifier) ([]ValueCount, error)
+	Aggregate(ctx context.Context, pipeline mongo.Pipeline, result interface{}) error
 	FindOne(ctx context.Context, filter T) (T, error)
 	FindOneById(ctx context.Context, id primitive.ObjectID) (T, error)
+	FindOneByIdWithTrashed(ctx context.Context, id primitive.ObjectID) (T, error)
+	FindByIds(ctx context.Context, ids []primitive.ObjectID) ([]T, error)
+	FindByIdsOrdered(ctx context.Context, ids []primitive.ObjectID) ([]T, error)
+	FindOneByHexId(ctx context.Context, hexID string) (T, error)
 	FindOneByIdentifier(ctx context.Context, identifier identifier.IIdentifier) (T, error)
+	TryFindOneById(ctx context.Context, id primitive.ObjectID) (T, bool, error)
+	TryFindOne(ctx context.Context, identifier identifier.IIdentifier) (T, bool, error)
+	FindAllWithCursor(ctx context.Context, query domain.KeysetParams[T]) (CursorPage[T], error)
+	Export(ctx context.Context, query domain.KeysetParams[T]) (ExportBatch[T], error)
+	FindAllRaw(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]T, error)
+	FindOneRaw(ctx context.Context, filter bson.M) (T, error)
 	ResolveIDByUniqueField(ctx context.Context, model domain.BaseModel, field string, value interface{}) (primitive.ObjectID, error)
 
 	// Mutations
 	Insert(ctx context.Context, entity T) (T, error)
 	Update(ctx context.Context, identifier identifier.IIdentifier, entity T) (T, error)
+	UpdateReturningBefore(ctx context.Context, identifier identifier.IIdentifier, entity T) (T, error)
+	UpdateModified(ctx context.Context, identifier identifier.IIdentifier, entity T) (T, bool, error)
+	UpdateFields(ctx context.Context, identifier identifier.IIdentifier, fields bson.M) (T, error)
+	UpdateIf(ctx context.Context, identifier identifier.IIdentifier, condition identifier.IIdentifier, fields bson.M) (T, bool, error)
+	UpdateWithArrayFilters(ctx context.Context, identifier identifier.IIdentifier, update bson.M, arrayFilters []interface{}) (T, error)
+	RetryOnConflict(ctx context.Context, id primitive.ObjectID, mutate func(T) error, maxAttempts int) (T, error)
+	UpdateIfChanged(ctx context.Context, identifier identifier.IIdentifier, entity T) (T, bool, error)
+	UpdateMany(ctx context.Context, identifier identifier.IIdentifier, fields bson.M) (int64, error)
+	Upsert(ctx context.Context, identifier identifier.IIdentifier, entity T) (T, error)
 	Delete(ctx context.Context, identifier identifier.IIdentifier) error
+	DeleteAll(ctx context.Context, confirm bool) error
 
 	// Soft & Hard Delete
 	SoftDelete(ctx context.Context, identifier identifier.IIdentifier) (T, error)
+	SoftDeleteReturningBefore(ctx context.Context, identifier identifier.IIdentifier) (T, error)
 	HardDelete(ctx context.Context, identifier identifier.IIdentifier) (T, error)
 
 	// Bulk operations
 	BulkInsert(ctx context.Context, entities []T) ([]T, error)
-	BulkUpdate(ctx context.Context, entities []T) ([]T, error)
-	BulkSoftDelete(ctx context.Context, identifiers []identifier.IIdentifier) error
-	BulkHardDelete(ctx context.Context, identifiers []identifier.IIdentifier) error
+	BulkInsertWithOptions(ctx context.Context, entities []T, opts BulkOptions) ([]T, error)
+	BulkUpdate(ctx context.Context, entities []T) (BulkResult, error)
+	BulkUpsert(ctx context.Context, entities []T) ([]T, error)
+	BulkSoftDelete(ctx context.Context, identifiers []identifier.IIdentifier) (BulkResult, error)
+	BulkSoftDeleteWithOptions(ctx context.Context, identifiers []identifier.IIdentifier, opts BulkOptions) (BulkResult, error)
+	SoftDeleteMany(ctx context.Context, identifier identifier.IIdentifier) (int64, error)
+	BulkHardDelete(ctx context.Context, identifiers []identifier.IIdentifier) (BulkResult, error)
 
 	// Trashed Data
 	GetTrashed(ctx context.Context) ([]T, error)
 	GetTrashedWithPagination(ctx context.Context, query domain.QueryParams[T]) ([]T, uint, error)
+	GetTrashedInRange(ctx context.Context, start, end time.Time) ([]T, error)
+	GetTrashedInRangeWithPagination(ctx context.Context, start, end time.Time, query domain.QueryParams[T]) ([]T, uint, error)
+	PurgeTrashedOlderThan(ctx context.Context, cutoff time.Time, dryRun bool) (int64, error)
 
 	// Restore
 	Restore(ctx context.Context, identifier identifier.IIdentifier) (T, error)
-	RestoreAll(ctx context.Context) error
+	RestoreAll(ctx context.Context, confirm bool) error
+	RestoreMany(ctx context.Context, identifier identifier.IIdentifier) (int64, error)
+	BulkRestore(ctx context.Context, identifiers []identifier.IIdentifier) error
+
+	// Maintenance
+	BackfillTimestamps(ctx context.Context) (int64, error)
+	RenameField(ctx context.Context, from, to string) (int64, error)
+	MigrateDocuments(ctx context.Context, filter identifier.IIdentifier, transform func(bson.M) (bson.M, error), batchSize int) (int64, error)
+	EnsureSlugIndex(ctx context.Context) error
+	EnsureIndexes(ctx context.Context, specs ...IndexSpec) ([]string, error)
+	EnsureIndexesAsync(ctx context.Context, specs ...IndexSpec) <-chan IndexBuildResult
+	CreateUniqueIndex(ctx context.Context, field string) error
+
+	// Scoping
+	ForDatabase(name string) IUnitOfWork[T]
+	WithReadConcern(rc *readconcern.ReadConcern) IUnitOfWork[T]
+	WithReadPreference(rp *readpref.ReadPref) IUnitOfWork[T]
+	SnapshotContext(ctx context.Context) (context.Context, func(), error)
+
+	// Diagnostics
+	CollectionName() string
+	Stats() OperationStats
+	ResetStats()
+}
+
+// BulkOptions controls how bulk operations are split into batches
+type BulkOptions struct {
+	// BatchSize is the number of items written per round trip; zero or less
+	// uses the configured bulk batch size
+	BatchSize int
+	// Progress, when set, is called after each batch with the number of
+	// items processed so far and the total
+	Progress func(done, total int)
+}
+
+// BulkResult summarizes a bulk write keyed by identifiers
+type BulkResult struct {
+	// Requested is the number of identifiers submitted
+	Requested int `json:"requested"`
+	// Processed is the number of identifiers sent in completed batches
+	Processed int   `json:"processed"`
+	Matched   int64 `json:"matched"`
+	Modified  int64 `json:"modified"`
+	Deleted   int64 `json:"deleted"`
+	// UnmatchedIDs lists the entities BulkUpdate found no live document for
+	UnmatchedIDs []primitive.ObjectID `json:"unmatchedIds,omitempty"`
+}
+
+// Unmatched returns how many processed identifiers affected no document,
+// e.g. because the record was missing or already trashed
+func (r BulkResult) Unmatched() int64 {
+	return int64(r.Processed) - r.Matched - r.Deleted
+}
+
+// Affected returns how many documents the bulk write changed: those modified
+// by a soft delete or update plus those removed by a hard delete
+func (r BulkResult) Affected() int64 {
+	return r.Modified + r.Deleted
+}
+
+// CursorPage is one page of a keyset query. The cursors are opaque tokens to
+// pass back as KeysetParams.Cursor and are empty when there is no such page.
+type CursorPage[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	PrevCursor string `json:"prevCursor,omitempty"`
+	// HasMore reports whether more items follow in the direction paged
+	HasMore bool `json:"hasMore"`
+}
+
+// ExportBatch is one batch of a resumable export. ResumeToken is passed back
+// as KeysetParams.Cursor to fetch the next batch and is empty once Done.
+type ExportBatch[T any] struct {
+	Items       []T    `json:"items"`
+	ResumeToken string `json:"resumeToken,omitempty"`
+	Done        bool   `json:"done"`
+}
+
+// IndexSpec declares an index created by EnsureIndexes
+type IndexSpec struct {
+	// Keys lists the indexed fields in order, with 1 or -1 as direction
+	Keys bson.D
+	// Name overrides the server-generated index name
+	Name   string
+	Unique bool
+	// PartialFilter limits the index to matching documents. Only equality,
+	// $exists: true, $gt/$gte/$lt/$lte, $type and a top-level $and are allowed.
+	PartialFilter bson.M
+	// Background asks servers before 4.2 to build without locking the
+	// collection. Later servers ignore it and always build that way.
+	Background bool
+}
+
+// IndexBuildResult reports the outcome of EnsureIndexesAsync
+type IndexBuildResult struct {
+	Names []string
+	Err   error
+}
+
+// ValueCount is a distinct value of a field and the number of documents
+// holding it, e.g. one facet of a filter
+type ValueCount struct {
+	Value interface{} `bson:"value" json:"value"`
+	Count int64       `bson:"count" json:"count"`
+}
+
+// OperationStats counts the database operations issued by a Unit of Work
+type OperationStats struct {
+	Finds   int64 `json:"finds"`
+	Counts  int64 `json:"counts"`
+	Inserts int64 `json:"inserts"`
+	Updates int64 `json:"updates"`
+	Deletes int64 `json:"deletes"`
+}
+
+// Total returns the number of operations across all kinds
+func (s OperationStats) Total() int64 {
+	return s.Finds + s.Counts + s.Inserts + s.Updates + s.Deletes
 }
 
 // IUnitOfWorkFactory creates Unit of Work instances with generics
 type IUnitOfWorkFactory[T ModelConstraint] interface {
+	Ping(ctx context.Context) error
 	Create() IUnitOfWork[T]
 	CreateWithContext(ctx context.Context) IUnitOfWork[T]
 }
@@ -5,30 +5,70 @@ import (
 
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
-type IBaseRepository[T ModelConstraint] interface {
-	Insert(ctx context.Context, entity T) (T, error)
-	Update(ctx context.Context, id identifier.IIdentifier, entity T) (T, error)
-	Delete(ctx context.Context, id identifier.IIdentifier) error
+// IReadOnlyRepository exposes only the queries of a repository, for services
+// such as reporting that must never write
+type IReadOnlyRepository[T ModelConstraint] interface {
 	FindOneById(ctx context.Context, id primitive.ObjectID) (T, error)
+	FindOneByHexId(ctx context.Context, hexID string) (T, error)
+	FindOneByIdWithTrashed(ctx context.Context, id primitive.ObjectID) (T, error)
+	FindByIds(ctx context.Context, ids []primitive.ObjectID) ([]T, error)
+	FindByIdsOrdered(ctx context.Context, ids []primitive.ObjectID) ([]T, error)
 	FindOne(ctx context.Context, id identifier.IIdentifier) (T, error)
+	TryFindOneById(ctx context.Context, id primitive.ObjectID) (T, bool, error)
+	TryFindOne(ctx context.Context, id identifier.IIdentifier) (T, bool, error)
 	FindAll(ctx context.Context, id identifier.IIdentifier) ([]T, error)
+	FindAllMatchingAny(ctx context.Context, ids ...identifier.IIdentifier) ([]T, error)
+	FindAllMatchingAll(ctx context.Context, ids ...identifier.IIdentifier) ([]T, error)
+	FindAllSatisfying(ctx context.Context, spec identifier.Specification) ([]T, error)
 	FindAllWithPagination(ctx context.Context, query domain.QueryParams[T]) ([]T, int64, error)
+	Count(ctx context.Context, id identifier.IIdentifier) (int64, error)
+	Exists(ctx context.Context, id identifier.IIdentifier) (bool, error)
+	Distinct(ctx context.Context, field string, filter identifier.IIdentifier) ([]interface{}, error)
+	DistinctWithCounts(ctx context.Context, field string, filter identifier.IIdentifier) ([]ValueCount, error)
+	Aggregate(ctx context.Context, pipeline mongo.Pipeline, result interface{}) error
+	GetTrashed(ctx context.Context) ([]T, error)
+
+	CollectionName() string
+}
+
+type IBaseRepository[T ModelConstraint] interface {
+	IReadOnlyRepository[T]
+
+	Insert(ctx context.Context, entity T) (T, error)
+	Update(ctx context.Context, id identifier.IIdentifier, entity T) (T, error)
+	UpdateFields(ctx context.Context, id identifier.IIdentifier, fields bson.M) (T, error)
+	UpdateIf(ctx context.Context, id identifier.IIdentifier, condition identifier.IIdentifier, fields bson.M) (T, bool, error)
+	UpdateWithArrayFilters(ctx context.Context, id identifier.IIdentifier, update bson.M, arrayFilters []interface{}) (T, error)
+	RetryOnConflict(ctx context.Context, id primitive.ObjectID, mutate func(T) error, maxAttempts int) (T, error)
+	UpdateMany(ctx context.Context, id identifier.IIdentifier, fields bson.M) (int64, error)
+	Upsert(ctx context.Context, id identifier.IIdentifier, entity T) (T, error)
+	Delete(ctx context.Context, id identifier.IIdentifier) error
+	DeleteAll(ctx context.Context, confirm bool) error
 
 	BulkInsert(ctx context.Context, entities []T) ([]T, error)
-	BulkUpdate(ctx context.Context, entities []T) ([]T, error)
-	BulkDelete(ctx context.Context, identifiers []identifier.IIdentifier) error
+	BulkUpdate(ctx context.Context, entities []T) (BulkResult, error)
+	BulkUpsert(ctx context.Context, entities []T) ([]T, error)
+	BulkDelete(ctx context.Context, identifiers []identifier.IIdentifier) (BulkResult, error)
 
 	SoftDelete(ctx context.Context, id identifier.IIdentifier) (T, error)
-	BulkSoftDelete(ctx context.Context, identifiers []identifier.IIdentifier) error
+	BulkSoftDelete(ctx context.Context, identifiers []identifier.IIdentifier) (BulkResult, error)
+	SoftDeleteMany(ctx context.Context, id identifier.IIdentifier) (int64, error)
 	Restore(ctx context.Context, id identifier.IIdentifier) (T, error)
-	GetTrashed(ctx context.Context) ([]T, error)
+	RestoreMany(ctx context.Context, id identifier.IIdentifier) (int64, error)
+	BulkRestore(ctx context.Context, identifiers []identifier.IIdentifier) error
 
 	BeginTransaction(ctx context.Context) error
 	CommitTransaction(ctx context.Context) error
 	RollbackTransaction(ctx context.Context) error
+	RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+
+	Ping(ctx context.Context) error
+	CreateUniqueIndex(ctx context.Context, field string) error
 }
 
 type IUserRepository interface {
@@ -56,6 +96,9 @@ type User struct {
 	Active            bool   `bson:"active" json:"active"`
 }
 
+// NormalizedFields stores emails lowercased so lookups ignore case
+func (User) NormalizedFields() []string { return []string{"email"} }
+
 type Product struct {
 	domain.BaseEntity `bson:",inline"`
 	Price             float64 `bson:"price" json:"price"`
@@ -64,14 +107,14 @@ type Product struct {
 }
 
 type UserStats struct {
-	TotalUsers  int64   `json:"totalUsers"`
-	ActiveUsers int64   `json:"activeUsers"`
-	AverageAge  float64 `json:"averageAge"`
+	TotalUsers  int64   `bson:"totalUsers" json:"totalUsers"`
+	ActiveUsers int64   `bson:"activeUsers" json:"activeUsers"`
+	AverageAge  float64 `bson:"averageAge" json:"averageAge"`
 }
 
 type ProductStats struct {
-	TotalProducts   int64    `json:"totalProducts"`
-	InStockProducts int64    `json:"inStockProducts"`
-	AveragePrice    float64  `json:"averagePrice"`
-	Categories      []string `json:"categories"`
+	TotalProducts   int64    `bson:"totalProducts" json:"totalProducts"`
+	InStockProducts int64    `bson:"inStockProducts" json:"inStockProducts"`
+	AveragePrice    float64  `bson:"averagePrice" json:"averagePrice"`
+	Categories      []string `bson:"categories" json:"categories"`
 }
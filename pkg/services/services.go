@@ -4,12 +4,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
 	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// conflictAttempts bounds the read-modify-write attempts of service methods
+// that retry on ErrVersionConflict
+const conflictAttempts = 3
+
 type IUserService interface {
 	CreateUser(ctx context.Context, email string, age int) (*persistence.User, error)
 	GetUserByID(ctx context.Context, id primitive.ObjectID) (*persistence.User, error)
@@ -25,6 +33,11 @@ type IUserService interface {
 
 	CreateUsers(ctx context.Context, users []*persistence.User) ([]*persistence.User, error)
 	BulkDeactivateUsers(ctx context.Context, userIDs []primitive.ObjectID) error
+	SoftDeleteUsersByPredicate(ctx context.Context, id identifier.IIdentifier) (int64, error)
+	RestoreUsersByPredicate(ctx context.Context, id identifier.IIdentifier) (int64, error)
+
+	EnsureIndexes(ctx context.Context) error
+	Ping(ctx context.Context) error
 }
 
 type IProductService interface {
@@ -40,7 +53,9 @@ type IProductService interface {
 	GetProductStatistics(ctx context.Context) (*persistence.ProductStats, error)
 
 	CreateProducts(ctx context.Context, products []*persistence.Product) ([]*persistence.Product, error)
-	BulkUpdateStock(ctx context.Context, productIDs []primitive.ObjectID, inStock bool) error
+	BulkUpdateStock(ctx context.Context, productIDs []primitive.ObjectID, inStock bool) (int64, error)
+
+	Ping(ctx context.Context) error
 }
 
 type UserService struct {
@@ -61,8 +76,11 @@ func (s *UserService) CreateUser(ctx context.Context, email string, age int) (*p
 		return nil, errors.New("age must be between 0 and 150")
 	}
 
-	existingUser, err := s.userRepo.FindByEmail(ctx, email)
-	if err == nil && existingUser != nil {
+	exists, err := s.userRepo.Exists(ctx, identifier.New().Equal("email", domain.Normalize(email)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+	if exists {
 		return nil, fmt.Errorf("user with email %s already exists", email)
 	}
 
@@ -74,7 +92,17 @@ func (s *UserService) CreateUser(ctx context.Context, email string, age int) (*p
 	user.SetName(fmt.Sprintf("User_%s", email))
 	user.SetSlug(fmt.Sprintf("user-%s", email))
 
-	return s.userRepo.Insert(ctx, user)
+	created, err := s.userRepo.Insert(ctx, user)
+	if errors.Is(err, uowerrors.ErrDuplicateKey) {
+		return nil, fmt.Errorf("user with email %s already exists: %w", email, uowerrors.ErrEntityExists)
+	}
+	return created, err
+}
+
+// EnsureIndexes creates the unique email index CreateUser relies on to reject
+// duplicates registered concurrently
+func (s *UserService) EnsureIndexes(ctx context.Context) error {
+	return s.userRepo.CreateUniqueIndex(ctx, "email")
 }
 
 func (s *UserService) GetUserByID(ctx context.Context, id primitive.ObjectID) (*persistence.User, error) {
@@ -95,26 +123,25 @@ func (s *UserService) UpdateUser(ctx context.Context, user *persistence.User) (*
 }
 
 func (s *UserService) DeactivateUser(ctx context.Context, id primitive.ObjectID) error {
-	user, err := s.userRepo.FindOneById(ctx, id)
-	if err != nil {
-		return fmt.Errorf("user not found: %w", err)
-	}
-
-	user.Active = false
-	updateCriteria := identifier.New().Equal("_id", id)
-	_, err = s.userRepo.Update(ctx, updateCriteria, user)
+	_, err := s.setActive(ctx, id, false)
 	return err
 }
 
 func (s *UserService) ActivateUser(ctx context.Context, id primitive.ObjectID) (*persistence.User, error) {
-	user, err := s.userRepo.FindOneById(ctx, id)
+	return s.setActive(ctx, id, true)
+}
+
+// setActive flips the active flag with a read-modify-write that is retried
+// when a concurrent update to the same user wins
+func (s *UserService) setActive(ctx context.Context, id primitive.ObjectID, active bool) (*persistence.User, error) {
+	user, err := s.userRepo.RetryOnConflict(ctx, id, func(user *persistence.User) error {
+		user.Active = active
+		return nil
+	}, conflictAttempts)
 	if err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
+		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
-
-	user.Active = true
-	updateCriteria := identifier.New().Equal("_id", id)
-	return s.userRepo.Update(ctx, updateCriteria, user)
+	return user, nil
 }
 
 func (s *UserService) DeleteUser(ctx context.Context, id primitive.ObjectID) error {
@@ -161,6 +188,37 @@ func (s *UserService) BulkDeactivateUsers(ctx context.Context, userIDs []primiti
 	return nil
 }
 
+// SoftDeleteUsersByPredicate soft deletes every user matched by id in one
+// write and returns how many were deleted
+func (s *UserService) SoftDeleteUsersByPredicate(ctx context.Context, id identifier.IIdentifier) (int64, error) {
+	if err := validatePredicate(id); err != nil {
+		return 0, err
+	}
+	return s.userRepo.SoftDeleteMany(ctx, id)
+}
+
+// RestoreUsersByPredicate restores every soft-deleted user matched by id in
+// one write and returns how many were restored
+func (s *UserService) RestoreUsersByPredicate(ctx context.Context, id identifier.IIdentifier) (int64, error) {
+	if err := validatePredicate(id); err != nil {
+		return 0, err
+	}
+	return s.userRepo.RestoreMany(ctx, id)
+}
+
+// validatePredicate rejects a missing or empty predicate, which would
+// otherwise match every document
+func validatePredicate(id identifier.IIdentifier) error {
+	if id == nil || len(id.ToBSON()) == 0 {
+		return errors.New("predicate must not be empty")
+	}
+	return nil
+}
+
+func (s *UserService) Ping(ctx context.Context) error {
+	return s.userRepo.Ping(ctx)
+}
+
 type ProductService struct {
 	productRepo persistence.IProductRepository
 }
@@ -261,12 +319,62 @@ func (s *ProductService) CreateProducts(ctx context.Context, products []*persist
 	return s.productRepo.BulkInsert(ctx, products)
 }
 
-func (s *ProductService) BulkUpdateStock(ctx context.Context, productIDs []primitive.ObjectID, inStock bool) error {
-
+// BulkUpdateStock sets the stock flag on all the given products with a single
+// update and returns how many were updated. The lookup and the update run in
+// one transaction, so if any product is missing or deleted nothing is
+// updated. Standalone servers need the factory's transaction fallback, which
+// gives up that guarantee.
+func (s *ProductService) BulkUpdateStock(ctx context.Context, productIDs []primitive.ObjectID, inStock bool) (int64, error) {
+	unique := make([]primitive.ObjectID, 0, len(productIDs))
+	seen := make(map[primitive.ObjectID]bool, len(productIDs))
 	for _, id := range productIDs {
-		if _, err := s.SetProductStock(ctx, id, inStock); err != nil {
-			return fmt.Errorf("failed to update stock for product %s: %w", id.Hex(), err)
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
 		}
 	}
-	return nil
+	if len(unique) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]interface{}, len(unique))
+	for i, id := range unique {
+		ids[i] = id
+	}
+	criteria := identifier.New().In("_id", ids)
+
+	var updated int64
+	err := s.productRepo.RunInTransaction(ctx, func(ctx context.Context) error {
+		found, err := s.productRepo.FindByIds(ctx, unique)
+		if err != nil {
+			return fmt.Errorf("failed to load products: %w", err)
+		}
+		if len(found) != len(unique) {
+			remaining := make(map[primitive.ObjectID]bool, len(unique))
+			for _, id := range unique {
+				remaining[id] = true
+			}
+			for _, product := range found {
+				delete(remaining, product.GetID())
+			}
+			missing := make([]string, 0, len(remaining))
+			for _, id := range unique {
+				if remaining[id] {
+					missing = append(missing, id.Hex())
+				}
+			}
+			return fmt.Errorf("products not found: %s", strings.Join(missing, ", "))
+		}
+
+		updated, err = s.productRepo.UpdateMany(ctx, criteria, bson.M{"inStock": inStock})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return updated, nil
+}
+
+func (s *ProductService) Ping(ctx context.Context) error {
+	return s.productRepo.Ping(ctx)
 }
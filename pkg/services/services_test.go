@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/mongodb"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+)
+
+// stockRepo keeps products in memory. UpdateMany applies to the products the
+// last FindByIds returned, and RunInTransaction restores the stock flags when
+// fn fails.
+type stockRepo struct {
+	persistence.IProductRepository
+	products    map[primitive.ObjectID]*persistence.Product
+	lastFound   []*persistence.Product
+	updateErr   error
+	updateCalls int
+}
+
+func (r *stockRepo) FindByIds(_ context.Context, ids []primitive.ObjectID) ([]*persistence.Product, error) {
+	r.lastFound = nil
+	for _, id := range ids {
+		if product, ok := r.products[id]; ok {
+			r.lastFound = append(r.lastFound, product)
+		}
+	}
+	return r.lastFound, nil
+}
+
+func (r *stockRepo) UpdateMany(_ context.Context, _ identifier.IIdentifier, fields bson.M) (int64, error) {
+	r.updateCalls++
+	for _, product := range r.lastFound {
+		product.InStock = fields["inStock"].(bool)
+	}
+	if r.updateErr != nil {
+		return 0, r.updateErr
+	}
+	return int64(len(r.lastFound)), nil
+}
+
+func (r *stockRepo) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	snapshot := make(map[primitive.ObjectID]bool, len(r.products))
+	for id, product := range r.products {
+		snapshot[id] = product.InStock
+	}
+	if err := fn(ctx); err != nil {
+		for id, inStock := range snapshot {
+			r.products[id].InStock = inStock
+		}
+		return err
+	}
+	return nil
+}
+
+func newStockRepo(ids ...primitive.ObjectID) *stockRepo {
+	repo := &stockRepo{products: map[primitive.ObjectID]*persistence.Product{}}
+	for _, id := range ids {
+		repo.products[id] = &persistence.Product{BaseEntity: domain.BaseEntity{ID: id}, InStock: true}
+	}
+	return repo
+}
+
+func TestProductService_BulkUpdateStock(t *testing.T) {
+	a, b, missing := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	repo := newStockRepo(a, b)
+	service := NewProductService(repo)
+	ctx := context.Background()
+
+	_, err := service.BulkUpdateStock(ctx, []primitive.ObjectID{a, missing, b}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), missing.Hex())
+	assert.Zero(t, repo.updateCalls, "nothing is written when a product is missing")
+	assert.True(t, repo.products[a].InStock)
+
+	updated, err := service.BulkUpdateStock(ctx, []primitive.ObjectID{a, b, a}, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), updated)
+	assert.Equal(t, 1, repo.updateCalls)
+	assert.False(t, repo.products[a].InStock)
+	assert.False(t, repo.products[b].InStock)
+
+	updated, err = service.BulkUpdateStock(ctx, nil, true)
+	require.NoError(t, err)
+	assert.Zero(t, updated)
+}
+
+func TestProductService_BulkUpdateStock_RollsBack(t *testing.T) {
+	a, b := primitive.NewObjectID(), primitive.NewObjectID()
+	repo := newStockRepo(a, b)
+	repo.updateErr = errors.New("write conflict")
+	service := NewProductService(repo)
+
+	updated, err := service.BulkUpdateStock(context.Background(), []primitive.ObjectID{a, b}, false)
+	assert.ErrorIs(t, err, repo.updateErr)
+	assert.Zero(t, updated)
+	assert.True(t, repo.products[a].InStock, "a failed update leaves every product untouched")
+	assert.True(t, repo.products[b].InStock)
+}
+
+func TestProductService_BulkUpdateStock_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+	ctx := context.Background()
+
+	config := mongodb.NewConfig()
+	config.Database = "services_" + primitive.NewObjectID().Hex()
+	config.Timeout = 2 * time.Second
+	factory, err := mongodb.NewFactory[*persistence.Product](config, mongodb.WithTransactionFallback())
+	require.NoError(t, err)
+	defer factory.Close(ctx)
+
+	base := mongodb.NewBaseRepository[*persistence.Product](factory)
+	if err := base.Ping(ctx); err != nil {
+		t.Skipf("Integration test requires MongoDB instance: %v", err)
+	}
+	t.Cleanup(func() { _ = base.DeleteAll(ctx, true) })
+	service := NewProductService(mongodb.NewProductRepository(base))
+
+	products, err := service.CreateProducts(ctx, []*persistence.Product{
+		{BaseEntity: domain.BaseEntity{Name: "Laptop"}, Category: "electronics", Price: 1000, InStock: true},
+		{BaseEntity: domain.BaseEntity{Name: "Phone"}, Category: "electronics", Price: 500, InStock: true},
+	})
+	require.NoError(t, err)
+	ids := []primitive.ObjectID{products[0].GetID(), products[1].GetID()}
+
+	_, err = service.BulkUpdateStock(ctx, append(ids, primitive.NewObjectID()), false)
+	require.Error(t, err)
+	inStock, err := service.GetInStockProducts(ctx)
+	require.NoError(t, err)
+	assert.Len(t, inStock, 2, "a missing product leaves every product untouched")
+
+	updated, err := service.BulkUpdateStock(ctx, ids, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), updated)
+	inStock, err = service.GetInStockProducts(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, inStock)
+}
@@ -0,0 +1,43 @@
+// Package testsupport holds helpers for setting up and tearing down data in
+// tests that run against MongoDB through repositories or units of work.
+package testsupport
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+)
+
+// Seeder inserts entities; repositories and units of work both satisfy it
+type Seeder[T persistence.ModelConstraint] interface {
+	BulkInsert(ctx context.Context, entities []T) ([]T, error)
+}
+
+// Truncater removes every document of a collection; repositories and units of
+// work both satisfy it
+type Truncater interface {
+	DeleteAll(ctx context.Context, confirm bool) error
+}
+
+// Seed inserts entities through repo in a single bulk write and returns them
+// with their IDs and timestamps set
+func Seed[T persistence.ModelConstraint](ctx context.Context, repo Seeder[T], entities ...T) ([]T, error) {
+	if len(entities) == 0 {
+		return entities, nil
+	}
+	seeded, err := repo.BulkInsert(ctx, entities)
+	if err != nil {
+		return seeded, fmt.Errorf("failed to seed %d entities: %w", len(entities), err)
+	}
+	return seeded, nil
+}
+
+// Truncate permanently removes every document behind repo, trashed ones
+// included, so the next test starts from an empty collection
+func Truncate(ctx context.Context, repo Truncater) error {
+	if err := repo.DeleteAll(ctx, true); err != nil {
+		return fmt.Errorf("failed to truncate: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package testsupport
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	uowerrors "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/errors"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/mongodb"
+	"github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+)
+
+// memoryRepo keeps inserted users in memory
+type memoryRepo struct {
+	users     []*persistence.User
+	insertErr error
+}
+
+func (r *memoryRepo) BulkInsert(_ context.Context, users []*persistence.User) ([]*persistence.User, error) {
+	if r.insertErr != nil {
+		return nil, r.insertErr
+	}
+	for _, user := range users {
+		user.SetID(primitive.NewObjectID())
+	}
+	r.users = append(r.users, users...)
+	return users, nil
+}
+
+func (r *memoryRepo) DeleteAll(_ context.Context, confirm bool) error {
+	if !confirm {
+		return uowerrors.ErrConfirmationRequired
+	}
+	r.users = nil
+	return nil
+}
+
+func TestSeedAndTruncate(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryRepo{}
+
+	seeded, err := Seed(ctx, repo, &persistence.User{Email: "a@example.com"}, &persistence.User{Email: "b@example.com"})
+	require.NoError(t, err)
+	require.Len(t, seeded, 2)
+	assert.False(t, seeded[0].GetID().IsZero())
+	assert.Len(t, repo.users, 2)
+
+	require.NoError(t, Truncate(ctx, repo))
+	assert.Empty(t, repo.users)
+
+	seeded, err = Seed[*persistence.User](ctx, repo)
+	require.NoError(t, err)
+	assert.Empty(t, seeded)
+}
+
+func TestSeed_Error(t *testing.T) {
+	repo := &memoryRepo{insertErr: uowerrors.ErrDuplicateKey}
+
+	_, err := Seed(context.Background(), repo, &persistence.User{Email: "a@example.com"})
+	assert.ErrorIs(t, err, uowerrors.ErrDuplicateKey)
+	assert.ErrorContains(t, err, "failed to seed 1 entities")
+}
+
+func TestSeedAndTruncate_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+	ctx := context.Background()
+
+	config := mongodb.NewConfig()
+	config.Database = "testsupport_" + primitive.NewObjectID().Hex()
+	config.Timeout = 2 * time.Second
+	factory, err := mongodb.NewFactory[*persistence.User](config)
+	require.NoError(t, err)
+	defer factory.Close(ctx)
+
+	repo := mongodb.NewBaseRepository[*persistence.User](factory)
+	if err := repo.Ping(ctx); err != nil {
+		t.Skipf("Integration test requires MongoDB instance: %v", err)
+	}
+	t.Cleanup(func() { _ = Truncate(ctx, repo) })
+
+	for _, tt := range []struct {
+		name   string
+		emails []string
+	}{
+		{"three users", []string{"a@example.com", "b@example.com", "c@example.com"}},
+		{"one user", []string{"d@example.com"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, Truncate(ctx, repo))
+
+			users := make([]*persistence.User, len(tt.emails))
+			for i, email := range tt.emails {
+				users[i] = &persistence.User{Email: email, Active: true}
+			}
+			_, err := Seed(ctx, repo, users...)
+			require.NoError(t, err)
+
+			all, err := repo.FindAll(ctx, nil)
+			require.NoError(t, err)
+			assert.Len(t, all, len(tt.emails), "each case starts from an empty collection")
+		})
+	}
+}